@@ -0,0 +1,69 @@
+package analysis
+
+import "github.com/tmornini/rigetti-computing/memory"
+
+// Block is a basic block: instructions that always execute one after the
+// other, entered only at the first and left only after the last
+type Block struct {
+	Start        memory.Address
+	Instructions []*Instruction
+
+	// Successors are the last instruction's, each To the Start of a block
+	Successors []Edge
+}
+
+// Last instruction of the block
+func (block *Block) Last() *Instruction {
+	return block.Instructions[len(block.Instructions)-1]
+}
+
+// Blocks splits the reachable instructions into basic blocks, in order of
+// address. Blocks begin at #x00, at jump targets and after conditional
+// jumps
+func (graph *Graph) Blocks() []*Block {
+	var blocks []*Block
+
+	for _, address := range graph.Addresses() {
+		if !graph.leader(address) {
+			continue
+		}
+
+		block := &Block{Start: address}
+		instruction := graph.Instructions[address]
+
+		for {
+			block.Instructions = append(block.Instructions, instruction)
+
+			if len(instruction.Successors) != 1 {
+				break
+			}
+
+			next := instruction.Successors[0]
+
+			if next.Kind != FallThrough || graph.leader(next.To) {
+				break
+			}
+
+			instruction = graph.Instructions[next.To]
+		}
+
+		block.Successors = block.Last().Successors
+		blocks = append(blocks, block)
+	}
+
+	return blocks
+}
+
+// leader is true for the first instruction of a block: the entry point, and
+// instructions not only reached by falling through from the one before
+func (graph *Graph) leader(address memory.Address) bool {
+	predecessors := graph.Instructions[address].Predecessors
+
+	if address == 0 || len(predecessors) != 1 {
+		return true
+	}
+
+	from := graph.Instructions[predecessors[0].From]
+
+	return predecessors[0].Kind != FallThrough || len(from.Successors) != 1
+}
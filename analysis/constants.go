@@ -0,0 +1,103 @@
+package analysis
+
+import (
+	"github.com/tmornini/rigetti-computing/memory"
+	"github.com/tmornini/rigetti-computing/processor"
+)
+
+// value of a register, when it is the same on every path
+type value struct {
+	known bool
+	value byte
+}
+
+// registers holds X, Y, Z and W, indexed by their operand
+type registers [4]value
+
+func (r registers) join(other registers) registers {
+	for index := range r {
+		if r[index] != other[index] {
+			r[index] = value{}
+		}
+	}
+
+	return r
+}
+
+// constants returns the registers on entry to each reachable instruction,
+// known where every path to it gives them the same value. Registers are 0
+// when execution begins
+func (graph *Graph) constants() map[memory.Address]registers {
+	entry := registers{}
+
+	for index := range entry {
+		entry[index] = value{known: true}
+	}
+
+	constants := map[memory.Address]registers{0: entry}
+	pending := []memory.Address{0}
+
+	for len(pending) > 0 {
+		address := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+
+		instruction := graph.Instructions[address]
+		out := transfer(instruction, constants[address])
+
+		for _, edge := range instruction.Successors {
+			in, ok := constants[edge.To]
+			joined := out
+
+			if ok {
+				joined = in.join(out)
+				if joined == in {
+					continue
+				}
+			}
+
+			constants[edge.To] = joined
+			pending = append(pending, edge.To)
+		}
+	}
+
+	return constants
+}
+
+// transfer executes an instruction on what is known of the registers
+func transfer(instruction *Instruction, in registers) registers {
+	if instruction.Err != nil {
+		return in
+	}
+
+	out := in
+	operands := instruction.Bytes[1:]
+
+	switch instruction.Opcode() {
+	case processor.ADD, processor.SUB, processor.MUL, processor.DIV:
+		a, b := in[operands[0]], in[operands[1]]
+		r3 := operands[2]
+
+		switch {
+		case instruction.Opcode() == processor.DIV && b.known && b.value == 0:
+			// divide by zero leaves r3 unchanged
+		case !a.known || !b.known:
+			out[r3] = value{}
+		case instruction.Opcode() == processor.ADD:
+			out[r3] = value{known: true, value: a.value + b.value}
+		case instruction.Opcode() == processor.SUB:
+			out[r3] = value{known: true, value: a.value - b.value}
+		case instruction.Opcode() == processor.MUL:
+			out[r3] = value{known: true, value: a.value * b.value}
+		default:
+			out[r3] = value{known: true, value: a.value / b.value}
+		}
+	case processor.LDM:
+		out[operands[1]] = value{}
+	case processor.LDI:
+		out[operands[1]] = value{known: true, value: operands[0]}
+	case processor.SWP:
+		out[operands[0]], out[operands[1]] = in[operands[1]], in[operands[0]]
+	}
+
+	return out
+}
@@ -0,0 +1,99 @@
+package analysis
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/tmornini/rigetti-computing/memory"
+	"github.com/tmornini/rigetti-computing/processor"
+)
+
+// ErrJumpIntoInstruction jump into the middle of an instruction
+var ErrJumpIntoInstruction = errors.New("jump into the middle of an instruction")
+
+// ErrNoHalt no HLT is reachable
+var ErrNoHalt = errors.New("no reachable HLT, so the program never halts")
+
+// ErrRunsPastEnd execution continues past #xFF
+var ErrRunsPastEnd = errors.New(
+	"execution runs past the end of program memory, wrapping around to 00",
+)
+
+// ErrUnreachable bytes execution never reaches
+var ErrUnreachable = errors.New("unreachable")
+
+// ErrUninitializedRead read of main memory that is never written
+var ErrUninitializedRead = errors.New("read of never-written main memory")
+
+// StraddleError is an instruction extending past #xFF
+type StraddleError struct {
+	Opcode    byte
+	Remaining int // bytes before the end of program memory
+}
+
+func (err *StraddleError) Error() string {
+	return fmt.Sprintf(
+		"%s: %s is %d bytes, only %d remain",
+		processor.ErrFetchBeyondEnd,
+		processor.OpcodeName(err.Opcode),
+		processor.InstructionLength(err.Opcode),
+		err.Remaining,
+	)
+}
+
+// Unwrap allows errors.Is(err, processor.ErrFetchBeyondEnd)
+func (err *StraddleError) Unwrap() error {
+	return processor.ErrFetchBeyondEnd
+}
+
+// JumpIntoInstructionError identifies the instruction a jump lands inside
+type JumpIntoInstructionError struct {
+	Target      memory.Address
+	Instruction memory.Address
+}
+
+func (err *JumpIntoInstructionError) Error() string {
+	return fmt.Sprintf(
+		"%s: %02x is inside the instruction at %02x",
+		ErrJumpIntoInstruction,
+		err.Target,
+		err.Instruction,
+	)
+}
+
+// Unwrap allows errors.Is(err, ErrJumpIntoInstruction)
+func (err *JumpIntoInstructionError) Unwrap() error {
+	return ErrJumpIntoInstruction
+}
+
+// UnreachableError counts unreachable bytes
+type UnreachableError struct {
+	Length int
+}
+
+func (err *UnreachableError) Error() string {
+	if err.Length == 1 {
+		return fmt.Sprintf("%s: 1 byte", ErrUnreachable)
+	}
+
+	return fmt.Sprintf("%s: %d bytes", ErrUnreachable, err.Length)
+}
+
+// Unwrap allows errors.Is(err, ErrUnreachable)
+func (err *UnreachableError) Unwrap() error {
+	return ErrUnreachable
+}
+
+// UninitializedReadError is the main memory address read
+type UninitializedReadError struct {
+	Address memory.Address
+}
+
+func (err *UninitializedReadError) Error() string {
+	return fmt.Sprintf("%s: %02x", ErrUninitializedRead, err.Address)
+}
+
+// Unwrap allows errors.Is(err, ErrUninitializedRead)
+func (err *UninitializedReadError) Unwrap() error {
+	return ErrUninitializedRead
+}
@@ -0,0 +1,245 @@
+package analysis
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/tmornini/rigetti-computing/coverage"
+	"github.com/tmornini/rigetti-computing/memory"
+	"github.com/tmornini/rigetti-computing/processor"
+)
+
+// WriteDOT writes the basic blocks as a Graphviz digraph, e.g. for
+// dot -Tsvg. With counts, blocks are labelled with their executions, edges
+// with how often they were followed, widest for the most followed, and
+// those never followed are dashed
+func (graph *Graph) WriteDOT(writer io.Writer, counts *coverage.Coverage) error {
+	blocks := graph.Blocks()
+	most := mostFollowed(blocks, counts)
+
+	lines := []string{
+		"digraph cfg {\n",
+		"\tnode [shape=box, fontname=\"monospace\"];\n",
+	}
+
+	for _, block := range blocks {
+		label := ""
+
+		if counts != nil {
+			label = executions(counts.Executions[block.Start]) + "\\l"
+		}
+
+		for _, text := range blockText(block) {
+			label += text + "\\l"
+		}
+
+		attributes := ""
+		if counts != nil && counts.Executions[block.Start] == 0 {
+			attributes = ", style=dashed"
+		}
+
+		lines = append(lines, fmt.Sprintf(
+			"\t%s [label=\"%s\"%s];\n",
+			blockName(block.Start),
+			label,
+			attributes,
+		))
+	}
+
+	for _, block := range blocks {
+		for _, edge := range block.Successors {
+			var attributes []string
+
+			label := edgeLabel(edge, counts)
+			if label != "" {
+				attributes = append(attributes, "label=\""+label+"\"")
+			}
+
+			if counts != nil {
+				followed := edgeCount(edge, counts)
+
+				if followed == 0 {
+					attributes = append(attributes, "style=dashed")
+				} else {
+					attributes = append(
+						attributes,
+						fmt.Sprintf("penwidth=%.1f", edgeWidth(followed, most)),
+					)
+				}
+			}
+
+			text := ""
+			if len(attributes) > 0 {
+				text = " [" + strings.Join(attributes, ", ") + "]"
+			}
+
+			lines = append(lines, fmt.Sprintf(
+				"\t%s -> %s%s;\n",
+				blockName(block.Start),
+				blockName(edge.To),
+				text,
+			))
+		}
+	}
+
+	lines = append(lines, "}\n")
+
+	_, err := io.WriteString(writer, strings.Join(lines, ""))
+
+	return err
+}
+
+// WriteMermaid writes the basic blocks as a Mermaid flowchart, e.g. for
+// Markdown. Counts are shown as WriteDOT shows them
+func (graph *Graph) WriteMermaid(
+	writer io.Writer,
+	counts *coverage.Coverage,
+) error {
+	blocks := graph.Blocks()
+	most := mostFollowed(blocks, counts)
+
+	lines := []string{"flowchart TD\n"}
+
+	for _, block := range blocks {
+		text := blockText(block)
+
+		if counts != nil {
+			text = append([]string{executions(counts.Executions[block.Start])}, text...)
+		}
+
+		lines = append(lines, fmt.Sprintf(
+			"    %s[\"%s\"]\n",
+			blockName(block.Start),
+			strings.Join(text, "<br/>"),
+		))
+	}
+
+	var styles []string
+	link := 0
+
+	for _, block := range blocks {
+		for _, edge := range block.Successors {
+			arrow := "-->"
+
+			if counts != nil {
+				followed := edgeCount(edge, counts)
+
+				if followed == 0 {
+					arrow = "-.->"
+				} else {
+					styles = append(styles, fmt.Sprintf(
+						"    linkStyle %d stroke-width:%.1fpx\n",
+						link,
+						edgeWidth(followed, most),
+					))
+				}
+			}
+
+			label := edgeLabel(edge, counts)
+			if label != "" {
+				arrow += "|\"" + label + "\"|"
+			}
+
+			lines = append(lines, fmt.Sprintf(
+				"    %s %s %s\n",
+				blockName(block.Start),
+				arrow,
+				blockName(edge.To),
+			))
+
+			link++
+		}
+	}
+
+	lines = append(lines, styles...)
+
+	_, err := io.WriteString(writer, strings.Join(lines, ""))
+
+	return err
+}
+
+func blockName(address memory.Address) string {
+	return fmt.Sprintf("b%02x", address)
+}
+
+// blockText disassembles each instruction of a block, with the error it
+// faults with
+func blockText(block *Block) []string {
+	var text []string
+
+	for _, instruction := range block.Instructions {
+		line := fmt.Sprintf("%02x: %s", instruction.Address, instruction.Assembly)
+
+		if instruction.Err != nil {
+			line += " ; " + instruction.Err.Error()
+		}
+
+		text = append(text, line)
+	}
+
+	return text
+}
+
+func executions(count uint64) string {
+	if count == 1 {
+		return "1 execution"
+	}
+
+	return fmt.Sprintf("%d executions", count)
+}
+
+// edgeLabel names conditional jumps' edges and wraps, with how often they
+// were followed when there are counts
+func edgeLabel(edge Edge, counts *coverage.Coverage) string {
+	var label []string
+
+	switch edge.Kind {
+	case Taken, NotTaken, Wrap:
+		label = append(label, edge.Kind.String())
+	}
+
+	if counts != nil {
+		label = append(label, fmt.Sprint(edgeCount(edge, counts)))
+	}
+
+	return strings.Join(label, " ")
+}
+
+// edgeCount is how often an edge was followed
+func edgeCount(edge Edge, counts *coverage.Coverage) uint64 {
+	conditional := processor.IsConditionalJump(counts.ProgramMemory[edge.From])
+
+	switch {
+	case edge.Kind == Taken:
+		return counts.Taken[edge.From]
+	case conditional:
+		return counts.NotTaken[edge.From]
+	default:
+		return counts.Executions[edge.From]
+	}
+}
+
+func mostFollowed(blocks []*Block, counts *coverage.Coverage) uint64 {
+	var most uint64
+
+	if counts == nil {
+		return 0
+	}
+
+	for _, block := range blocks {
+		for _, edge := range block.Successors {
+			if followed := edgeCount(edge, counts); followed > most {
+				most = followed
+			}
+		}
+	}
+
+	return most
+}
+
+// edgeWidth is from 1 to 5, in proportion to how often the edge was
+// followed
+func edgeWidth(followed uint64, most uint64) float64 {
+	return 1 + 4*float64(followed)/float64(most)
+}
@@ -0,0 +1,254 @@
+package analysis
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/tmornini/rigetti-computing/memory"
+	"github.com/tmornini/rigetti-computing/processor"
+)
+
+// Severity of a finding
+type Severity int
+
+const (
+	// Warning is probably a mistake, but execution continues
+	Warning Severity = iota
+
+	// Error is certainly a mistake: executing it faults, or the program
+	// never halts
+	Error
+)
+
+// Finding is a problem with the bytes from Address, Length long
+type Finding struct {
+	Address  memory.Address
+	Length   int
+	Assembly string // of the instruction at Address, "" for other bytes
+	Severity Severity
+	Err      error
+}
+
+func (finding Finding) String() string {
+	text := fmt.Sprintf("%02x", finding.Address)
+
+	if finding.Assembly != "" {
+		text += ": " + finding.Assembly
+	} else if finding.Length > 1 {
+		text += fmt.Sprintf("-%02x", int(finding.Address)+finding.Length-1)
+	}
+
+	if finding.Severity == Warning {
+		text += ": warning"
+	}
+
+	return text + ": " + finding.Err.Error()
+}
+
+// Check analyzes the code reachable from #x00, returning its findings in
+// order of address. Main memory is assumed to be 0 when execution begins
+func Check(programMemory *memory.ReadOnly) []Finding {
+	return NewGraph(programMemory).Check()
+}
+
+// Check returns the graph's findings in order of address
+func (graph *Graph) Check() []Finding {
+	var findings []Finding
+
+	for _, check := range []func() []Finding{
+		graph.checkInstructions,
+		graph.checkJumps,
+		graph.checkHalt,
+		graph.checkUnreachable,
+		graph.checkUninitializedReads,
+	} {
+		findings = append(findings, check()...)
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Address < findings[j].Address
+	})
+
+	return findings
+}
+
+func (graph *Graph) finding(
+	address memory.Address,
+	severity Severity,
+	err error,
+) Finding {
+	instruction := graph.Instructions[address]
+
+	return Finding{
+		Address:  address,
+		Length:   len(instruction.Bytes),
+		Assembly: instruction.Assembly,
+		Severity: severity,
+		Err:      err,
+	}
+}
+
+// checkInstructions finds reachable instructions that fault, and those
+// after which execution wraps around
+func (graph *Graph) checkInstructions() []Finding {
+	var findings []Finding
+
+	for _, address := range graph.Addresses() {
+		instruction := graph.Instructions[address]
+		err := instruction.Err
+
+		if errors.Is(err, processor.ErrFetchBeyondEnd) {
+			err = &StraddleError{
+				Opcode:    instruction.Opcode(),
+				Remaining: len(instruction.Bytes),
+			}
+		}
+
+		if err != nil {
+			findings = append(findings, graph.finding(address, Error, err))
+		}
+
+		for _, edge := range instruction.Successors {
+			if edge.Kind == Wrap {
+				findings = append(
+					findings,
+					graph.finding(address, Warning, ErrRunsPastEnd),
+				)
+			}
+		}
+	}
+
+	return findings
+}
+
+// checkJumps finds jumps to an address inside another reachable
+// instruction, so the same bytes execute as different instructions
+func (graph *Graph) checkJumps() []Finding {
+	var findings []Finding
+
+	covered := graph.covered()
+
+	for _, address := range graph.Addresses() {
+		for _, edge := range graph.Instructions[address].Successors {
+			if edge.Kind != Jump && edge.Kind != Taken {
+				continue
+			}
+
+			for _, start := range covered[edge.To] {
+				if start == edge.To {
+					continue
+				}
+
+				findings = append(findings, graph.finding(
+					address,
+					Error,
+					&JumpIntoInstructionError{Target: edge.To, Instruction: start},
+				))
+			}
+		}
+	}
+
+	return findings
+}
+
+// checkHalt finds programs that cannot halt normally
+func (graph *Graph) checkHalt() []Finding {
+	for _, instruction := range graph.Instructions {
+		if instruction.Err == nil && instruction.Opcode() == processor.HLT {
+			return nil
+		}
+	}
+
+	return []Finding{graph.finding(0, Error, ErrNoHalt)}
+}
+
+// checkUnreachable finds bytes no reachable instruction is part of. Zeros
+// at either end of a run of them are padding, and not reported
+func (graph *Graph) checkUnreachable() []Finding {
+	var findings []Finding
+
+	covered := graph.covered()
+
+	for address := 0; address < len(graph.Program); {
+		if len(covered[address]) > 0 || graph.Program[address] == 0 {
+			address++
+
+			continue
+		}
+
+		end := address
+		last := address
+
+		for end < len(graph.Program) && len(covered[end]) == 0 {
+			if graph.Program[end] != 0 {
+				last = end
+			}
+
+			end++
+		}
+
+		findings = append(findings, Finding{
+			Address:  memory.Address(address),
+			Length:   last - address + 1,
+			Severity: Warning,
+			Err:      &UnreachableError{Length: last - address + 1},
+		})
+
+		address = end
+	}
+
+	return findings
+}
+
+// checkUninitializedReads finds LDMs from addresses no reachable STR
+// writes. Only addresses that are the same on every path are checked, and
+// nothing is reported when a STR's address is not
+func (graph *Graph) checkUninitializedReads() []Finding {
+	constants := graph.constants()
+
+	var written [256]bool
+	var reads []memory.Address
+
+	for _, address := range graph.Addresses() {
+		instruction := graph.Instructions[address]
+
+		if instruction.Err != nil {
+			continue
+		}
+
+		operands := instruction.Bytes[1:]
+		in := constants[address]
+
+		switch instruction.Opcode() {
+		case processor.LDM:
+			if in[operands[0]].known {
+				reads = append(reads, address)
+			}
+		case processor.STR:
+			target := in[operands[1]]
+
+			if !target.known {
+				return nil
+			}
+
+			written[target.value] = true
+		}
+	}
+
+	var findings []Finding
+
+	for _, address := range reads {
+		source := constants[address][graph.Instructions[address].Bytes[1]].value
+
+		if !written[source] {
+			findings = append(findings, graph.finding(
+				address,
+				Warning,
+				&UninitializedReadError{Address: memory.Address(source)},
+			))
+		}
+	}
+
+	return findings
+}
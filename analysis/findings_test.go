@@ -0,0 +1,130 @@
+package analysis
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/tmornini/rigetti-computing/memory"
+	"github.com/tmornini/rigetti-computing/processor"
+)
+
+// program is bytes from #x00, with bytes from #xF0 when end is not empty
+func program(start []byte, end []byte) *memory.ReadOnly {
+	var programMemory memory.ReadOnly
+
+	copy(programMemory[:], start)
+	copy(programMemory[0xf0:], end)
+
+	return &programMemory
+}
+
+// TestChecks finds each kind of problem, and nothing in a correct program
+func TestChecks(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		program  *memory.ReadOnly
+		findings []Finding // Err is compared with errors.Is, Length ignored
+	}{
+		{
+			name:    "correct",
+			program: &loop,
+		},
+		{
+			name: "faulting instruction",
+			program: program([]byte{
+				0x0c, 0x06, //             JMC 0x06
+				0x01, 0x09, 0x00, 0x0f, // ADD 09 X W
+				0x0f, //                   HLT
+			}, nil),
+			findings: []Finding{{Address: 0x02, Severity: Error, Err: processor.ErrUnknownRegister}},
+		},
+		{
+			name: "straddle",
+			program: program([]byte{
+				0x0c, 0xfe, // JMC 0xfe
+				0x0f, //       HLT
+			}, append(make([]byte, 14), 0x01, 0x00)), // ADD X, which is 4 bytes
+			findings: []Finding{{Address: 0xfe, Severity: Error, Err: processor.ErrFetchBeyondEnd}},
+		},
+		{
+			name: "runs past end",
+			program: program([]byte{
+				0x0c, 0xff, // JMC 0xff
+				0x0f, //       HLT
+			}, nil),
+			findings: []Finding{{Address: 0xff, Severity: Warning, Err: ErrRunsPastEnd}},
+		},
+		{
+			name: "jump into instruction",
+			program: program([]byte{
+				0x06, 0x0f, 0x00, // LDI 0x0f X
+				0x0b, 0x01, //       JMP 0x01, which is HLT
+			}, nil),
+			findings: []Finding{{Address: 0x03, Severity: Error, Err: ErrJumpIntoInstruction}},
+		},
+		{
+			name: "no halt",
+			program: program([]byte{
+				0x00,       // NOP
+				0x0b, 0x00, // JMP 0x00
+			}, nil),
+			findings: []Finding{{Address: 0x00, Severity: Error, Err: ErrNoHalt}},
+		},
+		{
+			name: "unreachable",
+			program: program([]byte{
+				0x0b, 0x05, //       JMP 0x05
+				0x00, 0x0e, 0x00, // NOP, and PRN X which is skipped
+				0x0f, //             HLT
+			}, nil),
+			// zeros at either end are padding
+			findings: []Finding{{Address: 0x03, Length: 1, Severity: Warning, Err: ErrUnreachable}},
+		},
+		{
+			name: "uninitialized read",
+			program: program([]byte{
+				0x06, 0x10, 0x00, // LDI 0x10 X
+				0x05, 0x00, 0x01, // LDM X Y
+				0x0f, //             HLT
+			}, nil),
+			findings: []Finding{{Address: 0x03, Severity: Warning, Err: ErrUninitializedRead}},
+		},
+		{
+			name: "initialized read",
+			program: program([]byte{
+				0x06, 0x10, 0x00, // LDI 0x10 X
+				0x07, 0x00, 0x00, // STR X X
+				0x05, 0x00, 0x01, // LDM X Y
+				0x0f, //             HLT
+			}, nil),
+		},
+		{
+			name: "read after a store to an unknown address",
+			program: program([]byte{
+				0x06, 0x10, 0x00, // LDI 0x10 X
+				0x05, 0x00, 0x01, // LDM X Y
+				0x07, 0x00, 0x01, // STR X Y, where Y is not constant
+				0x0f, //             HLT
+			}, nil),
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			findings := Check(test.program)
+
+			if len(findings) != len(test.findings) {
+				t.Fatalf("got %d findings %v, want %d", len(findings), findings, len(test.findings))
+			}
+
+			for index, finding := range findings {
+				want := test.findings[index]
+
+				if finding.Address != want.Address ||
+					finding.Severity != want.Severity ||
+					!errors.Is(finding.Err, want.Err) ||
+					want.Length != 0 && finding.Length != want.Length {
+					t.Errorf("got %s, want %+v", finding, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package analysis
+
+import (
+	"sort"
+
+	"github.com/tmornini/rigetti-computing/memory"
+	"github.com/tmornini/rigetti-computing/processor"
+)
+
+// EdgeKind is how control passes from one instruction to another
+type EdgeKind int
+
+const (
+	// FallThrough to the next instruction
+	FallThrough EdgeKind = iota
+
+	// Jump by JMP
+	Jump
+
+	// Taken by JMC or JME when their flag is set
+	Taken
+
+	// NotTaken by JMC or JME when their flag is clear
+	NotTaken
+
+	// Wrap from an instruction ending at #xFF to #x00, as the PC wraps
+	// around by default
+	Wrap
+
+	numberOfEdgeKinds
+)
+
+var edgeKindNames = [numberOfEdgeKinds]string{
+	"fall through",
+	"jump",
+	"taken",
+	"not taken",
+	"wrap",
+}
+
+func (kind EdgeKind) String() string {
+	if kind < 0 || kind >= numberOfEdgeKinds {
+		return "???"
+	}
+
+	return edgeKindNames[kind]
+}
+
+// Edge of the control-flow graph
+type Edge struct {
+	From memory.Address
+	To   memory.Address
+	Kind EdgeKind
+}
+
+// Instruction is a node of the control-flow graph
+type Instruction struct {
+	processor.DisassembledInstruction
+
+	Successors   []Edge
+	Predecessors []Edge
+}
+
+// Opcode of the instruction
+func (instruction *Instruction) Opcode() byte {
+	return instruction.Bytes[0]
+}
+
+// Graph is the control-flow graph of the code reachable from #x00, with an
+// Instruction for each address execution can reach. Instructions that
+// fault have no successors, as faults abort by default
+type Graph struct {
+	Program      *memory.ReadOnly
+	Instructions map[memory.Address]*Instruction
+}
+
+// NewGraph decodes every instruction reachable from #x00, following both
+// directions of conditional jumps
+func NewGraph(programMemory *memory.ReadOnly) *Graph {
+	graph := &Graph{
+		Program:      programMemory,
+		Instructions: map[memory.Address]*Instruction{},
+	}
+
+	pending := []memory.Address{0}
+
+	for len(pending) > 0 {
+		address := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+
+		if graph.Instructions[address] != nil {
+			continue
+		}
+
+		instruction := &Instruction{
+			DisassembledInstruction: processor.Disassemble(programMemory, address),
+		}
+
+		instruction.Successors = successors(instruction)
+		graph.Instructions[address] = instruction
+
+		for _, edge := range instruction.Successors {
+			pending = append(pending, edge.To)
+		}
+	}
+
+	for _, address := range graph.Addresses() {
+		for _, edge := range graph.Instructions[address].Successors {
+			to := graph.Instructions[edge.To]
+			to.Predecessors = append(to.Predecessors, edge)
+		}
+	}
+
+	return graph
+}
+
+func successors(instruction *Instruction) []Edge {
+	if instruction.Err != nil {
+		return nil
+	}
+
+	address := instruction.Address
+
+	edge := func(to memory.Address, kind EdgeKind) Edge {
+		return Edge{From: address, To: to, Kind: kind}
+	}
+
+	next := int(address) + len(instruction.Bytes)
+	fallThrough := edge(memory.Address(next), FallThrough)
+
+	if next > 0xff {
+		fallThrough = edge(0, Wrap)
+	}
+
+	switch instruction.Opcode() {
+	case processor.HLT:
+		return nil
+	case processor.JMP:
+		return []Edge{edge(memory.Address(instruction.Bytes[1]), Jump)}
+	case processor.JMC, processor.JME:
+		notTaken := fallThrough
+
+		if notTaken.Kind == FallThrough {
+			notTaken.Kind = NotTaken
+		}
+
+		return []Edge{
+			edge(memory.Address(instruction.Bytes[1]), Taken),
+			notTaken,
+		}
+	}
+
+	return []Edge{fallThrough}
+}
+
+// Addresses of the reachable instructions, in order
+func (graph *Graph) Addresses() []memory.Address {
+	addresses := make([]memory.Address, 0, len(graph.Instructions))
+
+	for address := range graph.Instructions {
+		addresses = append(addresses, address)
+	}
+
+	sort.Slice(addresses, func(i, j int) bool {
+		return addresses[i] < addresses[j]
+	})
+
+	return addresses
+}
+
+// covered returns, for each byte of program memory, the addresses of the
+// reachable instructions it is part of
+func (graph *Graph) covered() [256][]memory.Address {
+	var covered [256][]memory.Address
+
+	for _, address := range graph.Addresses() {
+		instruction := graph.Instructions[address]
+
+		for offset := range instruction.Bytes {
+			covered[int(address)+offset] = append(
+				covered[int(address)+offset],
+				address,
+			)
+		}
+	}
+
+	return covered
+}
@@ -0,0 +1,155 @@
+package analysis
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/tmornini/rigetti-computing/memory"
+)
+
+// loop counts X down from 3, jumping over the JMP back to its top when it
+// reaches 0
+var loop = memory.ReadOnly{
+	0x06, 0x03, 0x00, //       LDI 0x03 X
+	0x06, 0x01, 0x01, //       LDI 0x01 Y
+	0x06, 0x00, 0x02, // TOP   LDI 0x00 Z
+	0x02, 0x00, 0x01, 0x00, // SUB X Y X
+	0x09, 0x00, 0x02, //       EQL X Z
+	0x0c, 0x14, //             JMC DONE
+	0x0b, 0x06, //             JMP TOP
+	0x0f, //             DONE  HLT
+}
+
+// TestNewGraph follows every edge from #x00, and links each instruction to
+// its predecessors
+func TestNewGraph(t *testing.T) {
+	graph := NewGraph(&loop)
+
+	addresses := []memory.Address{0x00, 0x03, 0x06, 0x09, 0x0d, 0x10, 0x12, 0x14}
+
+	if !reflect.DeepEqual(graph.Addresses(), addresses) {
+		t.Fatalf("got addresses %x, want %x", graph.Addresses(), addresses)
+	}
+
+	for _, test := range []struct {
+		address      memory.Address
+		successors   []Edge
+		predecessors []Edge
+	}{
+		{
+			address:    0x00,
+			successors: []Edge{{From: 0x00, To: 0x03, Kind: FallThrough}},
+		},
+		{
+			address:    0x06,
+			successors: []Edge{{From: 0x06, To: 0x09, Kind: FallThrough}},
+			predecessors: []Edge{
+				{From: 0x03, To: 0x06, Kind: FallThrough},
+				{From: 0x12, To: 0x06, Kind: Jump},
+			},
+		},
+		{
+			address: 0x10,
+			successors: []Edge{
+				{From: 0x10, To: 0x14, Kind: Taken},
+				{From: 0x10, To: 0x12, Kind: NotTaken},
+			},
+			predecessors: []Edge{{From: 0x0d, To: 0x10, Kind: FallThrough}},
+		},
+		{
+			address:      0x14,
+			predecessors: []Edge{{From: 0x10, To: 0x14, Kind: Taken}},
+		},
+	} {
+		instruction := graph.Instructions[test.address]
+
+		if !reflect.DeepEqual(instruction.Successors, test.successors) {
+			t.Errorf(
+				"%02x has successors %+v, want %+v",
+				test.address,
+				instruction.Successors,
+				test.successors,
+			)
+		}
+
+		if !reflect.DeepEqual(instruction.Predecessors, test.predecessors) {
+			t.Errorf(
+				"%02x has predecessors %+v, want %+v",
+				test.address,
+				instruction.Predecessors,
+				test.predecessors,
+			)
+		}
+	}
+}
+
+// TestNewGraphEnds gives instructions that fault no successors, and those
+// at the end of program memory a Wrap edge to #x00
+func TestNewGraphEnds(t *testing.T) {
+	var programMemory memory.ReadOnly
+
+	programMemory[0x00] = 0x0b // JMP 0xfc
+	programMemory[0x01] = 0xfc
+	programMemory[0xfc] = 0x0c // JMC 0x10
+	programMemory[0xfd] = 0x10
+	programMemory[0xfe] = 0x00 // NOP
+	programMemory[0xff] = 0x00 // NOP
+	programMemory[0x10] = 0x10 // unknown opcode
+
+	graph := NewGraph(&programMemory)
+
+	if successors := graph.Instructions[0x10].Successors; successors != nil {
+		t.Errorf("unknown opcode has successors %+v", successors)
+	}
+
+	wrap := []Edge{{From: 0xff, To: 0x00, Kind: Wrap}}
+
+	if successors := graph.Instructions[0xff].Successors; !reflect.DeepEqual(successors, wrap) {
+		t.Errorf("NOP at ff has successors %+v, want %+v", successors, wrap)
+	}
+}
+
+// TestBlocks begins blocks at #x00, jump targets and after conditional
+// jumps, linking them by their last instruction's edges
+func TestBlocks(t *testing.T) {
+	blocks := NewGraph(&loop).Blocks()
+
+	expected := []struct {
+		start        memory.Address
+		instructions int
+		successors   []Edge
+	}{
+		{0x00, 2, []Edge{{From: 0x03, To: 0x06, Kind: FallThrough}}},
+		{0x06, 4, []Edge{{From: 0x10, To: 0x14, Kind: Taken}, {From: 0x10, To: 0x12, Kind: NotTaken}}},
+		{0x12, 1, []Edge{{From: 0x12, To: 0x06, Kind: Jump}}},
+		{0x14, 1, nil},
+	}
+
+	if len(blocks) != len(expected) {
+		t.Fatalf("got %d blocks, want %d", len(blocks), len(expected))
+	}
+
+	for index, block := range blocks {
+		want := expected[index]
+
+		if block.Start != want.start || len(block.Instructions) != want.instructions {
+			t.Errorf(
+				"block %d starts at %02x with %d instructions, want %02x with %d",
+				index,
+				block.Start,
+				len(block.Instructions),
+				want.start,
+				want.instructions,
+			)
+		}
+
+		if !reflect.DeepEqual(block.Successors, want.successors) {
+			t.Errorf(
+				"block %02x has successors %+v, want %+v",
+				block.Start,
+				block.Successors,
+				want.successors,
+			)
+		}
+	}
+}
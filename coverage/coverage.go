@@ -0,0 +1,115 @@
+package coverage
+
+import (
+	"errors"
+
+	"github.com/tmornini/rigetti-computing/memory"
+	"github.com/tmornini/rigetti-computing/processor"
+)
+
+// Coverage records which instructions of a program were executed, and which
+// way its conditional jumps went, across any number of runs. Add it to
+// processor.Configuration.Observers before each run
+type Coverage struct {
+	ProgramMemory memory.ReadOnly
+
+	Runs uint64
+
+	Executions [256]uint64 // by program address
+	Taken      [256]uint64 // JMC and JME jumps by program address
+	NotTaken   [256]uint64 // JMC and JME fall throughs by program address
+}
+
+// New create the coverage of a single run of a program
+func New(programMemory *memory.ReadOnly) *Coverage {
+	return &Coverage{ProgramMemory: *programMemory, Runs: 1}
+}
+
+// Observe records an instruction processed
+func (coverage *Coverage) Observe(event processor.Event) {
+	coverage.Executions[event.ProgramCounter]++
+
+	if !event.Conditional || event.Err != nil {
+		return
+	}
+
+	if event.Jumped {
+		coverage.Taken[event.ProgramCounter]++
+	} else {
+		coverage.NotTaken[event.ProgramCounter]++
+	}
+}
+
+// Merge adds the coverage of other runs of the same program
+func (coverage *Coverage) Merge(other *Coverage) error {
+	if other.ProgramMemory != coverage.ProgramMemory {
+		return ErrProgramMismatch
+	}
+
+	coverage.Runs += other.Runs
+
+	for address := range coverage.Executions {
+		coverage.Executions[address] += other.Executions[address]
+		coverage.Taken[address] += other.Taken[address]
+		coverage.NotTaken[address] += other.NotTaken[address]
+	}
+
+	return nil
+}
+
+// Summary counts the instructions executed and the conditional jump
+// directions taken, of the instructions in the program's disassembly
+type Summary struct {
+	Instructions         int
+	InstructionsExecuted int
+
+	Branches      int // two for each JMC and JME
+	BranchesTaken int
+}
+
+// Summary of the program's disassembly
+func (coverage *Coverage) Summary() Summary {
+	summary := Summary{}
+
+	for _, instruction := range processor.DisassembleProgram(&coverage.ProgramMemory) {
+		if !coverage.counted(instruction) {
+			continue
+		}
+
+		address := instruction.Address
+
+		summary.Instructions++
+
+		if coverage.Executions[address] > 0 {
+			summary.InstructionsExecuted++
+		}
+
+		if isData(instruction) || !processor.IsConditionalJump(instruction.Bytes[0]) {
+			continue
+		}
+
+		summary.Branches += 2
+
+		if coverage.Taken[address] > 0 {
+			summary.BranchesTaken++
+		}
+
+		if coverage.NotTaken[address] > 0 {
+			summary.BranchesTaken++
+		}
+	}
+
+	return summary
+}
+
+// counted is false for .byte directives, which are data unless executed
+func (coverage *Coverage) counted(
+	instruction processor.DisassembledInstruction,
+) bool {
+	return !isData(instruction) || coverage.Executions[instruction.Address] > 0
+}
+
+func isData(instruction processor.DisassembledInstruction) bool {
+	return errors.Is(instruction.Err, processor.ErrUnknownOpcode) ||
+		errors.Is(instruction.Err, processor.ErrFetchBeyondEnd)
+}
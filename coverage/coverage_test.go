@@ -0,0 +1,127 @@
+package coverage
+
+import (
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"github.com/tmornini/rigetti-computing/memory"
+	"github.com/tmornini/rigetti-computing/processor"
+)
+
+// alwaysTaken has a JMC that always jumps over data, which disassembles as
+// an LDI taking in the HLT at DONE
+var alwaysTaken = memory.ReadOnly{
+	0x06, 0x01, 0x00, // LDI 0x01 X
+	0x09, 0x00, 0x00, // EQL X X
+	0x0c, 0x0a, //       JMC DONE
+	0x06, 0x0f, //       data
+	0x0f, //       DONE  HLT
+}
+
+// covered runs the program once, observed by a new coverage
+func covered(t *testing.T, programMemory *memory.ReadOnly) *Coverage {
+	t.Helper()
+
+	coverage := New(programMemory)
+
+	configuration := processor.DefaultConfiguration
+	configuration.Output = ioutil.Discard
+	configuration.Observers = []processor.Observer{coverage}
+
+	err := processor.New(
+		processor.NormalInstructionSet,
+		configuration,
+		programMemory,
+		&memory.ReadWrite{},
+	).Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return coverage
+}
+
+// TestObserve counts executions, and which way conditional jumps went
+func TestObserve(t *testing.T) {
+	coverage := covered(t, &alwaysTaken)
+
+	if coverage.Runs != 1 ||
+		coverage.Executions[0x06] != 1 ||
+		coverage.Executions[0x08] != 0 ||
+		coverage.Executions[0x0a] != 1 {
+		t.Errorf("got %d runs and executions %v", coverage.Runs, coverage.Executions[:11])
+	}
+
+	if coverage.Taken[0x06] != 1 || coverage.NotTaken[0x06] != 0 {
+		t.Errorf("JMC taken %d and not taken %d, want 1 and 0", coverage.Taken[0x06], coverage.NotTaken[0x06])
+	}
+}
+
+// TestSummary counts the instructions of the disassembly, not its NOP
+// padding, and the directions of each conditional jump. The disassembly
+// takes the data for an LDI, which hides the HLT at DONE
+func TestSummary(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		coverage *Coverage
+		summary  Summary
+	}{
+		{
+			name:     "never run",
+			coverage: &Coverage{ProgramMemory: alwaysTaken},
+			summary:  Summary{Instructions: 4, Branches: 2},
+		},
+		{
+			name:     "always taken",
+			coverage: covered(t, &alwaysTaken),
+			summary:  Summary{Instructions: 4, InstructionsExecuted: 3, Branches: 2, BranchesTaken: 1},
+		},
+	} {
+		if summary := test.coverage.Summary(); summary != test.summary {
+			t.Errorf("%s: got %+v, want %+v", test.name, summary, test.summary)
+		}
+	}
+}
+
+// TestMerge adds the coverage of the same program, and refuses that of
+// another, leaving the coverage as it was
+func TestMerge(t *testing.T) {
+	other := alwaysTaken
+	other[0x01] = 0x02 // LDI 0x02 X
+
+	for _, test := range []struct {
+		name     string
+		other    *Coverage
+		err      error
+		runs     uint64
+		taken    uint64
+		notTaken uint64
+	}{
+		{name: "same program", other: covered(t, &alwaysTaken), runs: 2, taken: 2},
+		{name: "same program, never run", other: New(&alwaysTaken), runs: 2, taken: 1},
+		{name: "different program", other: covered(t, &other), err: ErrProgramMismatch, runs: 1, taken: 1},
+	} {
+		coverage := covered(t, &alwaysTaken)
+
+		err := coverage.Merge(test.other)
+		if !errors.Is(err, test.err) {
+			t.Errorf("%s: got %v, want %v", test.name, err, test.err)
+		}
+
+		if coverage.Runs != test.runs ||
+			coverage.Taken[0x06] != test.taken ||
+			coverage.NotTaken[0x06] != test.notTaken {
+			t.Errorf(
+				"%s: got %d runs, JMC taken %d and not taken %d, want %d, %d and %d",
+				test.name,
+				coverage.Runs,
+				coverage.Taken[0x06],
+				coverage.NotTaken[0x06],
+				test.runs,
+				test.taken,
+				test.notTaken,
+			)
+		}
+	}
+}
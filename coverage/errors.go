@@ -0,0 +1,9 @@
+package coverage
+
+import "errors"
+
+// ErrInvalidCoverage invalid coverage file
+var ErrInvalidCoverage = errors.New("invalid coverage file")
+
+// ErrProgramMismatch coverage of different programs
+var ErrProgramMismatch = errors.New("coverage is of a different program")
@@ -0,0 +1,161 @@
+package coverage
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/tmornini/rigetti-computing/memory"
+)
+
+// coverage files are text, so they diff well and can be merged by hand:
+//
+//	rcc coverage 1
+//	runs 3
+//	program 0600000601...
+//	# address executions taken not-taken
+//	90 15 3 12
+//
+// with a line for each address executed. The program is 512 hex digits
+const coverageHeader = "rcc coverage 1"
+
+// Write the coverage file
+func (coverage *Coverage) Write(writer io.Writer) error {
+	_, err := fmt.Fprintf(
+		writer,
+		"%s\nruns %d\nprogram %s\n# address executions taken not-taken\n",
+		coverageHeader,
+		coverage.Runs,
+		hex.EncodeToString(coverage.ProgramMemory[:]),
+	)
+	if err != nil {
+		return err
+	}
+
+	for address, executions := range coverage.Executions {
+		if executions == 0 {
+			continue
+		}
+
+		_, err = fmt.Fprintf(
+			writer,
+			"%02x %d %d %d\n",
+			address,
+			executions,
+			coverage.Taken[address],
+			coverage.NotTaken[address],
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Read a coverage file written by Write
+func Read(reader io.Reader) (*Coverage, error) {
+	coverage := &Coverage{}
+
+	scanner := bufio.NewScanner(reader)
+
+	lineNumber := 0
+	program := false
+
+	for scanner.Scan() {
+		lineNumber++
+
+		line := scanner.Text()
+		fields := strings.Fields(line)
+
+		var err error
+
+		switch {
+		case lineNumber == 1:
+			if line != coverageHeader {
+				return nil, lineError(lineNumber, "must be %s", coverageHeader)
+			}
+		case len(fields) == 0 || strings.HasPrefix(fields[0], "#"):
+			continue
+		case fields[0] == "runs" && len(fields) == 2:
+			coverage.Runs, err = strconv.ParseUint(fields[1], 10, 64)
+		case fields[0] == "program" && len(fields) == 2:
+			err = readProgram(coverage, fields[1])
+			program = true
+		case len(fields) == 4:
+			err = readAddress(coverage, fields)
+		default:
+			err = fmt.Errorf("%q is not runs, program or an address", line)
+		}
+
+		if err != nil {
+			return nil, lineError(lineNumber, "%s", err)
+		}
+	}
+
+	err := scanner.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	if lineNumber == 0 {
+		return nil, lineError(1, "must be %s", coverageHeader)
+	}
+
+	if !program {
+		return nil, lineError(lineNumber, "no program")
+	}
+
+	return coverage, nil
+}
+
+func readProgram(coverage *Coverage, encoded string) error {
+	decoded, err := hex.DecodeString(encoded)
+	if err != nil || len(decoded) != len(coverage.ProgramMemory) {
+		return fmt.Errorf(
+			"program must be %d bytes of hex",
+			len(coverage.ProgramMemory),
+		)
+	}
+
+	copy(coverage.ProgramMemory[:], decoded)
+
+	return nil
+}
+
+func readAddress(coverage *Coverage, fields []string) error {
+	address, err := strconv.ParseUint(fields[0], 16, 8)
+	if err != nil {
+		return fmt.Errorf("%s is not a hex address from 00 to ff", fields[0])
+	}
+
+	counts := []*uint64{
+		&coverage.Executions[address],
+		&coverage.Taken[address],
+		&coverage.NotTaken[address],
+	}
+
+	for index, count := range counts {
+		*count, err = strconv.ParseUint(fields[1+index], 10, 64)
+		if err != nil {
+			return fmt.Errorf("%s is not a decimal count", fields[1+index])
+		}
+	}
+
+	return nil
+}
+
+func lineError(
+	lineNumber int,
+	format string,
+	arguments ...interface{},
+) error {
+	return &memory.LineError{
+		Err:    ErrInvalidCoverage,
+		Line:   lineNumber,
+		Reason: fmt.Sprintf(format, arguments...),
+	}
+}
@@ -0,0 +1,61 @@
+package coverage
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tmornini/rigetti-computing/memory"
+)
+
+// TestReadWrite reads back what Write wrote
+func TestReadWrite(t *testing.T) {
+	coverage := covered(t, &alwaysTaken)
+	coverage.Runs = 3
+	coverage.NotTaken[0x06] = 5
+
+	var file bytes.Buffer
+
+	err := coverage.Write(&file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	read, err := Read(&file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(read, coverage) {
+		t.Errorf("read %+v, want %+v", read, coverage)
+	}
+}
+
+// TestReadInvalid reports the line of each malformed coverage file
+func TestReadInvalid(t *testing.T) {
+	program := "program " + strings.Repeat("00", 256)
+
+	for _, test := range []struct {
+		name string
+		file string
+		line int
+	}{
+		{"empty", "", 1},
+		{"header", "rcc coverage 2\n" + program, 1},
+		{"no program", "rcc coverage 1\nruns 1\n00 1 0 0", 3},
+		{"short program", "rcc coverage 1\nprogram 0600", 2},
+		{"runs", "rcc coverage 1\nruns many\n" + program, 2},
+		{"address", "rcc coverage 1\n" + program + "\n100 1 0 0", 3},
+		{"count", "rcc coverage 1\n" + program + "\n00 1 -1 0", 3},
+		{"unknown line", "rcc coverage 1\n" + program + "\nexecutions 1", 3},
+	} {
+		_, err := Read(strings.NewReader(test.file))
+
+		var lineError *memory.LineError
+		if !errors.Is(err, ErrInvalidCoverage) || !errors.As(err, &lineError) || lineError.Line != test.line {
+			t.Errorf("%s: got %v, want %v at line %d", test.name, err, ErrInvalidCoverage, test.line)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package coverage
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/tmornini/rigetti-computing/memory"
+	"github.com/tmornini/rigetti-computing/processor"
+)
+
+// WriteLCOV writes the coverage as an LCOV tracefile for genhtml, CI
+// services and editors. sourceLines maps each instruction's address to its
+// line in source, as AssembleWithLines returns them, so that only the
+// instructions the assembler assembled are reported, however data between
+// them would disassemble. When sourceLines is nil, source is rcc disasm's
+// listing of the program, on the same lines as WriteListing writes
+// instructions
+func (coverage *Coverage) WriteLCOV(
+	writer io.Writer,
+	source string,
+	sourceLines *[256]int,
+) error {
+	var instructions []processor.DisassembledInstruction
+	var lines map[memory.Address]int
+
+	if sourceLines == nil {
+		instructions = processor.DisassembleProgram(&coverage.ProgramMemory)
+		lines = processor.ListingLines(instructions)
+	} else {
+		lines = map[memory.Address]int{}
+
+		for address, line := range sourceLines {
+			if line == 0 {
+				continue
+			}
+
+			instructions = append(
+				instructions,
+				processor.Disassemble(&coverage.ProgramMemory, memory.Address(address)),
+			)
+			lines[memory.Address(address)] = line
+		}
+	}
+
+	_, err := fmt.Fprintf(writer, "TN:\nSF:%s\n", source)
+	if err != nil {
+		return err
+	}
+
+	linesFound, linesHit := 0, 0
+	branchesFound, branchesHit := 0, 0
+
+	for _, instruction := range instructions {
+		address := instruction.Address
+
+		line, ok := lines[address]
+		if !ok || !coverage.counted(instruction) {
+			continue
+		}
+
+		executions := coverage.Executions[address]
+
+		linesFound++
+
+		if executions > 0 {
+			linesHit++
+		}
+
+		_, err = fmt.Fprintf(writer, "DA:%d,%d\n", line, executions)
+		if err != nil {
+			return err
+		}
+
+		if isData(instruction) || !processor.IsConditionalJump(instruction.Bytes[0]) {
+			continue
+		}
+
+		for branch, taken := range []uint64{coverage.Taken[address], coverage.NotTaken[address]} {
+			branchesFound++
+
+			count := "-" // LCOV's never reached
+			if executions > 0 {
+				count = fmt.Sprintf("%d", taken)
+			}
+
+			if taken > 0 {
+				branchesHit++
+			}
+
+			_, err = fmt.Fprintf(writer, "BRDA:%d,0,%d,%s\n", line, branch, count)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err = fmt.Fprintf(
+		writer,
+		"BRF:%d\nBRH:%d\nLF:%d\nLH:%d\nend_of_record\n",
+		branchesFound,
+		branchesHit,
+		linesFound,
+		linesHit,
+	)
+
+	return err
+}
@@ -0,0 +1,66 @@
+package coverage
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/tmornini/rigetti-computing/processor"
+)
+
+// alwaysTakenSource assembles to alwaysTaken
+const alwaysTakenSource = `; JMC always jumps
+        LDI 0x01 X
+        EQL X X
+        JMC DONE
+        .byte 0x06 0x0f
+DONE:   HLT
+`
+
+// TestWriteLCOV writes a DA line for each instruction and a BRDA line for
+// each direction of a conditional jump, on the lines of rcc disasm's
+// listing or of the source
+func TestWriteLCOV(t *testing.T) {
+	_, sourceLines, err := processor.AssembleWithLines(strings.NewReader(alwaysTakenSource))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		name        string
+		coverage    *Coverage
+		sourceLines *[256]int
+		records     string
+	}{
+		{
+			name:     "taken only",
+			coverage: covered(t, &alwaysTaken),
+			// the listing hides the HLT at DONE in an LDI
+			records: "DA:1,1\nDA:2,1\nDA:3,1\nBRDA:3,0,0,1\nBRDA:3,0,1,0\nDA:4,0\nBRF:2\nBRH:1\nLF:4\nLH:3\n",
+		},
+		{
+			name:     "never reached",
+			coverage: New(&alwaysTaken),
+			records:  "DA:1,0\nDA:2,0\nDA:3,0\nBRDA:3,0,0,-\nBRDA:3,0,1,-\nDA:4,0\nBRF:2\nBRH:0\nLF:4\nLH:0\n",
+		},
+		{
+			name:        "source",
+			coverage:    covered(t, &alwaysTaken),
+			sourceLines: &sourceLines,
+			records:     "DA:2,1\nDA:3,1\nDA:4,1\nBRDA:4,0,0,1\nBRDA:4,0,1,0\nDA:6,1\nBRF:2\nBRH:1\nLF:4\nLH:4\n",
+		},
+	} {
+		var tracefile bytes.Buffer
+
+		err := test.coverage.WriteLCOV(&tracefile, "always-taken.asm", test.sourceLines)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := "TN:\nSF:always-taken.asm\n" + test.records + "end_of_record\n"
+
+		if tracefile.String() != expected {
+			t.Errorf("%s: got\n%s\nwant\n%s", test.name, tracefile.String(), expected)
+		}
+	}
+}
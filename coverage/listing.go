@@ -0,0 +1,89 @@
+package coverage
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/tmornini/rigetti-computing/memory"
+	"github.com/tmornini/rigetti-computing/processor"
+)
+
+// WriteListing writes the program disassembled, each instruction annotated
+// with its executions and each JMC and JME with how often it was taken,
+// followed by a summary. Instructions are on the same lines as rcc disasm
+// writes them, which WriteLCOV refers to when there is no source
+func (coverage *Coverage) WriteListing(writer io.Writer) error {
+	disassembled := processor.DisassembleProgram(&coverage.ProgramMemory)
+
+	counted := map[memory.Address]bool{}
+
+	for _, instruction := range disassembled {
+		counted[instruction.Address] = coverage.counted(instruction)
+	}
+
+	err := processor.WriteListing(
+		writer,
+		disassembled,
+		func(address memory.Address) string {
+			if !counted[address] {
+				return ""
+			}
+
+			return coverage.annotation(address)
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	summary := coverage.Summary()
+
+	_, err = fmt.Fprintf(
+		writer,
+		"\n; runs: %d\n"+
+			"; %d of %d instructions executed, %s\n"+
+			"; %d of %d conditional jump directions taken, %s\n",
+		coverage.Runs,
+		summary.InstructionsExecuted,
+		summary.Instructions,
+		percentage(summary.InstructionsExecuted, summary.Instructions),
+		summary.BranchesTaken,
+		summary.Branches,
+		percentage(summary.BranchesTaken, summary.Branches),
+	)
+
+	return err
+}
+
+func (coverage *Coverage) annotation(address memory.Address) string {
+	executions := coverage.Executions[address]
+
+	if executions == 0 {
+		return "NOT EXECUTED"
+	}
+
+	if !processor.IsConditionalJump(coverage.ProgramMemory[address]) {
+		return fmt.Sprintf("%d", executions)
+	}
+
+	taken, notTaken := coverage.Taken[address], coverage.NotTaken[address]
+
+	annotation := fmt.Sprintf("%d, taken %d, not taken %d", executions, taken, notTaken)
+
+	switch {
+	case taken == 0:
+		annotation += ", NEVER TAKEN"
+	case notTaken == 0:
+		annotation += ", ALWAYS TAKEN"
+	}
+
+	return annotation
+}
+
+func percentage(count int, total int) string {
+	if total == 0 {
+		return "100.0%"
+	}
+
+	return fmt.Sprintf("%.1f%%", 100*float64(count)/float64(total))
+}
@@ -0,0 +1,43 @@
+package differential
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/tmornini/rigetti-computing/processor"
+)
+
+// ErrDivergence the processor and the reference reached different states
+var ErrDivergence = errors.New("processor and reference diverge")
+
+// DivergenceError is the first step after which the states differ, and how
+type DivergenceError struct {
+	Step        uint64                            // 0 before the first step
+	Instruction processor.DisassembledInstruction // executed by Step
+	Differences []string
+}
+
+func (err *DivergenceError) Error() string {
+	if err.Step == 0 {
+		return fmt.Sprintf(
+			"%s before the first step: %s",
+			ErrDivergence,
+			strings.Join(err.Differences, ", "),
+		)
+	}
+
+	return fmt.Sprintf(
+		"%s after step %d, %02x: %s: %s",
+		ErrDivergence,
+		err.Step,
+		byte(err.Instruction.Address),
+		err.Instruction.Assembly,
+		strings.Join(err.Differences, ", "),
+	)
+}
+
+// Unwrap allows errors.Is(err, ErrDivergence)
+func (err *DivergenceError) Unwrap() error {
+	return ErrDivergence
+}
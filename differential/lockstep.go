@@ -0,0 +1,144 @@
+package differential
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/tmornini/rigetti-computing/memory"
+	"github.com/tmornini/rigetti-computing/processor"
+	"github.com/tmornini/rigetti-computing/reference"
+)
+
+// state of either implementation, for comparison
+type state struct {
+	registers  processor.Registers
+	mainMemory memory.ReadWrite
+	output     string
+	halted     bool
+	faulted    bool
+}
+
+// Lockstep runs the program on a processor with the instruction set and
+// the default policies, and on a reference.Machine, for up to maxSteps
+// steps, zero is unlimited. It compares the PC, registers, flags, main
+// memory, output and whether each has halted or faulted before the first
+// step and after every step, returning a *DivergenceError when they differ
+func Lockstep(
+	instructionSet processor.InstructionSet,
+	programMemory *memory.ReadOnly,
+	mainMemory *memory.ReadWrite,
+	maxSteps uint64,
+) error {
+	var output bytes.Buffer
+
+	configuration := processor.DefaultConfiguration
+	configuration.Output = &output
+	configuration.TraceOutput = ioutil.Discard
+
+	processorMainMemory := *mainMemory
+
+	p := processor.New(instructionSet, configuration, programMemory, &processorMainMemory)
+	m := reference.New(*programMemory, *mainMemory)
+
+	var halted bool
+	var err error
+	var executed processor.DisassembledInstruction
+
+	for step := uint64(0); ; step++ {
+		actual := state{
+			registers:  p.Registers(),
+			mainMemory: *p.MainMemory(),
+			output:     output.String(),
+			halted:     halted,
+			faulted:    err != nil,
+		}
+
+		differences := actual.differences(machineState(m))
+		if len(differences) > 0 {
+			return &DivergenceError{
+				Step:        step,
+				Instruction: executed,
+				Differences: differences,
+			}
+		}
+
+		if halted || err != nil || (maxSteps != 0 && step == maxSteps) {
+			return nil
+		}
+
+		executed = processor.Disassemble(programMemory, p.Registers().ProgramCounter)
+
+		halted, err = p.Step()
+		m.Step()
+	}
+}
+
+func machineState(m *reference.Machine) state {
+	return state{
+		registers: processor.Registers{
+			ProgramCounter: memory.Address(m.PC),
+
+			X: m.Registers[0],
+			Y: m.Registers[1],
+			Z: m.Registers[2],
+			W: m.Registers[3],
+
+			C: m.C,
+			E: m.E,
+		},
+		mainMemory: m.Memory,
+		output:     string(m.Output),
+		halted:     m.Halted,
+		faulted:    m.Faulted,
+	}
+}
+
+// differences between the processor's state and the reference's
+func (actual state) differences(expected state) []string {
+	var differences []string
+
+	if actual.registers != expected.registers {
+		differences = append(
+			differences,
+			fmt.Sprintf("%s, reference %s", actual.registers, expected.registers),
+		)
+	}
+
+	for address := range actual.mainMemory {
+		if actual.mainMemory[address] != expected.mainMemory[address] {
+			differences = append(
+				differences,
+				fmt.Sprintf(
+					"[%02x] is %02x, reference %02x",
+					address,
+					actual.mainMemory[address],
+					expected.mainMemory[address],
+				),
+			)
+		}
+	}
+
+	if actual.output != expected.output {
+		differences = append(
+			differences,
+			fmt.Sprintf("output is %q, reference %q", actual.output, expected.output),
+		)
+	}
+
+	if actual.halted != expected.halted {
+		differences = append(
+			differences,
+			fmt.Sprintf("halted is %t, reference %t", actual.halted, expected.halted),
+		)
+	}
+
+	if actual.faulted != expected.faulted {
+		differences = append(
+			differences,
+			fmt.Sprintf("faulted is %t, reference %t", actual.faulted, expected.faulted),
+		)
+	}
+
+	return differences
+}
@@ -0,0 +1,179 @@
+package differential
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"math/rand"
+	"path/filepath"
+	"testing"
+
+	"github.com/tmornini/rigetti-computing/memory"
+	"github.com/tmornini/rigetti-computing/processor"
+)
+
+const maxSteps = 1000
+
+var instructionSets = map[string]processor.InstructionSet{
+	"normal": processor.NormalInstructionSet,
+	"debug":  processor.DebugInstructionSet,
+}
+
+// TestFixtures runs every spec program in lockstep with both instruction
+// sets, each from zeroed main memory
+func TestFixtures(t *testing.T) {
+	var programs []string
+
+	for _, pattern := range []string{"*.hex", "*.ihex", "*.srec", "*.asm"} {
+		for _, directory := range []string{"spec-successes", "spec-failures"} {
+			matches, err := filepath.Glob(filepath.Join("..", directory, pattern))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			programs = append(programs, matches...)
+		}
+	}
+
+	if len(programs) == 0 {
+		t.Fatal("no spec programs found")
+	}
+
+	for _, program := range programs {
+		programMemory, err := readProgram(program)
+		if err != nil {
+			continue // spec-failures that do not load
+		}
+
+		for name, instructionSet := range instructionSets {
+			err = Lockstep(instructionSet, programMemory, &memory.ReadWrite{}, maxSteps)
+			if err != nil {
+				t.Errorf("%s with the %s instruction set: %v", program, name, err)
+			}
+		}
+	}
+}
+
+func readProgram(program string) (*memory.ReadOnly, error) {
+	image, err := ioutil.ReadFile(program)
+	if err != nil {
+		return nil, err
+	}
+
+	if filepath.Ext(program) != ".asm" {
+		return memory.NewProgramFromImage(bytes.NewReader(image))
+	}
+
+	assembled, err := processor.Assemble(bytes.NewReader(image))
+	if err != nil {
+		return nil, err
+	}
+
+	return memory.NewProgramFrom(bytes.NewReader(assembled))
+}
+
+// TestRandomPrograms runs random programs and main memory in lockstep. The
+// programs are mostly valid instructions, with occasional unknown opcodes
+// and registers, and jumps within the program
+func TestRandomPrograms(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+
+	for count := 0; count < 5000; count++ {
+		programMemory := randomProgram(random)
+
+		var mainMemory memory.ReadWrite
+		random.Read(mainMemory[:])
+
+		err := Lockstep(processor.NormalInstructionSet, programMemory, &mainMemory, maxSteps)
+		if err != nil {
+			reproducer, err := Minimize(
+				processor.NormalInstructionSet,
+				programMemory,
+				&mainMemory,
+				maxSteps,
+			)
+
+			t.Fatalf("program %d: %v\nreproducer: %x", count, err, reproducer[:length(reproducer)])
+		}
+	}
+}
+
+func randomProgram(random *rand.Rand) *memory.ReadOnly {
+	var programMemory memory.ReadOnly
+
+	size := 1 + random.Intn(64)
+
+	sometimes := func(usually int, otherwise int) byte {
+		if random.Intn(20) == 0 {
+			return byte(random.Intn(otherwise))
+		}
+
+		return byte(random.Intn(usually))
+	}
+
+	for address := 0; address < size; {
+		opcode := sometimes(16, 256)
+		programMemory[address] = opcode
+		address++
+
+		for _, operand := range processor.OpcodeOperands(opcode) {
+			if address == len(programMemory) {
+				break
+			}
+
+			switch {
+			case operand == 'r':
+				programMemory[address] = sometimes(4, 256)
+			case opcode == processor.JMP || processor.IsConditionalJump(opcode):
+				programMemory[address] = sometimes(size, 256)
+			default:
+				programMemory[address] = byte(random.Intn(256))
+			}
+
+			address++
+		}
+	}
+
+	return &programMemory
+}
+
+// TestMinimize finds a small reproducer for a processor whose ADD subtracts
+func TestMinimize(t *testing.T) {
+	broken := processor.NormalInstructionSet
+	broken[processor.ADD] = processor.NormalInstructionSet[processor.SUB]
+
+	programMemory := memory.ReadOnly{
+		0x06, 0x05, 0x00, //       LDI 0x05 X
+		0x06, 0x03, 0x01, //       LDI 0x03 Y
+		0x0e, 0x00, //             PRN X
+		0x09, 0x00, 0x01, //       EQL X Y
+		0x0c, 0x18, //             JMC DONE
+		0x03, 0x00, 0x01, 0x03, // MUL X Y W
+		0x01, 0x00, 0x01, 0x02, // ADD X Y Z
+		0x07, 0x02, 0x01, //       STR Z Y
+		0x0f, //             DONE  HLT
+	}
+
+	err := Lockstep(broken, &programMemory, &memory.ReadWrite{}, maxSteps)
+	if !errors.Is(err, ErrDivergence) {
+		t.Fatalf("got %v, want %v", err, ErrDivergence)
+	}
+
+	reproducer, err := Minimize(broken, &programMemory, &memory.ReadWrite{}, maxSteps)
+
+	var divergence *DivergenceError
+	if !errors.As(err, &divergence) || divergence.Instruction.Bytes[0] != processor.ADD {
+		t.Fatalf("got %v, want a divergence after ADD", err)
+	}
+
+	if length(reproducer) > 8 {
+		t.Errorf("reproducer %x is more than 8 bytes", reproducer[:length(reproducer)])
+	}
+
+	err = Lockstep(processor.NormalInstructionSet, reproducer, &memory.ReadWrite{}, maxSteps)
+	if err != nil {
+		t.Error(err)
+	}
+
+	t.Logf("%v\nreproducer: %x", divergence, reproducer[:length(reproducer)])
+}
@@ -0,0 +1,88 @@
+package differential
+
+import (
+	"github.com/tmornini/rigetti-computing/memory"
+	"github.com/tmornini/rigetti-computing/processor"
+)
+
+// Minimize shrinks a program that diverges into a small reproducer that
+// still does. It deletes runs of bytes, halving their length down to one,
+// moving the bytes after them down, then zeroes single bytes, repeating
+// until neither helps. It returns the reproducer and its divergence, or the
+// program and nil when it does not diverge
+func Minimize(
+	instructionSet processor.InstructionSet,
+	programMemory *memory.ReadOnly,
+	mainMemory *memory.ReadWrite,
+	maxSteps uint64,
+) (*memory.ReadOnly, error) {
+	smallest := *programMemory
+
+	divergence := Lockstep(instructionSet, &smallest, mainMemory, maxSteps)
+	if divergence == nil {
+		return &smallest, nil
+	}
+
+	diverges := func(candidate *memory.ReadOnly) bool {
+		err := Lockstep(instructionSet, candidate, mainMemory, maxSteps)
+		if err == nil {
+			return false
+		}
+
+		smallest = *candidate
+		divergence = err
+
+		return true
+	}
+
+	for shrunk := true; shrunk; {
+		shrunk = false
+
+		for size := length(&smallest) / 2; size >= 1; size /= 2 {
+			for start := 0; start+size <= length(&smallest); {
+				if diverges(deleted(&smallest, start, size)) {
+					shrunk = true
+					continue
+				}
+
+				start += size
+			}
+		}
+
+		for address := range smallest {
+			if smallest[address] == 0 {
+				continue
+			}
+
+			candidate := smallest
+			candidate[address] = 0
+
+			if diverges(&candidate) {
+				shrunk = true
+			}
+		}
+	}
+
+	return &smallest, divergence
+}
+
+// length of a program, up to its last byte that is not 0
+func length(programMemory *memory.ReadOnly) int {
+	for index := len(programMemory) - 1; index >= 0; index-- {
+		if programMemory[index] != 0 {
+			return index + 1
+		}
+	}
+
+	return 0
+}
+
+// deleted is a copy of the program without size bytes from start
+func deleted(programMemory *memory.ReadOnly, start int, size int) *memory.ReadOnly {
+	var candidate memory.ReadOnly
+
+	copy(candidate[:], programMemory[:start])
+	copy(candidate[start:], programMemory[start+size:])
+
+	return &candidate
+}
@@ -0,0 +1,103 @@
+package exhaustive
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Predicate is a Check failing cases for which predicate is false
+func Predicate(predicate func(c *Case) bool) Check {
+	return func(c *Case) error {
+		if !predicate(c) {
+			return ErrPredicateFalse
+		}
+
+		return nil
+	}
+}
+
+// Reference is a Check comparing each case that halted with what reference
+// expects. Reference is given the case's values and a copy of its final
+// state to change into the expected state, so what it leaves alone is not
+// checked
+func Reference(reference func(values []byte, expected *Case)) Check {
+	return func(c *Case) error {
+		if c.Err != nil {
+			return c.Err
+		}
+
+		expected := *c
+		expected.Output = append([]byte{}, c.Output...)
+
+		reference(c.Values, &expected)
+
+		return difference(c, &expected)
+	}
+}
+
+// difference describes how a case's state differs from the expected state
+func difference(c *Case, expected *Case) error {
+	var differences []string
+
+	for _, register := range []struct {
+		name   string
+		actual byte
+		wanted byte
+	}{
+		{"PC", byte(c.Registers.ProgramCounter), byte(expected.Registers.ProgramCounter)},
+		{"X", c.Registers.X, expected.Registers.X},
+		{"Y", c.Registers.Y, expected.Registers.Y},
+		{"Z", c.Registers.Z, expected.Registers.Z},
+		{"W", c.Registers.W, expected.Registers.W},
+	} {
+		if register.actual != register.wanted {
+			differences = append(
+				differences,
+				fmt.Sprintf("%s is %02x, want %02x", register.name, register.actual, register.wanted),
+			)
+		}
+	}
+
+	for _, flag := range []struct {
+		name   string
+		actual bool
+		wanted bool
+	}{
+		{"C", c.Registers.C, expected.Registers.C},
+		{"E", c.Registers.E, expected.Registers.E},
+	} {
+		if flag.actual != flag.wanted {
+			differences = append(
+				differences,
+				fmt.Sprintf("%s is %t, want %t", flag.name, flag.actual, flag.wanted),
+			)
+		}
+	}
+
+	for address := range c.MainMemory {
+		if c.MainMemory[address] != expected.MainMemory[address] {
+			differences = append(
+				differences,
+				fmt.Sprintf(
+					"[%02x] is %02x, want %02x",
+					address,
+					c.MainMemory[address],
+					expected.MainMemory[address],
+				),
+			)
+		}
+	}
+
+	if !bytes.Equal(c.Output, expected.Output) {
+		differences = append(
+			differences,
+			fmt.Sprintf("output is %q, want %q", c.Output, expected.Output),
+		)
+	}
+
+	if len(differences) == 0 {
+		return nil
+	}
+
+	return &MismatchError{Differences: differences}
+}
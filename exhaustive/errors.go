@@ -0,0 +1,72 @@
+package exhaustive
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/tmornini/rigetti-computing/processor"
+)
+
+// ErrInvalidInputs inputs that are not registers, repeated or too many to
+// enumerate
+var ErrInvalidInputs = errors.New("invalid inputs")
+
+// ErrCheckFailed a case whose final state was rejected
+var ErrCheckFailed = errors.New("check failed")
+
+// ErrPredicateFalse a case for which a Predicate is false
+var ErrPredicateFalse = errors.New("predicate is false")
+
+// ErrMismatch a case whose final state is not what a Reference expects
+var ErrMismatch = errors.New("final state differs from the reference")
+
+// InputError identifies why the inputs are invalid
+type InputError struct {
+	Inputs []processor.Input
+	Reason string
+}
+
+func (err *InputError) Error() string {
+	return fmt.Sprintf("%s: %v, %s", ErrInvalidInputs, err.Inputs, err.Reason)
+}
+
+// Unwrap allows errors.Is(err, ErrInvalidInputs)
+func (err *InputError) Unwrap() error {
+	return ErrInvalidInputs
+}
+
+// FailureError is the first case a check rejected, and why
+type FailureError struct {
+	Inputs []processor.Input
+	Case   *Case
+	Err    error
+}
+
+func (err *FailureError) Error() string {
+	return fmt.Sprintf(
+		"%s for %s: %s",
+		ErrCheckFailed,
+		assignments(err.Inputs, err.Case.Values),
+		err.Err,
+	)
+}
+
+// Unwrap allows errors.Is(err, ErrCheckFailed)
+func (err *FailureError) Unwrap() error {
+	return ErrCheckFailed
+}
+
+// MismatchError lists how the final state differs from the reference
+type MismatchError struct {
+	Differences []string
+}
+
+func (err *MismatchError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrMismatch, strings.Join(err.Differences, ", "))
+}
+
+// Unwrap allows errors.Is(err, ErrMismatch)
+func (err *MismatchError) Unwrap() error {
+	return ErrMismatch
+}
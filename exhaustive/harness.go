@@ -0,0 +1,251 @@
+package exhaustive
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/tmornini/rigetti-computing/memory"
+	"github.com/tmornini/rigetti-computing/processor"
+)
+
+// MaxInputs is the most inputs a harness enumerates, 16,777,216 cases
+const MaxInputs = 3
+
+// DefaultMaxSteps stops each case that has not halted after this many
+// instructions
+const DefaultMaxSteps = 10000
+
+// casesPerBlock is how many consecutive cases a worker takes at a time
+const casesPerBlock = 256
+
+// Register inputs, each set to its 256 values in turn
+var (
+	X = processor.Input{Index: 0}
+	Y = processor.Input{Index: 1}
+	Z = processor.Input{Index: 2}
+	W = processor.Input{Index: 3}
+)
+
+// MainMemory is the input at an address of main memory
+func MainMemory(address memory.Address) processor.Input {
+	return processor.Input{Memory: true, Index: byte(address)}
+}
+
+// Case is a combination of the inputs' values and the state the program
+// ended in
+type Case struct {
+	Values []byte // of the harness's inputs
+
+	Registers  processor.Registers
+	MainMemory memory.ReadWrite
+	Output     []byte // printed by PRN
+	Steps      uint64
+
+	// Err stopped the program, nil when it halted
+	Err error
+}
+
+// Check returns an error when a case's final state is wrong
+type Check func(c *Case) error
+
+// Harness runs a program for every combination of its inputs' values
+type Harness struct {
+	ProgramMemory *memory.ReadOnly
+
+	// MainMemory is what main memory holds before the inputs are set.
+	// Registers other than the inputs start at 0
+	MainMemory memory.ReadWrite
+
+	Inputs []processor.Input
+
+	// Configuration each case's processor is created with. Output is
+	// replaced, Timing must be nil and Observers safe for concurrent use
+	Configuration processor.Configuration
+
+	// Workers run cases in parallel, each with its own processors. Zero is
+	// one per CPU
+	Workers int
+}
+
+// New harness for the program with the default policies and a limit of
+// DefaultMaxSteps
+func New(programMemory *memory.ReadOnly, inputs ...processor.Input) *Harness {
+	configuration := processor.DefaultConfiguration
+	configuration.MaxSteps = DefaultMaxSteps
+
+	return &Harness{
+		ProgramMemory: programMemory,
+		Inputs:        inputs,
+		Configuration: configuration,
+	}
+}
+
+// Cases is how many combinations of values the inputs have
+func (harness *Harness) Cases() uint64 {
+	return 1 << (8 * uint(len(harness.Inputs)))
+}
+
+// Verify runs every case, returning a *FailureError for the first whose
+// final state check rejects. Cases are ordered by their values as a number,
+// the last input varying fastest, so the failure reported does not depend
+// on how the workers are scheduled
+func (harness *Harness) Verify(check Check) error {
+	err := harness.validate()
+	if err != nil {
+		return err
+	}
+
+	workers := harness.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	cases := harness.Cases()
+	blocks := (cases + casesPerBlock - 1) / casesPerBlock
+
+	var (
+		nextBlock uint64
+		mutex     sync.Mutex
+		failure   *FailureError
+		failed    = cases // index of the first failure found so far
+	)
+
+	var wait sync.WaitGroup
+
+	for worker := 0; worker < workers; worker++ {
+		wait.Add(1)
+
+		go func() {
+			defer wait.Done()
+
+			for {
+				block := atomic.AddUint64(&nextBlock, 1) - 1
+				first := block * casesPerBlock
+
+				if block >= blocks || first > atomic.LoadUint64(&failed) {
+					return
+				}
+
+				last := first + casesPerBlock
+				if last > cases {
+					last = cases
+				}
+
+				for index := first; index < last; index++ {
+					c := harness.run(index)
+
+					err := check(c)
+					if err == nil {
+						continue
+					}
+
+					mutex.Lock()
+
+					if index < failed {
+						failure = &FailureError{
+							Inputs: harness.Inputs,
+							Case:   c,
+							Err:    err,
+						}
+
+						atomic.StoreUint64(&failed, index)
+					}
+
+					mutex.Unlock()
+
+					break
+				}
+			}
+		}()
+	}
+
+	wait.Wait()
+
+	if failure != nil {
+		return failure
+	}
+
+	return nil
+}
+
+func (harness *Harness) validate() error {
+	if len(harness.Inputs) > MaxInputs {
+		return &InputError{Inputs: harness.Inputs, Reason: "too many"}
+	}
+
+	seen := map[processor.Input]bool{}
+
+	for _, input := range harness.Inputs {
+		if !input.Valid() {
+			return &InputError{Inputs: harness.Inputs, Reason: input.String() + " is not X-W"}
+		}
+
+		if seen[input] {
+			return &InputError{Inputs: harness.Inputs, Reason: input.String() + " repeated"}
+		}
+
+		seen[input] = true
+	}
+
+	return nil
+}
+
+// values of the inputs for the case at index
+func (harness *Harness) values(index uint64) []byte {
+	values := make([]byte, len(harness.Inputs))
+
+	for position := range values {
+		shift := 8 * uint(len(values)-1-position)
+		values[position] = byte(index >> shift)
+	}
+
+	return values
+}
+
+// run the case at index on a processor of its own
+func (harness *Harness) run(index uint64) *Case {
+	c := &Case{Values: harness.values(index)}
+
+	mainMemory := harness.MainMemory
+
+	var output bytes.Buffer
+
+	configuration := harness.Configuration
+	configuration.Output = &output
+	configuration.TraceOutput = ioutil.Discard
+
+	p := processor.New(
+		processor.NormalInstructionSet,
+		configuration,
+		harness.ProgramMemory,
+		&mainMemory,
+	)
+
+	c.Err = p.Preset(harness.Inputs, c.Values)
+	if c.Err == nil {
+		c.Err = p.Run()
+	}
+
+	c.Registers = p.Registers()
+	c.MainMemory = *p.MainMemory()
+	c.Output = output.Bytes()
+	c.Steps = p.Steps()
+
+	return c
+}
+
+// assignments of the values to the inputs, e.g. X=80 Y=03
+func assignments(inputs []processor.Input, values []byte) string {
+	var texts []string
+
+	for position, input := range inputs {
+		texts = append(texts, fmt.Sprintf("%s=%02x", input, values[position]))
+	}
+
+	return strings.Join(texts, " ")
+}
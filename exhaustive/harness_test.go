@@ -0,0 +1,127 @@
+package exhaustive
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/tmornini/rigetti-computing/memory"
+	"github.com/tmornini/rigetti-computing/processor"
+)
+
+// multiply leaves X times Y in Z by adding X to Z, Y times
+var multiply = memory.ReadOnly{
+	0x06, 0x00, 0x03, // LOOP  LDI 0x00 W
+	0x09, 0x01, 0x03, //       EQL Y W
+	0x0c, 0x15, //             JMC DONE
+	0x06, 0x01, 0x03, //       LDI 0x01 W
+	0x02, 0x01, 0x03, 0x01, // SUB Y W Y
+	0x01, 0x02, 0x00, 0x02, // ADD Z X Z
+	0x0b, 0x00, //             JMP LOOP
+	0x0f, //             DONE  HLT
+}
+
+func multiplied(values []byte, expected *Case) {
+	expected.Registers.Y = 0
+	expected.Registers.Z = values[0] * values[1]
+	expected.Registers.W = 0
+	expected.Registers.C = false
+}
+
+// TestMultiply verifies multiply for all 65,536 pairs of X and Y
+func TestMultiply(t *testing.T) {
+	harness := New(&multiply, X, Y)
+
+	if harness.Cases() != 1<<16 {
+		t.Fatalf("got %d cases, want %d", harness.Cases(), 1<<16)
+	}
+
+	err := harness.Verify(Reference(multiplied))
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestFirstFailure reports the first of many failing cases, whatever the
+// number of workers
+func TestFirstFailure(t *testing.T) {
+	double := memory.ReadOnly{
+		0x01, 0x00, 0x00, 0x02, // ADD X X Z
+		0x0f, //                   HLT
+	}
+
+	for _, workers := range []int{1, 3, 8} {
+		harness := New(&double, X, MainMemory(0x10))
+		harness.Workers = workers
+
+		err := harness.Verify(Predicate(func(c *Case) bool {
+			return c.Registers.Z < 0x02 || c.MainMemory[0x10] < 0x40
+		}))
+
+		var failure *FailureError
+		if !errors.As(err, &failure) || !errors.Is(err, ErrCheckFailed) {
+			t.Fatalf("%d workers: got %v, want a *FailureError", workers, err)
+		}
+
+		want := "check failed for X=01 [10]=40: predicate is false"
+		if err.Error() != want {
+			t.Errorf("%d workers: got %q, want %q", workers, err, want)
+		}
+	}
+}
+
+// TestReferenceMismatch describes how the final state differs
+func TestReferenceMismatch(t *testing.T) {
+	err := New(&multiply, X, Y).Verify(Reference(func(values []byte, expected *Case) {
+		multiplied(values, expected)
+
+		if values[0] == 0x03 && values[1] == 0x05 {
+			expected.Registers.Z = 0x10
+			expected.MainMemory[0xff] = 0x01
+		}
+	}))
+
+	want := "check failed for X=03 Y=05: final state differs from the " +
+		"reference: Z is 0f, want 10, [ff] is 00, want 01"
+
+	if err == nil || err.Error() != want {
+		t.Errorf("got %v, want %q", err, want)
+	}
+}
+
+// TestStepLimit fails cases that do not halt
+func TestStepLimit(t *testing.T) {
+	loop := memory.ReadOnly{
+		0x0b, 0x00, // LOOP JMP LOOP
+	}
+
+	harness := New(&loop, W)
+	harness.Configuration.MaxSteps = 100
+
+	err := harness.Verify(Reference(func([]byte, *Case) {}))
+
+	var failure *FailureError
+	if !errors.As(err, &failure) {
+		t.Fatalf("got %v, want a *FailureError", err)
+	}
+
+	var limit *processor.StepLimitError
+	if !errors.As(failure.Err, &limit) || failure.Case.Values[0] != 0 {
+		t.Errorf("got %v for W=%02x, want a step limit for W=00", failure.Err, failure.Case.Values[0])
+	}
+}
+
+// TestInvalidInputs rejects registers other than X-W, repeated inputs and
+// more than MaxInputs
+func TestInvalidInputs(t *testing.T) {
+	for _, inputs := range [][]processor.Input{
+		{X, Y, Z, W},
+		{X, MainMemory(0x20), MainMemory(0x20)},
+		{X, {Index: 7}},
+	} {
+		err := New(&multiply, inputs...).Verify(Predicate(func(*Case) bool { return true }))
+
+		if !errors.Is(err, ErrInvalidInputs) {
+			t.Errorf("%v: got %v, want %v", inputs, err, ErrInvalidInputs)
+		}
+	}
+}
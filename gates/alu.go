@@ -0,0 +1,197 @@
+package gates
+
+import "github.com/tmornini/rigetti-computing/processor"
+
+// Operation selects what the ALU computes
+type Operation int
+
+const (
+	// AddOperation r1 + r2, as ADD
+	AddOperation Operation = iota
+
+	// SubOperation r1 - r2, as SUB
+	SubOperation
+
+	// MulOperation r1 * r2, as MUL
+	MulOperation
+
+	// DivOperation r1 / r2, as DIV, setting E when r2 is 0
+	DivOperation
+
+	// EqlOperation sets C when r1 equals r2, as EQL
+	EqlOperation
+
+	// NqlOperation sets C when r1 does not equal r2, as NQL
+	NqlOperation
+
+	numberOfOperations
+)
+
+// operationOpcodes are the mnemonics of the instructions each operation
+// executes
+var operationOpcodes = [numberOfOperations]string{
+	"ADD", "SUB", "MUL", "DIV", "EQL", "NQL",
+}
+
+// Opcode executed by the operation
+func (operation Operation) Opcode() byte {
+	opcode, _ := processor.ParseOpcode(operationOpcodes[operation])
+
+	return opcode
+}
+
+func (operation Operation) String() string {
+	if operation < 0 || operation >= numberOfOperations {
+		return "???"
+	}
+
+	return operationOpcodes[operation]
+}
+
+// operationBits selects an operation, in binary
+const operationBits = 3
+
+// ALU computes every operation on two 8-bit operands at once, selecting
+// the result and flags of the one Operation selects
+type ALU struct {
+	*Circuit
+
+	A         Bus // r1
+	B         Bus // r2
+	Operation Bus
+
+	Result Bus // for ADD, SUB, MUL and DIV
+
+	// C is the C flag EQL and NQL set, 0 for other operations
+	C Wire
+
+	// DivideByZero is DIV's E flag, 0 for other operations
+	DivideByZero Wire
+}
+
+// NewALU builds the ALU from gates
+func NewALU() *ALU {
+	circuit := NewCircuit()
+
+	alu := &ALU{
+		Circuit:   circuit,
+		A:         circuit.InputBus(8),
+		B:         circuit.InputBus(8),
+		Operation: circuit.InputBus(operationBits),
+	}
+
+	decode := circuit.decoder(alu.Operation)
+	selected := make([]Wire, numberOfOperations)
+
+	for operation := range selected {
+		selected[operation] = decode(operation)
+	}
+
+	sum, _ := circuit.Add(alu.A, alu.B, circuit.Constant(false))
+	difference, _ := circuit.Subtract(alu.A, alu.B)
+	product := circuit.Multiply(alu.A, alu.B)
+	quotient, _ := circuit.Divide(alu.A, alu.B)
+
+	results := [][]Wire{}
+
+	for _, result := range []struct {
+		operation Operation
+		value     Bus
+	}{
+		{AddOperation, sum},
+		{SubOperation, difference},
+		{MulOperation, product},
+		{DivOperation, quotient},
+	} {
+		gated := make([]Wire, len(result.value))
+
+		for index, wire := range result.value {
+			gated[index] = circuit.And(selected[result.operation], wire)
+		}
+
+		results = append(results, gated)
+	}
+
+	alu.Result = make(Bus, 8)
+
+	for bit := range alu.Result {
+		var candidates []Wire
+
+		for _, result := range results {
+			candidates = append(candidates, result[bit])
+		}
+
+		alu.Result[bit] = circuit.OrAll(candidates...)
+	}
+
+	equal := circuit.Equal(alu.A, alu.B)
+
+	alu.C = circuit.Or(
+		circuit.And(selected[EqlOperation], equal),
+		circuit.And(selected[NqlOperation], circuit.Not(equal)),
+	)
+
+	alu.DivideByZero = circuit.And(selected[DivOperation], circuit.IsZero(alu.B))
+
+	return alu
+}
+
+// decoder returns a function building a wire that is 1 when number is a
+// value, sharing the NOT gates of every value it decodes
+func (circuit *Circuit) decoder(number Bus) func(value int) Wire {
+	inverted := make([]Wire, len(number))
+
+	for index, wire := range number {
+		inverted[index] = circuit.Not(wire)
+	}
+
+	return func(value int) Wire {
+		literals := make([]Wire, len(number))
+
+		for index := range number {
+			if value>>uint(index)&1 == 1 {
+				literals[index] = number[index]
+			} else {
+				literals[index] = inverted[index]
+			}
+		}
+
+		return circuit.AndAll(literals...)
+	}
+}
+
+// ALUOutput is what the ALU computed
+type ALUOutput struct {
+	Result       byte
+	C            bool
+	DivideByZero bool
+}
+
+// Execute simulates the ALU for one operation
+func (alu *ALU) Execute(operation Operation, a, b byte) ALUOutput {
+	simulation := alu.NewSimulation()
+
+	return alu.execute(simulation, operation, a, b)
+}
+
+func (alu *ALU) execute(
+	simulation *Simulation,
+	operation Operation,
+	a, b byte,
+) ALUOutput {
+	simulation.SetBus(alu.A, uint64(a))
+	simulation.SetBus(alu.B, uint64(b))
+	simulation.SetBus(alu.Operation, uint64(operation))
+	simulation.Settle()
+
+	return ALUOutput{
+		Result:       byte(simulation.GetBus(alu.Result)),
+		C:            simulation.Get(alu.C),
+		DivideByZero: simulation.Get(alu.DivideByZero),
+	}
+}
+
+// Outputs of the ALU, for Statistics
+func (alu *ALU) Outputs() []Wire {
+	return append(append(Bus{}, alu.Result...), alu.C, alu.DivideByZero)
+}
@@ -0,0 +1,105 @@
+package gates
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/tmornini/rigetti-computing/memory"
+	"github.com/tmornini/rigetti-computing/processor"
+)
+
+// TestALU simulates the ALU for every operation and all 65,536 pairs of
+// operands, comparing it with the processor executing the instruction with
+// X and Y as operands
+func TestALU(t *testing.T) {
+	alu := NewALU()
+	simulation := alu.NewSimulation()
+
+	for operation := Operation(0); operation < numberOfOperations; operation++ {
+		t.Run(operation.String(), func(t *testing.T) {
+			p := newProcessor(t, operation)
+			failures := 0
+
+			for pair := 0; pair < 1<<16; pair++ {
+				a, b := byte(pair>>8), byte(pair)
+
+				p.SetRegisters(processor.Registers{X: a, Y: b})
+
+				_, err := p.Step()
+				if err != nil {
+					t.Fatalf("%s %02x %02x: %v", operation, a, b, err)
+				}
+
+				registers := p.Registers()
+				got := alu.execute(simulation, operation, a, b)
+				want := ALUOutput{DivideByZero: registers.E}
+
+				switch operation {
+				case EqlOperation, NqlOperation:
+					want.C = registers.C
+				case DivOperation:
+					// Z is unchanged by divide by zero, and the quotient is
+					// unused
+					if registers.E {
+						want.Result = got.Result
+					} else {
+						want.Result = registers.Z
+					}
+				default:
+					want.Result = registers.Z
+				}
+
+				if got != want {
+					t.Errorf(
+						"%s %02x %02x: got %+v, processor %+v",
+						operation, a, b, got, want,
+					)
+
+					failures++
+					if failures == 10 {
+						t.FailNow()
+					}
+				}
+			}
+		})
+	}
+}
+
+// newProcessor has a program that executes operation on X and Y, into Z
+func newProcessor(t *testing.T, operation Operation) *processor.Processor {
+	program := []byte{operation.Opcode(), 0x00, 0x01, 0x02}
+
+	if operation == EqlOperation || operation == NqlOperation {
+		program = program[:3]
+	}
+
+	programMemory, err := memory.NewProgramFrom(bytes.NewReader(program))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	configuration := processor.DefaultConfiguration
+	configuration.Output = &bytes.Buffer{}
+	configuration.TraceOutput = &bytes.Buffer{}
+
+	return processor.New(
+		processor.NormalInstructionSet,
+		configuration,
+		programMemory,
+		&memory.ReadWrite{},
+	)
+}
+
+// TestALUStatistics checks the ALU only counts gates its outputs use
+func TestALUStatistics(t *testing.T) {
+	alu := NewALU()
+	statistics := alu.Statistics(alu.Outputs()...)
+
+	if statistics.Total() == 0 || statistics.Total() >= len(alu.gates) {
+		t.Errorf("%d gates of %d wires", statistics.Total(), len(alu.gates))
+	}
+
+	if statistics.Depth == 0 {
+		t.Error("depth 0")
+	}
+}
@@ -0,0 +1,121 @@
+package gates
+
+// FullAdder adds three bits: 2 XOR, 2 AND and 1 OR gate
+func (circuit *Circuit) FullAdder(a, b, carryIn Wire) (sum Wire, carryOut Wire) {
+	partial := circuit.Xor(a, b)
+
+	sum = circuit.Xor(partial, carryIn)
+	carryOut = circuit.Or(circuit.And(a, b), circuit.And(partial, carryIn))
+
+	return sum, carryOut
+}
+
+// Add is a ripple carry adder, wrapping around as wide as a, which b must
+// be as wide as
+func (circuit *Circuit) Add(a, b Bus, carryIn Wire) (sum Bus, carryOut Wire) {
+	sum = make(Bus, len(a))
+	carry := carryIn
+
+	for index := range a {
+		sum[index], carry = circuit.FullAdder(a[index], b[index], carry)
+	}
+
+	return sum, carry
+}
+
+// Subtract adds a to the two's complement of b, wrapping around. borrow is
+// 1 when b is greater than a
+func (circuit *Circuit) Subtract(a, b Bus) (difference Bus, borrow Wire) {
+	notB := make(Bus, len(b))
+
+	for index, wire := range b {
+		notB[index] = circuit.Not(wire)
+	}
+
+	difference, carry := circuit.Add(a, notB, circuit.Constant(true))
+
+	return difference, circuit.Not(carry)
+}
+
+// Multiply is an array multiplier, keeping the low bits of the product, as
+// wide as a. Each bit of b adds a, shifted left by its position
+func (circuit *Circuit) Multiply(a, b Bus) Bus {
+	width := len(a)
+	product := circuit.partialProduct(a, b[0], width)
+
+	for shift := 1; shift < width; shift++ {
+		partial := circuit.partialProduct(a, b[shift], width-shift)
+
+		high, _ := circuit.Add(product[shift:], partial, circuit.Constant(false))
+
+		product = append(product[:shift:shift], high...)
+	}
+
+	return product
+}
+
+// partialProduct is the low width bits of a when bit is 1, otherwise 0
+func (circuit *Circuit) partialProduct(a Bus, bit Wire, width int) Bus {
+	partial := make(Bus, width)
+
+	for index := range partial {
+		partial[index] = circuit.And(a[index], bit)
+	}
+
+	return partial
+}
+
+// Divide is a restoring divider, one stage per bit of the quotient. Each
+// stage shifts the next bit of a into the remainder, and subtracts b when it
+// does not borrow. When b is 0 the quotient is all 1s and the remainder a
+func (circuit *Circuit) Divide(a, b Bus) (quotient Bus, remainder Bus) {
+	width := len(a)
+
+	quotient = make(Bus, width)
+	remainder = circuit.ConstantBus(width, 0)
+
+	divisor := append(append(Bus{}, b...), circuit.Constant(false))
+
+	for index := width - 1; index >= 0; index-- {
+		shifted := append(Bus{a[index]}, remainder...)
+
+		difference, borrow := circuit.Subtract(shifted, divisor)
+
+		quotient[index] = circuit.Not(borrow)
+		remainder = circuit.Mux(borrow, shifted[:width], difference[:width])
+	}
+
+	return quotient, remainder
+}
+
+// Mux is a when sel is 1, otherwise b: 1 NOT gate, and 2 AND and 1 OR gate
+// per bit
+func (circuit *Circuit) Mux(sel Wire, a, b Bus) Bus {
+	notSel := circuit.Not(sel)
+	selected := make(Bus, len(a))
+
+	for index := range a {
+		selected[index] = circuit.Or(
+			circuit.And(sel, a[index]),
+			circuit.And(notSel, b[index]),
+		)
+	}
+
+	return selected
+}
+
+// Equal is 1 when every bit of a equals b's
+func (circuit *Circuit) Equal(a, b Bus) Wire {
+	differences := make([]Wire, len(a))
+
+	for index := range a {
+		differences[index] = circuit.Xor(a[index], b[index])
+	}
+
+	return circuit.Not(circuit.OrAll(differences...))
+}
+
+// IsZero is 1 when every bit of a is 0
+func (circuit *Circuit) IsZero(a Bus) Wire {
+	return circuit.Not(circuit.OrAll(a...))
+}
@@ -0,0 +1,359 @@
+package gates
+
+// Circuit is combinational logic built from 2-input AND, OR and XOR gates
+// and NOT gates. Gates are added after the wires they read, so simulating
+// them in order settles the circuit in a single pass
+type Circuit struct {
+	gates []gate
+	depth []int // gates on the longest path to each wire
+
+	constants [2]Wire
+}
+
+// Wire is the output of an input, constant or gate
+type Wire int
+
+// Bus is a group of wires holding a number, least significant bit first
+type Bus []Wire
+
+// Kind of gate
+type Kind int
+
+const (
+	// Input set from outside the circuit
+	Input Kind = iota
+
+	// Constant 0 or 1
+	Constant
+
+	// Not gate
+	Not
+
+	// And gate
+	And
+
+	// Or gate
+	Or
+
+	// Xor gate
+	Xor
+
+	numberOfKinds
+)
+
+var kindNames = [numberOfKinds]string{
+	"input",
+	"constant",
+	"NOT",
+	"AND",
+	"OR",
+	"XOR",
+}
+
+func (kind Kind) String() string {
+	if kind < 0 || kind >= numberOfKinds {
+		return "???"
+	}
+
+	return kindNames[kind]
+}
+
+type gate struct {
+	kind  Kind
+	a     Wire
+	b     Wire
+	value bool // Constant
+}
+
+// NewCircuit has no gates, only the constants 0 and 1
+func NewCircuit() *Circuit {
+	circuit := &Circuit{}
+
+	circuit.constants[0] = circuit.add(gate{kind: Constant, value: false})
+	circuit.constants[1] = circuit.add(gate{kind: Constant, value: true})
+
+	return circuit
+}
+
+func (circuit *Circuit) add(g gate) Wire {
+	depth := 0
+
+	switch g.kind {
+	case Not:
+		depth = circuit.depth[g.a] + 1
+	case And, Or, Xor:
+		depth = deeper(circuit.depth[g.a], circuit.depth[g.b]) + 1
+	}
+
+	circuit.gates = append(circuit.gates, g)
+	circuit.depth = append(circuit.depth, depth)
+
+	return Wire(len(circuit.gates) - 1)
+}
+
+// Input adds an input wire
+func (circuit *Circuit) Input() Wire {
+	return circuit.add(gate{kind: Input})
+}
+
+// InputBus adds width input wires
+func (circuit *Circuit) InputBus(width int) Bus {
+	bus := make(Bus, width)
+
+	for index := range bus {
+		bus[index] = circuit.Input()
+	}
+
+	return bus
+}
+
+// Constant is a wire that is always value
+func (circuit *Circuit) Constant(value bool) Wire {
+	if value {
+		return circuit.constants[1]
+	}
+
+	return circuit.constants[0]
+}
+
+// ConstantBus is width wires always holding value
+func (circuit *Circuit) ConstantBus(width int, value uint64) Bus {
+	bus := make(Bus, width)
+
+	for index := range bus {
+		bus[index] = circuit.Constant(value>>uint(index)&1 == 1)
+	}
+
+	return bus
+}
+
+// Not adds a NOT gate, unless a is constant
+func (circuit *Circuit) Not(a Wire) Wire {
+	if value, ok := circuit.constant(a); ok {
+		return circuit.Constant(!value)
+	}
+
+	return circuit.add(gate{kind: Not, a: a})
+}
+
+// And adds an AND gate, unless an input is constant
+func (circuit *Circuit) And(a, b Wire) Wire {
+	a, b, value, ok := circuit.constantLast(a, b)
+
+	switch {
+	case !ok:
+		return circuit.add(gate{kind: And, a: a, b: b})
+	case value:
+		return a
+	default:
+		return circuit.Constant(false)
+	}
+}
+
+// Or adds an OR gate, unless an input is constant
+func (circuit *Circuit) Or(a, b Wire) Wire {
+	a, b, value, ok := circuit.constantLast(a, b)
+
+	switch {
+	case !ok:
+		return circuit.add(gate{kind: Or, a: a, b: b})
+	case value:
+		return circuit.Constant(true)
+	default:
+		return a
+	}
+}
+
+// Xor adds an XOR gate, unless an input is constant
+func (circuit *Circuit) Xor(a, b Wire) Wire {
+	a, b, value, ok := circuit.constantLast(a, b)
+
+	switch {
+	case !ok:
+		return circuit.add(gate{kind: Xor, a: a, b: b})
+	case value:
+		return circuit.Not(a)
+	default:
+		return a
+	}
+}
+
+func (circuit *Circuit) constant(wire Wire) (value bool, ok bool) {
+	g := circuit.gates[wire]
+
+	return g.value, g.kind == Constant
+}
+
+// constantLast swaps a and b when a is constant, reporting b's value when
+// it is constant
+func (circuit *Circuit) constantLast(a, b Wire) (Wire, Wire, bool, bool) {
+	if _, ok := circuit.constant(a); ok {
+		a, b = b, a
+	}
+
+	value, ok := circuit.constant(b)
+
+	return a, b, value, ok
+}
+
+// AndAll ands wires as a balanced tree, so its depth grows with the
+// logarithm of their number. 1 when there are none
+func (circuit *Circuit) AndAll(wires ...Wire) Wire {
+	return circuit.tree(circuit.And, circuit.Constant(true), wires)
+}
+
+// OrAll ors wires as a balanced tree. 0 when there are none
+func (circuit *Circuit) OrAll(wires ...Wire) Wire {
+	return circuit.tree(circuit.Or, circuit.Constant(false), wires)
+}
+
+func (circuit *Circuit) tree(
+	combine func(a, b Wire) Wire,
+	identity Wire,
+	wires []Wire,
+) Wire {
+	switch len(wires) {
+	case 0:
+		return identity
+	case 1:
+		return wires[0]
+	}
+
+	half := len(wires) / 2
+
+	return combine(
+		circuit.tree(combine, identity, wires[:half]),
+		circuit.tree(combine, identity, wires[half:]),
+	)
+}
+
+// Simulation holds the value of every wire in a circuit
+type Simulation struct {
+	circuit *Circuit
+	values  []bool
+}
+
+// NewSimulation has every input 0
+func (circuit *Circuit) NewSimulation() *Simulation {
+	return &Simulation{
+		circuit: circuit,
+		values:  make([]bool, len(circuit.gates)),
+	}
+}
+
+// Set an input
+func (simulation *Simulation) Set(wire Wire, value bool) {
+	simulation.values[wire] = value
+}
+
+// SetBus sets a bus of inputs to a number
+func (simulation *Simulation) SetBus(bus Bus, value uint64) {
+	for index, wire := range bus {
+		simulation.values[wire] = value>>uint(index)&1 == 1
+	}
+}
+
+// Settle evaluates every gate, in the order they were added
+func (simulation *Simulation) Settle() {
+	values := simulation.values
+
+	for index, g := range simulation.circuit.gates {
+		switch g.kind {
+		case Constant:
+			values[index] = g.value
+		case Not:
+			values[index] = !values[g.a]
+		case And:
+			values[index] = values[g.a] && values[g.b]
+		case Or:
+			values[index] = values[g.a] || values[g.b]
+		case Xor:
+			values[index] = values[g.a] != values[g.b]
+		}
+	}
+}
+
+// Get a wire, as of the last Settle
+func (simulation *Simulation) Get(wire Wire) bool {
+	return simulation.values[wire]
+}
+
+// GetBus reads a bus as a number, as of the last Settle
+func (simulation *Simulation) GetBus(bus Bus) uint64 {
+	var value uint64
+
+	for index, wire := range bus {
+		if simulation.values[wire] {
+			value |= 1 << uint(index)
+		}
+	}
+
+	return value
+}
+
+// Statistics counts gates, and measures propagation delay in gates
+type Statistics struct {
+	Gates [numberOfKinds]int // Input and Constant are not gates, so are 0
+
+	// Depth is the most gates on any path from an input to an output
+	Depth int
+}
+
+// Total gates
+func (statistics Statistics) Total() int {
+	total := 0
+
+	for _, gates := range statistics.Gates {
+		total += gates
+	}
+
+	return total
+}
+
+// Statistics of the gates outputs depend on, and the depth of the deepest
+func (circuit *Circuit) Statistics(outputs ...Wire) Statistics {
+	var statistics Statistics
+
+	live := make([]bool, len(circuit.gates))
+	pending := append([]Wire{}, outputs...)
+
+	for len(pending) > 0 {
+		wire := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+
+		if live[wire] {
+			continue
+		}
+
+		live[wire] = true
+
+		g := circuit.gates[wire]
+
+		switch g.kind {
+		case Not:
+			pending = append(pending, g.a)
+		case And, Or, Xor:
+			pending = append(pending, g.a, g.b)
+		}
+	}
+
+	for wire, g := range circuit.gates {
+		if live[wire] && g.kind != Input && g.kind != Constant {
+			statistics.Gates[g.kind]++
+		}
+	}
+
+	for _, output := range outputs {
+		statistics.Depth = deeper(statistics.Depth, circuit.depth[output])
+	}
+
+	return statistics
+}
+
+func deeper(a, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,166 @@
+package gates
+
+import "github.com/tmornini/rigetti-computing/processor"
+
+// ControlUnit decodes an opcode into the signals that steer the datapath.
+// Its opcodes, their lengths and operand kinds come from the processor's
+// tables
+type ControlUnit struct {
+	*Circuit
+
+	Opcode Bus
+
+	// Is has a wire for each known opcode, 1 when it is decoded
+	Is map[byte]Wire
+
+	Known  Wire // 0 for unknown opcodes, which fault
+	Length Bus  // bytes in the instruction, 1 for unknown opcodes
+
+	ALU          Wire // the ALU executes the instruction
+	ALUOperation Bus  // which Operation, when ALU is 1
+
+	// RegisterOperands is how many of the parameters are registers, which
+	// must be checked
+	RegisterOperands Bus
+
+	ReadsMainMemory  Wire // LDM
+	WritesMainMemory Wire // STR
+	Jumps            Wire // JMP, JMC and JME
+	Prints           Wire // PRN
+	Halts            Wire // HLT
+}
+
+// NewControlUnit builds the control unit from gates
+func NewControlUnit() *ControlUnit {
+	circuit := NewCircuit()
+
+	control := &ControlUnit{
+		Circuit: circuit,
+		Opcode:  circuit.InputBus(8),
+		Is:      map[byte]Wire{},
+	}
+
+	decode := circuit.decoder(control.Opcode)
+
+	var known []Wire
+	lengthBits := make([][]Wire, 3)
+	registerBits := make([][]Wire, 2)
+
+	for opcode := 0; opcode < 256; opcode++ {
+		if processor.OpcodeName(byte(opcode)) == "???" {
+			continue
+		}
+
+		is := decode(opcode)
+
+		control.Is[byte(opcode)] = is
+		known = append(known, is)
+
+		length := processor.InstructionLength(byte(opcode))
+
+		for bit := range lengthBits {
+			if length>>uint(bit)&1 == 1 {
+				lengthBits[bit] = append(lengthBits[bit], is)
+			}
+		}
+
+		registers := 0
+
+		for _, kind := range processor.OpcodeOperands(byte(opcode)) {
+			if kind == 'r' {
+				registers++
+			}
+		}
+
+		for bit := range registerBits {
+			if registers>>uint(bit)&1 == 1 {
+				registerBits[bit] = append(registerBits[bit], is)
+			}
+		}
+	}
+
+	control.Known = circuit.OrAll(known...)
+
+	// unknown opcodes are 1 byte long
+	lengthBits[0] = append(lengthBits[0], circuit.Not(control.Known))
+
+	control.Length = make(Bus, len(lengthBits))
+
+	for bit, wires := range lengthBits {
+		control.Length[bit] = circuit.OrAll(wires...)
+	}
+
+	control.RegisterOperands = make(Bus, len(registerBits))
+
+	for bit, wires := range registerBits {
+		control.RegisterOperands[bit] = circuit.OrAll(wires...)
+	}
+
+	var aluOpcodes []Wire
+	operationBitWires := make([][]Wire, operationBits)
+
+	for operation := Operation(0); operation < numberOfOperations; operation++ {
+		is := control.Is[operation.Opcode()]
+
+		aluOpcodes = append(aluOpcodes, is)
+
+		for bit := range operationBitWires {
+			if int(operation)>>uint(bit)&1 == 1 {
+				operationBitWires[bit] = append(operationBitWires[bit], is)
+			}
+		}
+	}
+
+	control.ALU = circuit.OrAll(aluOpcodes...)
+	control.ALUOperation = make(Bus, operationBits)
+
+	for bit, wires := range operationBitWires {
+		control.ALUOperation[bit] = circuit.OrAll(wires...)
+	}
+
+	is := func(mnemonics ...string) Wire {
+		var wires []Wire
+
+		for _, mnemonic := range mnemonics {
+			opcode, _ := processor.ParseOpcode(mnemonic)
+			wires = append(wires, control.Is[opcode])
+		}
+
+		return circuit.OrAll(wires...)
+	}
+
+	control.ReadsMainMemory = is("LDM")
+	control.WritesMainMemory = is("STR")
+	control.Jumps = is("JMP", "JMC", "JME")
+	control.Prints = is("PRN")
+	control.Halts = is("HLT")
+
+	return control
+}
+
+// Outputs of the control unit, for Statistics
+func (control *ControlUnit) Outputs() []Wire {
+	outputs := []Wire{
+		control.Known,
+		control.ALU,
+		control.ReadsMainMemory,
+		control.WritesMainMemory,
+		control.Jumps,
+		control.Prints,
+		control.Halts,
+	}
+
+	for _, bus := range []Bus{
+		control.Length,
+		control.ALUOperation,
+		control.RegisterOperands,
+	} {
+		outputs = append(outputs, bus...)
+	}
+
+	for _, wire := range control.Is {
+		outputs = append(outputs, wire)
+	}
+
+	return outputs
+}
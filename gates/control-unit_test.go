@@ -0,0 +1,98 @@
+package gates
+
+import (
+	"testing"
+
+	"github.com/tmornini/rigetti-computing/processor"
+)
+
+// TestControlUnit simulates the control unit for every opcode, comparing it
+// with the processor's tables
+func TestControlUnit(t *testing.T) {
+	control := NewControlUnit()
+	simulation := control.NewSimulation()
+
+	for opcode := 0; opcode < 256; opcode++ {
+		simulation.SetBus(control.Opcode, uint64(opcode))
+		simulation.Settle()
+
+		name := processor.OpcodeName(byte(opcode))
+		known := name != "???"
+
+		if simulation.Get(control.Known) != known {
+			t.Errorf("%02x %s: Known %t", opcode, name, !known)
+		}
+
+		length := 1
+		if known {
+			length = processor.InstructionLength(byte(opcode))
+		}
+
+		if got := int(simulation.GetBus(control.Length)); got != length {
+			t.Errorf("%02x %s: Length %d, want %d", opcode, name, got, length)
+		}
+
+		registers := 0
+
+		for _, kind := range processor.OpcodeOperands(byte(opcode)) {
+			if kind == 'r' {
+				registers++
+			}
+		}
+
+		got := int(simulation.GetBus(control.RegisterOperands))
+		if got != registers {
+			t.Errorf(
+				"%02x %s: RegisterOperands %d, want %d",
+				opcode, name, got, registers,
+			)
+		}
+
+		for decoded, wire := range control.Is {
+			if simulation.Get(wire) != (decoded == byte(opcode)) {
+				t.Errorf("%02x %s: Is[%02x] %t", opcode, name, decoded, !known)
+			}
+		}
+
+		isALU := false
+
+		for operation := Operation(0); operation < numberOfOperations; operation++ {
+			if operation.Opcode() != byte(opcode) {
+				continue
+			}
+
+			isALU = true
+
+			got := Operation(simulation.GetBus(control.ALUOperation))
+			if got != operation {
+				t.Errorf("%02x %s: ALUOperation %s", opcode, name, got)
+			}
+		}
+
+		if simulation.Get(control.ALU) != isALU {
+			t.Errorf("%02x %s: ALU %t", opcode, name, !isALU)
+		}
+
+		for _, signal := range []struct {
+			name      string
+			wire      Wire
+			mnemonics []string
+		}{
+			{"ReadsMainMemory", control.ReadsMainMemory, []string{"LDM"}},
+			{"WritesMainMemory", control.WritesMainMemory, []string{"STR"}},
+			{"Jumps", control.Jumps, []string{"JMP", "JMC", "JME"}},
+			{"Prints", control.Prints, []string{"PRN"}},
+			{"Halts", control.Halts, []string{"HLT"}},
+		} {
+			want := false
+
+			for _, mnemonic := range signal.mnemonics {
+				want = want || name == mnemonic
+			}
+
+			if simulation.Get(signal.wire) != want {
+				t.Errorf("%02x %s: %s %t", opcode, name, signal.name, !want)
+			}
+		}
+	}
+}
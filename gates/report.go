@@ -0,0 +1,103 @@
+package gates
+
+import (
+	"fmt"
+	"io"
+)
+
+// Component is a named part of the datapath, with the outputs its
+// Statistics are measured from
+type Component struct {
+	Name    string
+	Circuit *Circuit
+	Outputs []Wire
+}
+
+// Components of the ALU, each built on its own, then the ALU and control
+// unit as a whole
+func Components() []Component {
+	var components []Component
+
+	binary := func(
+		name string,
+		build func(circuit *Circuit, a, b Bus) []Wire,
+	) {
+		circuit := NewCircuit()
+		a, b := circuit.InputBus(8), circuit.InputBus(8)
+
+		components = append(components, Component{
+			Name:    name,
+			Circuit: circuit,
+			Outputs: build(circuit, a, b),
+		})
+	}
+
+	binary("adder", func(circuit *Circuit, a, b Bus) []Wire {
+		sum, _ := circuit.Add(a, b, circuit.Constant(false))
+
+		return sum
+	})
+	binary("subtractor", func(circuit *Circuit, a, b Bus) []Wire {
+		difference, _ := circuit.Subtract(a, b)
+
+		return difference
+	})
+	binary("multiplier", func(circuit *Circuit, a, b Bus) []Wire {
+		return circuit.Multiply(a, b)
+	})
+	binary("divider", func(circuit *Circuit, a, b Bus) []Wire {
+		quotient, _ := circuit.Divide(a, b)
+
+		return quotient
+	})
+	binary("comparator", func(circuit *Circuit, a, b Bus) []Wire {
+		return []Wire{circuit.Equal(a, b)}
+	})
+
+	alu := NewALU()
+	control := NewControlUnit()
+
+	return append(
+		components,
+		Component{Name: "ALU", Circuit: alu.Circuit, Outputs: alu.Outputs()},
+		Component{
+			Name:    "control unit",
+			Circuit: control.Circuit,
+			Outputs: control.Outputs(),
+		},
+	)
+}
+
+// WriteReport writes a table of the gates in each component, by kind, and
+// its depth
+func WriteReport(writer io.Writer, components []Component) error {
+	_, err := fmt.Fprintf(
+		writer,
+		"%-12s %6s %6s %6s %6s %6s %6s\n",
+		"component", Not, And, Or, Xor, "total", "depth",
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, component := range components {
+		statistics := component.Circuit.Statistics(component.Outputs...)
+
+		_, err = fmt.Fprintf(
+			writer,
+			"%-12s %6d %6d %6d %6d %6d %6d\n",
+			component.Name,
+			statistics.Gates[Not],
+			statistics.Gates[And],
+			statistics.Gates[Or],
+			statistics.Gates[Xor],
+			statistics.Total(),
+			statistics.Depth,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
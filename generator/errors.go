@@ -0,0 +1,45 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidOptions options that cannot generate a program
+var ErrInvalidOptions = errors.New("invalid generator options")
+
+// ErrProgramTooLong program that does not fit in program memory
+var ErrProgramTooLong = errors.New("generated program is longer than 256 bytes")
+
+// OptionsError identifies the invalid option
+type OptionsError struct {
+	Option string
+	Reason string
+}
+
+func (err *OptionsError) Error() string {
+	return fmt.Sprintf("%s: %s %s", ErrInvalidOptions, err.Option, err.Reason)
+}
+
+// Unwrap allows errors.Is(err, ErrInvalidOptions)
+func (err *OptionsError) Unwrap() error {
+	return ErrInvalidOptions
+}
+
+// TooLongError is how long the program would have been
+type TooLongError struct {
+	Length int
+}
+
+func (err *TooLongError) Error() string {
+	return fmt.Sprintf(
+		"%s: %d bytes, use fewer instructions or loops",
+		ErrProgramTooLong,
+		err.Length,
+	)
+}
+
+// Unwrap allows errors.Is(err, ErrProgramTooLong)
+func (err *TooLongError) Unwrap() error {
+	return ErrProgramTooLong
+}
@@ -0,0 +1,254 @@
+package generator
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+
+	"github.com/tmornini/rigetti-computing/memory"
+	"github.com/tmornini/rigetti-computing/processor"
+	"github.com/tmornini/rigetti-computing/workload"
+)
+
+// counters of nested loops are at #xF0 to #xFF, so STR only writes below
+const firstCounter = 0xf0
+
+var registerNames = []string{"X", "Y", "Z", "W"}
+
+// Program is generated assembly language and the bytes it assembles to
+type Program struct {
+	Options  Options
+	Assembly string // annotated with the loops and their counters
+	Bytes    []byte
+
+	// MaxSteps bounds the instructions executed up to and including HLT,
+	// whatever main memory is initialized to
+	MaxSteps uint64
+}
+
+// ProgramMemory holds the program, followed by zeros
+func (program *Program) ProgramMemory() *memory.ReadOnly {
+	programMemory := &memory.ReadOnly{}
+	copy(programMemory[:], program.Bytes)
+
+	return programMemory
+}
+
+// Workload of the program with main memory initialized to 0, for
+// benchmarking
+func (program *Program) Workload(name string) workload.Workload {
+	return workload.Workload{
+		Name:          name,
+		ProgramMemory: *program.ProgramMemory(),
+	}
+}
+
+type generator struct {
+	options Options
+	random  *rand.Rand
+
+	lines   []string
+	length  int // bytes, up to the line being generated
+	labels  int
+	loops   int
+	weights int // the total of the mix
+}
+
+// Generate a program that always halts, with valid register operands. Loops
+// count down a counter in main memory, from #xF0 for the outermost, which
+// is reloaded into W to decrement and test it, with Z holding its address.
+// STR writes only to constant addresses below #xF0 and other jumps only
+// skip forward within a run of instructions, so every loop ends
+func Generate(options Options) (*Program, error) {
+	err := options.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	g := &generator{
+		options: options,
+		random:  rand.New(rand.NewSource(options.Seed)),
+	}
+
+	for _, weight := range options.Mix {
+		g.weights += weight
+	}
+
+	steps := g.block(options.Instructions, options.Loops, 0)
+
+	g.instruction(processor.HLT, "")
+	steps++
+
+	if g.length > len(memory.ReadOnly{}) {
+		return nil, &TooLongError{Length: g.length}
+	}
+
+	header := []string{
+		"; rcc generate " + options.String(),
+		fmt.Sprintf("; halts within %d steps", steps),
+		"",
+	}
+
+	assembly := strings.Join(append(header, g.lines...), "\n") + "\n"
+
+	program, err := processor.Assemble(strings.NewReader(assembly))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Program{
+		Options:  options,
+		Assembly: assembly,
+		Bytes:    program,
+		MaxSteps: steps,
+	}, nil
+}
+
+// block generates instructions, with loops around some of them, returning
+// the most steps it can take
+func (g *generator) block(instructions int, loops int, depth int) uint64 {
+	if loops == 0 {
+		return g.run(instructions)
+	}
+
+	inner := 0
+	if depth+1 < g.options.MaxDepth {
+		inner = g.random.Intn(loops)
+	}
+
+	body := g.random.Intn(instructions + 1)
+	before := g.random.Intn(instructions - body + 1)
+
+	steps := g.run(before)
+	steps += g.loop(body, inner, depth)
+	steps += g.block(instructions-body-before, loops-1-inner, depth)
+
+	return steps
+}
+
+// loop generates a loop around a block, returning the most steps it can
+// take
+func (g *generator) loop(instructions int, loops int, depth int) uint64 {
+	g.loops++
+
+	label := fmt.Sprintf("LOOP%d", g.loops)
+	address := firstCounter + depth
+	counter := fmt.Sprintf("0x%02x", address)
+	iterations := 1 + g.random.Intn(g.options.MaxIterations)
+
+	g.blank()
+	g.comment(fmt.Sprintf("%s runs %d times, counting down [%02x]", label, iterations, address))
+	g.instruction(processor.LDI, "", fmt.Sprintf("0x%02x", iterations), "W")
+	g.instruction(processor.LDI, "", counter, "Z")
+	g.instruction(processor.STR, "", "W", "Z")
+	g.label(label)
+
+	steps := g.block(instructions, loops, depth+1)
+
+	g.instruction(processor.LDI, "decrement "+label+"'s counter", counter, "Z")
+	g.instruction(processor.LDM, "", "Z", "W")
+	g.instruction(processor.LDI, "", "0x01", "Z")
+	g.instruction(processor.SUB, "", "W", "Z", "W")
+	g.instruction(processor.LDI, "", counter, "Z")
+	g.instruction(processor.STR, "", "W", "Z")
+	g.instruction(processor.LDI, "", "0x00", "Z")
+	g.instruction(processor.NQL, "", "W", "Z")
+	g.instruction(processor.JMC, "until it is 0", label)
+	g.blank()
+
+	return 3 + uint64(iterations)*(steps+9)
+}
+
+// run generates instructions from the mix, returning the most steps they
+// can take. Jumps skip forward to the end of the run at most
+func (g *generator) run(instructions int) uint64 {
+	targets := map[int][]string{}
+	steps := uint64(0)
+
+	for index := 0; index < instructions; index++ {
+		for _, target := range targets[index] {
+			g.label(target)
+		}
+
+		opcode := g.opcode()
+		operands := make([]string, len(processor.OpcodeOperands(opcode)))
+
+		for position, kind := range processor.OpcodeOperands(opcode) {
+			if kind == 'r' {
+				operands[position] = g.register()
+			} else {
+				operands[position] = fmt.Sprintf("0x%02x", g.random.Intn(256))
+			}
+		}
+
+		switch opcode {
+		case processor.JMP, processor.JMC, processor.JME:
+			g.labels++
+			target := fmt.Sprintf("SKIP%d", g.labels)
+			to := index + 1 + g.random.Intn(instructions-index)
+			targets[to] = append(targets[to], target)
+
+			g.instruction(opcode, "", target)
+		case processor.STR:
+			address := fmt.Sprintf("0x%02x", g.random.Intn(firstCounter))
+
+			g.instruction(processor.LDI, "STR address, below the counters", address, operands[1])
+			steps++
+
+			g.instruction(opcode, "", operands...)
+		default:
+			g.instruction(opcode, "", operands...)
+		}
+
+		steps++
+	}
+
+	for _, target := range targets[instructions] {
+		g.label(target)
+	}
+
+	return steps
+}
+
+// opcode chosen at random, in proportion to the weights of the mix
+func (g *generator) opcode() byte {
+	choice := g.random.Intn(g.weights)
+	opcode := 0
+
+	for choice >= g.options.Mix[opcode] {
+		choice -= g.options.Mix[opcode]
+		opcode++
+	}
+
+	return byte(opcode)
+}
+
+func (g *generator) register() string {
+	return registerNames[g.random.Intn(len(registerNames))]
+}
+
+func (g *generator) instruction(opcode byte, comment string, operands ...string) {
+	line := "        " + strings.Join(append([]string{processor.OpcodeName(opcode)}, operands...), " ")
+
+	if comment != "" {
+		line = fmt.Sprintf("%-32s; %s", line, comment)
+	}
+
+	g.lines = append(g.lines, line)
+	g.length += processor.InstructionLength(opcode)
+}
+
+func (g *generator) label(label string) {
+	g.lines = append(g.lines, label+":")
+}
+
+func (g *generator) comment(comment string) {
+	g.lines = append(g.lines, "; "+comment)
+}
+
+// blank separates loops from the instructions around them
+func (g *generator) blank() {
+	if len(g.lines) > 0 && g.lines[len(g.lines)-1] != "" {
+		g.lines = append(g.lines, "")
+	}
+}
@@ -0,0 +1,224 @@
+package generator
+
+import (
+	"errors"
+	"io/ioutil"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/tmornini/rigetti-computing/analysis"
+	"github.com/tmornini/rigetti-computing/differential"
+	"github.com/tmornini/rigetti-computing/memory"
+	"github.com/tmornini/rigetti-computing/pipeline"
+	"github.com/tmornini/rigetti-computing/processor"
+)
+
+// TestReproducible generates the same program from the same options
+func TestReproducible(t *testing.T) {
+	first, err := Generate(DefaultOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	again, err := Generate(DefaultOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(first, again) {
+		t.Errorf("generated\n%s\nthen\n%s", first.Assembly, again.Assembly)
+	}
+
+	options := DefaultOptions
+	options.Seed++
+
+	other, err := Generate(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if reflect.DeepEqual(first.Bytes, other.Bytes) {
+		t.Errorf("seeds %d and %d generated the same program", DefaultOptions.Seed, options.Seed)
+	}
+}
+
+// TestGeneratedPrograms checks programs generated with random options on
+// random main memory
+func TestGeneratedPrograms(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	checked := 0
+
+	for seed := int64(1); seed <= 500; seed++ {
+		options := Options{
+			Seed:          seed,
+			Instructions:  random.Intn(40),
+			Mix:           DefaultMix,
+			Loops:         random.Intn(5),
+			MaxDepth:      1 + random.Intn(4),
+			MaxIterations: 1 + random.Intn(20),
+		}
+
+		var mainMemory memory.ReadWrite
+		random.Read(mainMemory[:])
+
+		program, err := Generate(options)
+		if errors.Is(err, ErrProgramTooLong) {
+			continue
+		}
+
+		if err != nil {
+			t.Fatalf("%s: %v", options, err)
+		}
+
+		checkProgram(t, program, &mainMemory)
+		checked++
+	}
+
+	if checked < 400 {
+		t.Errorf("only %d of 500 programs fit in program memory", checked)
+	}
+}
+
+// checkProgram runs the program on the processor, where it must halt within
+// MaxSteps, and in lockstep with the reference and the pipeline, with and
+// without forwarding. rcc check must find no errors
+func checkProgram(t *testing.T, program *Program, mainMemory *memory.ReadWrite) {
+	t.Helper()
+
+	programMemory := program.ProgramMemory()
+
+	configuration := processor.DefaultConfiguration
+	configuration.MaxSteps = program.MaxSteps
+	configuration.Output = ioutil.Discard
+	configuration.TraceOutput = ioutil.Discard
+
+	processorMainMemory := *mainMemory
+
+	p := processor.New(processor.NormalInstructionSet, configuration, programMemory, &processorMainMemory)
+
+	err := p.Run()
+	if err != nil {
+		t.Fatalf("%s: %v\n%s", program.Options, err, program.Assembly)
+	}
+
+	err = differential.Lockstep(processor.NormalInstructionSet, programMemory, mainMemory, program.MaxSteps)
+	if err != nil {
+		t.Fatalf("%s: %v\n%s", program.Options, err, program.Assembly)
+	}
+
+	for _, forwarding := range []bool{false, true} {
+		pipelineMainMemory := *mainMemory
+
+		p := processor.New(processor.NormalInstructionSet, configuration, programMemory, &pipelineMainMemory)
+
+		pipe, err := pipeline.New(p, pipeline.Options{Forwarding: forwarding, Lockstep: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = pipe.Run()
+		if err != nil {
+			t.Fatalf("%s, forwarding %t: %v\n%s", program.Options, forwarding, err, program.Assembly)
+		}
+	}
+
+	for _, finding := range analysis.Check(programMemory) {
+		if finding.Severity == analysis.Error {
+			t.Fatalf("%s: %s\n%s", program.Options, finding, program.Assembly)
+		}
+	}
+}
+
+// TestInvalidOptions are reported
+func TestInvalidOptions(t *testing.T) {
+	for _, options := range []Options{
+		{Instructions: -1, MaxDepth: 1, MaxIterations: 1},
+		{Instructions: 1, MaxDepth: 1, MaxIterations: 1},
+		{MaxDepth: 17, MaxIterations: 1},
+		{MaxDepth: 1, MaxIterations: 256},
+	} {
+		_, err := Generate(options)
+		if !errors.Is(err, ErrInvalidOptions) {
+			t.Errorf("%+v: got %v, want %v", options, err, ErrInvalidOptions)
+		}
+	}
+
+	options := DefaultOptions
+	options.Instructions = 200
+
+	_, err := Generate(options)
+
+	var tooLong *TooLongError
+	if !errors.As(err, &tooLong) {
+		t.Errorf("got %v, want a *TooLongError", err)
+	}
+}
+
+// FuzzGenerate checks programs generated from fuzzed options
+func FuzzGenerate(f *testing.F) {
+	f.Add(int64(1), uint8(24), uint8(2), uint8(2), uint8(10), []byte{})
+	f.Add(int64(7), uint8(16), uint8(3), uint8(3), uint8(4), []byte{0xff})
+
+	f.Fuzz(func(
+		t *testing.T,
+		seed int64,
+		instructions uint8,
+		loops uint8,
+		depth uint8,
+		iterations uint8,
+		mainMemoryData []byte,
+	) {
+		options := Options{
+			Seed:          seed,
+			Instructions:  int(instructions % 64),
+			Mix:           DefaultMix,
+			Loops:         int(loops % 8),
+			MaxDepth:      1 + int(depth%maxDepth),
+			MaxIterations: 1 + int(iterations%16),
+		}
+
+		program, err := Generate(options)
+		if errors.Is(err, ErrProgramTooLong) {
+			return
+		}
+
+		if err != nil {
+			t.Fatalf("%s: %v", options, err)
+		}
+
+		var mainMemory memory.ReadWrite
+		copy(mainMemory[:], mainMemoryData)
+
+		checkProgram(t, program, &mainMemory)
+	})
+}
+
+// BenchmarkGenerated runs the program rcc-bench runs as its generated
+// workload, with three nested loops
+func BenchmarkGenerated(b *testing.B) {
+	options := DefaultOptions
+	options.Loops = 3
+	options.MaxDepth = 3
+
+	program, err := Generate(options)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	configuration := processor.DefaultConfiguration
+	configuration.Output = ioutil.Discard
+
+	b.ReportAllocs()
+
+	for n := 0; n < b.N; n++ {
+		var mainMemory memory.ReadWrite
+
+		p := processor.New(processor.NormalInstructionSet, configuration, program.ProgramMemory(), &mainMemory)
+
+		err = p.Run()
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package generator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/tmornini/rigetti-computing/processor"
+)
+
+// Options shape the programs generated
+type Options struct {
+	// Seed makes the same options generate the same program
+	Seed int64
+
+	// Instructions chosen from Mix, not counting the code that runs loops
+	// or the final HLT
+	Instructions int
+
+	Mix Mix
+
+	// Loops placed around runs of the instructions, nested up to MaxDepth
+	// deep, each running between 1 and MaxIterations times
+	Loops         int
+	MaxDepth      int
+	MaxIterations int
+}
+
+// DefaultOptions generate programs of 24 instructions and 2 loops, which
+// usually fit in program memory
+var DefaultOptions = Options{
+	Seed:          1,
+	Instructions:  24,
+	Mix:           DefaultMix,
+	Loops:         2,
+	MaxDepth:      2,
+	MaxIterations: 10,
+}
+
+// maxDepth is the number of loop counters, at #xF0 to #xFF
+const maxDepth = 16
+
+// Mix is the relative weight of each opcode from NOP to PRN. HLT ends every
+// program, so it is not in the mix
+type Mix [processor.HLT]int
+
+// DefaultMix favors arithmetic and loads
+var DefaultMix = Mix{
+	1, // NOP
+	4, // ADD
+	4, // SUB
+	2, // MUL
+	2, // DIV
+	3, // LDM
+	4, // LDI
+	3, // STR
+	1, // SWP
+	2, // EQL
+	2, // NQL
+	1, // JMP
+	2, // JMC
+	1, // JME
+	1, // PRN
+}
+
+// ParseMix changes the weights of DefaultMix named by comma separated
+// mnemonic=weight pairs, e.g. PRN=0,MUL=8
+func ParseMix(text string) (Mix, error) {
+	mix := DefaultMix
+
+	for _, field := range strings.Split(text, ",") {
+		field = strings.TrimSpace(field)
+
+		pair := strings.SplitN(field, "=", 2)
+		if len(pair) != 2 {
+			return Mix{}, &OptionsError{Option: "mix", Reason: field + " is not mnemonic=weight"}
+		}
+
+		opcode, err := processor.ParseOpcode(pair[0])
+		if err != nil || opcode >= processor.HLT {
+			return Mix{}, &OptionsError{Option: "mix", Reason: pair[0] + " is not NOP to PRN"}
+		}
+
+		weight, err := strconv.Atoi(pair[1])
+		if err != nil || weight < 0 {
+			return Mix{}, &OptionsError{Option: "mix", Reason: pair[1] + " is not a weight"}
+		}
+
+		mix[opcode] = weight
+	}
+
+	return mix, nil
+}
+
+// String lists the weights that differ from DefaultMix, as ParseMix reads
+// them
+func (mix Mix) String() string {
+	var pairs []string
+
+	for opcode, weight := range mix {
+		if weight != DefaultMix[opcode] {
+			pairs = append(
+				pairs,
+				fmt.Sprintf("%s=%d", processor.OpcodeName(byte(opcode)), weight),
+			)
+		}
+	}
+
+	return strings.Join(pairs, ",")
+}
+
+func (options Options) validate() error {
+	total := 0
+
+	for _, weight := range options.Mix {
+		if weight < 0 {
+			return &OptionsError{Option: "mix", Reason: "weights must not be negative"}
+		}
+
+		total += weight
+	}
+
+	switch {
+	case options.Instructions < 0:
+		return &OptionsError{Option: "instructions", Reason: "must not be negative"}
+	case options.Instructions > 0 && total == 0:
+		return &OptionsError{Option: "mix", Reason: "some weight must be positive"}
+	case options.Loops < 0:
+		return &OptionsError{Option: "loops", Reason: "must not be negative"}
+	case options.MaxDepth < 1 || options.MaxDepth > maxDepth:
+		return &OptionsError{Option: "depth", Reason: "must be 1 to 16"}
+	case options.MaxIterations < 1 || options.MaxIterations > 255:
+		return &OptionsError{Option: "iterations", Reason: "must be 1 to 255"}
+	}
+
+	return nil
+}
+
+// String is the rcc generate flags for the options
+func (options Options) String() string {
+	text := fmt.Sprintf(
+		"-seed %d -instructions %d -loops %d -depth %d -iterations %d",
+		options.Seed,
+		options.Instructions,
+		options.Loops,
+		options.MaxDepth,
+		options.MaxIterations,
+	)
+
+	if mix := options.Mix.String(); mix != "" {
+		text += " -mix " + mix
+	}
+
+	return text
+}
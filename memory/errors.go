@@ -1,9 +1,105 @@
 package memory
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 // ErrIllegalMemoryAccess illegal memory access
 var ErrIllegalMemoryAccess = errors.New("illegal memory access")
 
 // ErrInvalidProgramLength invalid program length
 var ErrInvalidProgramLength = errors.New("invalid program length")
+
+// ErrInvalidMainMemoryLength invalid main memory length
+var ErrInvalidMainMemoryLength = errors.New("invalid main memory length")
+
+// ErrInvalidHexDump invalid hex dump
+var ErrInvalidHexDump = errors.New("invalid hex dump")
+
+// ErrInvalidIntelHex invalid Intel HEX
+var ErrInvalidIntelHex = errors.New("invalid Intel HEX")
+
+// ErrInvalidSRecord invalid Motorola S-record
+var ErrInvalidSRecord = errors.New("invalid S-record")
+
+// LineError describes a malformed line of a text file: a memory image, or
+// assembly language, a timing file or a coverage file read by package
+// processor or coverage
+type LineError struct {
+	// ErrInvalidHexDump, ErrInvalidIntelHex, ErrInvalidSRecord,
+	// processor.ErrInvalidAssembly, processor.ErrInvalidTiming or
+	// coverage.ErrInvalidCoverage
+	Err    error
+	Line   int // 1 is the first line
+	Reason string
+}
+
+func (err *LineError) Error() string {
+	return fmt.Sprintf("%s: line %d: %s", err.Err, err.Line, err.Reason)
+}
+
+// Unwrap allows errors.Is(err, ErrInvalidHexDump) etc.
+func (err *LineError) Unwrap() error {
+	return err.Err
+}
+
+// AccessError describes an out of bounds read or write
+type AccessError struct {
+	Operation string // read or write
+	Memory    string // read-only or read/write
+	Address   Address
+	Length    int
+}
+
+func (err *AccessError) Error() string {
+	return fmt.Sprintf(
+		"%s: out of bounds %s on %s memory: %x-%x",
+		ErrIllegalMemoryAccess,
+		err.Operation,
+		err.Memory,
+		err.Address,
+		int(err.Address)+err.Length,
+	)
+}
+
+// Unwrap allows errors.Is(err, ErrIllegalMemoryAccess)
+func (err *AccessError) Unwrap() error {
+	return ErrIllegalMemoryAccess
+}
+
+// ProgramLengthError describes a program that does not fit program memory
+type ProgramLengthError struct {
+	Length int
+}
+
+func (err *ProgramLengthError) Error() string {
+	return fmt.Sprintf(
+		"%s, must be 1-256 bytes, is: %d",
+		ErrInvalidProgramLength,
+		err.Length,
+	)
+}
+
+// Unwrap allows errors.Is(err, ErrInvalidProgramLength)
+func (err *ProgramLengthError) Unwrap() error {
+	return ErrInvalidProgramLength
+}
+
+// MainMemoryLengthError describes an image that does not fit main memory
+type MainMemoryLengthError struct {
+	Length int
+}
+
+func (err *MainMemoryLengthError) Error() string {
+	return fmt.Sprintf(
+		"%s, must be 0-256 bytes, is: %d",
+		ErrInvalidMainMemoryLength,
+		err.Length,
+	)
+}
+
+// Unwrap allows errors.Is(err, ErrInvalidMainMemoryLength)
+func (err *MainMemoryLengthError) Unwrap() error {
+	return ErrInvalidMainMemoryLength
+}
@@ -0,0 +1,98 @@
+package memory
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// addSpecFixtures seeds the corpus with every file of the spec fixtures, and
+// the program each image describes
+func addSpecFixtures(f *testing.F) {
+	files, err := filepath.Glob(filepath.Join("..", "spec-*", "*"))
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	for _, file := range files {
+		contents, err := ioutil.ReadFile(file)
+		if err != nil {
+			f.Fatal(err)
+		}
+
+		f.Add(contents)
+
+		image, err := ReadImage(bytes.NewReader(contents))
+		if err == nil {
+			f.Add(image)
+		}
+	}
+}
+
+// isSentinel is whether err is one of the documented errors
+func isSentinel(err error, sentinels ...error) bool {
+	for _, sentinel := range sentinels {
+		if errors.Is(err, sentinel) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func FuzzNewProgramFrom(f *testing.F) {
+	addSpecFixtures(f)
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		programMemory, err := NewProgramFrom(bytes.NewReader(data))
+
+		again, againErr := NewProgramFrom(bytes.NewReader(data))
+		if !reflect.DeepEqual(programMemory, again) || !reflect.DeepEqual(err, againErr) {
+			t.Fatalf("not deterministic: %v then %v", err, againErr)
+		}
+
+		if err != nil {
+			if !isSentinel(err, ErrInvalidProgramLength) || len(data) >= 1 && len(data) <= 256 {
+				t.Fatalf("%d bytes: %v", len(data), err)
+			}
+
+			return
+		}
+
+		if !bytes.Equal(programMemory[:len(data)], data) {
+			t.Fatalf("program %x is not %x", programMemory[:len(data)], data)
+		}
+
+		for _, b := range programMemory[len(data):] {
+			if b != 0 {
+				t.Fatalf("program %x is not padded with 0", programMemory)
+			}
+		}
+	})
+}
+
+func FuzzNewProgramFromImage(f *testing.F) {
+	addSpecFixtures(f)
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		programMemory, err := NewProgramFromImage(bytes.NewReader(data))
+
+		again, againErr := NewProgramFromImage(bytes.NewReader(data))
+		if !reflect.DeepEqual(programMemory, again) || !reflect.DeepEqual(err, againErr) {
+			t.Fatalf("not deterministic: %v then %v", err, againErr)
+		}
+
+		if err != nil && !isSentinel(
+			err,
+			ErrInvalidProgramLength,
+			ErrInvalidHexDump,
+			ErrInvalidIntelHex,
+			ErrInvalidSRecord,
+		) {
+			t.Fatalf("undocumented error: %v", err)
+		}
+	})
+}
@@ -0,0 +1,188 @@
+package memory
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// hex dumps are written as xxd writes them, one line per 16 bytes:
+//
+//	00000000: 0600 0006 0103 0648 0107 0100 0100 0300  LDI 0x00 X, ...
+//
+// an offset and colon, up to 16 bytes of hex in space separated groups and,
+// after two spaces, free-text that is usually annotated assembly
+const hexDumpBytesPerLine = 16
+
+// IsHexDump reports whether data begins like a hex dump rather than binary
+func IsHexDump(data []byte) bool {
+	colon := bytes.IndexByte(data, ':')
+	if colon < 1 || colon > 16 {
+		return false
+	}
+
+	for _, digit := range data[:colon] {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", rune(digit)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ReadHexDump converts a hex dump to the bytes it describes. Gaps between
+// lines are filled with zeros
+func ReadHexDump(reader io.Reader) ([]byte, error) {
+	var data []byte
+
+	scanner := bufio.NewScanner(reader)
+	lineNumber := 0
+
+	for scanner.Scan() {
+		lineNumber++
+
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		offset, lineBytes, reason := parseHexDumpLine(line)
+		if reason != "" {
+			return nil, &LineError{
+				Err:    ErrInvalidHexDump,
+				Line:   lineNumber,
+				Reason: reason,
+			}
+		}
+
+		if offset < len(data) {
+			return nil, &LineError{
+				Err:  ErrInvalidHexDump,
+				Line: lineNumber,
+				Reason: fmt.Sprintf(
+					"offset %x overlaps the previous line, which ends at %x",
+					offset,
+					len(data),
+				),
+			}
+		}
+
+		data = append(data, make([]byte, offset-len(data))...)
+		data = append(data, lineBytes...)
+	}
+
+	err := scanner.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func parseHexDumpLine(line string) (offset int, lineBytes []byte, reason string) {
+	colon := strings.Index(line, ":")
+	if colon < 0 {
+		return 0, nil, "missing offset, expected e.g. 00000010:"
+	}
+
+	parsedOffset, err := strconv.ParseUint(line[:colon], 16, 16)
+	if err != nil {
+		return 0, nil, fmt.Sprintf("invalid offset %q", line[:colon])
+	}
+
+	// however far the bytes are indented, the free-text follows two spaces
+	hexColumn := strings.TrimLeft(line[colon+1:], " \t")
+	hexColumn = strings.SplitN(hexColumn, "  ", 2)[0]
+
+	for _, group := range strings.Fields(hexColumn) {
+		groupBytes, err := hex.DecodeString(group)
+		if err != nil {
+			return 0, nil, fmt.Sprintf("invalid hex %q", group)
+		}
+
+		lineBytes = append(lineBytes, groupBytes...)
+	}
+
+	if len(lineBytes) == 0 {
+		return 0, nil, "offset without bytes"
+	}
+
+	if len(lineBytes) > hexDumpBytesPerLine {
+		return 0, nil, fmt.Sprintf(
+			"%d bytes, at most %d are allowed per line",
+			len(lineBytes),
+			hexDumpBytesPerLine,
+		)
+	}
+
+	return int(parsedOffset), lineBytes, ""
+}
+
+// WriteHexDump writes data as xxd does, with each line's bytes as ASCII in
+// place of annotated assembly, so ReadHexDump reads it back
+func WriteHexDump(writer io.Writer, data []byte) error {
+	for offset := 0; offset < len(data); offset += hexDumpBytesPerLine {
+		end := offset + hexDumpBytesPerLine
+		if end > len(data) {
+			end = len(data)
+		}
+
+		_, err := fmt.Fprintf(
+			writer,
+			"%08x: %-39s  %s\n",
+			offset,
+			hexDumpGroups(data[offset:end]),
+			hexDumpASCII(data[offset:end]),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// hexDumpGroups formats bytes in space separated groups of two
+func hexDumpGroups(lineBytes []byte) string {
+	var groups []string
+
+	for index := 0; index < len(lineBytes); index += 2 {
+		end := index + 2
+		if end > len(lineBytes) {
+			end = len(lineBytes)
+		}
+
+		groups = append(groups, hex.EncodeToString(lineBytes[index:end]))
+	}
+
+	return strings.Join(groups, " ")
+}
+
+// hexDumpASCII shows printable bytes as themselves and the rest as .
+func hexDumpASCII(lineBytes []byte) string {
+	ascii := make([]byte, len(lineBytes))
+
+	for index, value := range lineBytes {
+		if value >= ' ' && value <= '~' {
+			ascii[index] = value
+		} else {
+			ascii[index] = '.'
+		}
+	}
+
+	return string(ascii)
+}
+
+// NewProgramFromHexDump creates a new read-only memory from a hex dump
+func NewProgramFromHexDump(hexDumpReader io.Reader) (*ReadOnly, error) {
+	programBytes, err := ReadHexDump(hexDumpReader)
+	if err != nil {
+		return nil, err
+	}
+
+	return newProgramFromBytes(programBytes)
+}
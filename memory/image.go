@@ -0,0 +1,130 @@
+package memory
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+// Format is how a memory image is encoded
+type Format int
+
+const (
+	// Binary raw bytes, as in rcc file.bin
+	Binary Format = iota
+
+	// HexDump xxd format, as in spec-*/*.hex
+	HexDump
+
+	// IntelHex Intel HEX records
+	IntelHex
+
+	// SRecord Motorola S-records
+	SRecord
+)
+
+var formatNames = [...]string{"binary", "hex dump", "Intel HEX", "S-record"}
+
+func (format Format) String() string {
+	if format < 0 || int(format) >= len(formatNames) {
+		return "???"
+	}
+
+	return formatNames[format]
+}
+
+// DetectFormat guesses the format of an image from its first bytes. Opcodes
+// above #x0F are unknown, so real programs never begin with : or S
+func DetectFormat(image []byte) Format {
+	switch {
+	case IsHexDump(image):
+		return HexDump
+	case len(image) > 0 && image[0] == ':':
+		return IntelHex
+	case len(image) > 1 && image[0] == 'S' && image[1] >= '0' && image[1] <= '9':
+		return SRecord
+	default:
+		return Binary
+	}
+}
+
+// ReadImage reads an image in any Format, returning the bytes it describes
+func ReadImage(reader io.Reader) ([]byte, error) {
+	image, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("error reading image: %w", err)
+	}
+
+	switch DetectFormat(image) {
+	case HexDump:
+		return ReadHexDump(bytes.NewReader(image))
+	case IntelHex:
+		return ReadIntelHex(bytes.NewReader(image))
+	case SRecord:
+		return ReadSRecord(bytes.NewReader(image))
+	default:
+		return image, nil
+	}
+}
+
+// NewProgramFromImage creates a new read-only memory from an image in any
+// Format
+func NewProgramFromImage(imageReader io.Reader) (*ReadOnly, error) {
+	programBytes, err := ReadImage(imageReader)
+	if err != nil {
+		return nil, err
+	}
+
+	return newProgramFromBytes(programBytes)
+}
+
+// NewMainMemoryFromImage creates a new read/write memory from an image in
+// any Format. Bytes beyond the end of the image are zero
+func NewMainMemoryFromImage(imageReader io.Reader) (*ReadWrite, error) {
+	mainMemoryBytes, err := ReadImage(imageReader)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(mainMemoryBytes) > 256 {
+		return nil, &MainMemoryLengthError{Length: len(mainMemoryBytes)}
+	}
+
+	mainMemory := &ReadWrite{}
+
+	copy(mainMemory[:], mainMemoryBytes)
+
+	return mainMemory, nil
+}
+
+// sparseImage collects the data records of Intel HEX and S-record files,
+// which may arrive in any order
+type sparseImage struct {
+	data   [256]byte
+	length int
+}
+
+func (image *sparseImage) write(address int, data []byte) error {
+	end := address + len(data)
+
+	if end > len(image.data) {
+		return fmt.Errorf(
+			"address %x-%x is beyond the end of memory at ff",
+			address,
+			end-1,
+		)
+	}
+
+	copy(image.data[address:end], data)
+
+	if end > image.length {
+		image.length = end
+	}
+
+	return nil
+}
+
+func (image *sparseImage) bytes() []byte {
+	return image.data[:image.length]
+}
@@ -0,0 +1,160 @@
+package memory
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Intel HEX records are written as :LLAAAATT followed by LL data bytes and a
+// checksum byte, which makes the sum of all bytes in the record zero
+const (
+	intelHexData                   = 0x00
+	intelHexEndOfFile              = 0x01
+	intelHexExtendedSegmentAddress = 0x02
+	intelHexStartSegmentAddress    = 0x03
+	intelHexExtendedLinearAddress  = 0x04
+	intelHexStartLinearAddress     = 0x05
+
+	intelHexBytesPerRecord = 16
+)
+
+// ReadIntelHex converts Intel HEX records to the bytes they describe. Gaps
+// between records are filled with zeros
+func ReadIntelHex(reader io.Reader) ([]byte, error) {
+	image := &sparseImage{}
+
+	scanner := bufio.NewScanner(reader)
+	lineNumber := 0
+	baseAddress := 0
+
+	for scanner.Scan() {
+		lineNumber++
+
+		lineError := func(format string, arguments ...interface{}) error {
+			return &LineError{
+				Err:    ErrInvalidIntelHex,
+				Line:   lineNumber,
+				Reason: fmt.Sprintf(format, arguments...),
+			}
+		}
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if line[0] != ':' {
+			return nil, lineError("record does not start with a colon")
+		}
+
+		record, err := hex.DecodeString(line[1:])
+		if err != nil {
+			return nil, lineError("invalid hex")
+		}
+
+		if len(record) < 5 || len(record) != 5+int(record[0]) {
+			return nil, lineError("record length does not match its byte count")
+		}
+
+		if checksum(record) != 0 {
+			return nil, lineError(
+				"checksum is %02x, expected %02x",
+				record[len(record)-1],
+				byte(record[len(record)-1]-checksum(record)),
+			)
+		}
+
+		address := int(record[1])<<8 | int(record[2])
+		recordType := record[3]
+		data := record[4 : len(record)-1]
+
+		switch recordType {
+		case intelHexData:
+			err = image.write(baseAddress+address, data)
+			if err != nil {
+				return nil, lineError("%s", err)
+			}
+		case intelHexEndOfFile:
+			return image.bytes(), nil
+		case intelHexExtendedSegmentAddress, intelHexExtendedLinearAddress:
+			if len(data) != 2 {
+				return nil, lineError("address record must have 2 data bytes")
+			}
+
+			baseAddress = int(data[0])<<8 | int(data[1])
+
+			if recordType == intelHexExtendedSegmentAddress {
+				baseAddress <<= 4
+			} else {
+				baseAddress <<= 16
+			}
+		case intelHexStartSegmentAddress, intelHexStartLinearAddress:
+			// execution always starts at #x00
+		default:
+			return nil, lineError("unknown record type %02x", recordType)
+		}
+	}
+
+	err := scanner.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, &LineError{
+		Err:    ErrInvalidIntelHex,
+		Line:   lineNumber,
+		Reason: "missing end of file record",
+	}
+}
+
+// WriteIntelHex writes data as Intel HEX data records and an end of file
+// record
+func WriteIntelHex(writer io.Writer, data []byte) error {
+	for address := 0; address < len(data); address += intelHexBytesPerRecord {
+		end := address + intelHexBytesPerRecord
+		if end > len(data) {
+			end = len(data)
+		}
+
+		err := writeIntelHexRecord(
+			writer,
+			address,
+			intelHexData,
+			data[address:end],
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return writeIntelHexRecord(writer, 0, intelHexEndOfFile, nil)
+}
+
+func writeIntelHexRecord(
+	writer io.Writer,
+	address int,
+	recordType byte,
+	data []byte,
+) error {
+	record := []byte{byte(len(data)), byte(address >> 8), byte(address), recordType}
+	record = append(record, data...)
+	record = append(record, -checksum(record))
+
+	_, err := fmt.Fprintf(writer, ":%X\n", record)
+
+	return err
+}
+
+// checksum is the low byte of the sum of bytes
+func checksum(bytes []byte) byte {
+	sum := byte(0)
+
+	for _, value := range bytes {
+		sum += value
+	}
+
+	return sum
+}
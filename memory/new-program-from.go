@@ -4,32 +4,28 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
-	"os"
 )
 
 // NewProgramFrom creates a new read-only memory from a reader
 func NewProgramFrom(programReader io.Reader) (*ReadOnly, error) {
 	programBytes, err := ioutil.ReadAll(programReader)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "error reading code: %s\n", err)
-		return nil, err
+		return nil, fmt.Errorf("error reading code: %w", err)
 	}
 
+	return newProgramFromBytes(programBytes)
+}
+
+func newProgramFromBytes(programBytes []byte) (*ReadOnly, error) {
 	length := len(programBytes)
 
 	if length < 1 || length > 256 {
-		fmt.Fprintf(
-			os.Stderr,
-			"invalid program length, must be 1-256 bytes, is: %d\n",
-			length,
-		)
-
-		return nil, ErrInvalidProgramLength
+		return nil, &ProgramLengthError{Length: length}
 	}
 
 	programMemory := &ReadOnly{}
 
-	copy(programMemory[:], programBytes[0:256])
+	copy(programMemory[:], programBytes)
 
 	return programMemory, nil
 }
@@ -1,10 +1,5 @@
 package memory
 
-import (
-	"fmt"
-	"os"
-)
-
 // ReadOnly is memory that can be read from
 type ReadOnly [256]byte
 
@@ -17,13 +12,12 @@ func (
 	end := int(address) + length
 
 	if end > 256 {
-		fmt.Fprintf(
-			os.Stderr,
-			"out of bounds memory read on read-only memory: %x-%x\n",
-			address,
-			end,
-		)
-		return nil, ErrIllegalMemoryAccess
+		return nil, &AccessError{
+			Operation: "read",
+			Memory:    "read-only",
+			Address:   address,
+			Length:    length,
+		}
 	}
 
 	return readOnly[address:end], nil
@@ -1,10 +1,5 @@
 package memory
 
-import (
-	"fmt"
-	"os"
-)
-
 // ReadWrite is memory that can be read from and written to
 type ReadWrite [256]byte
 
@@ -17,13 +12,12 @@ func (
 	end := int(address) + length
 
 	if address < 0 || end > 256 {
-		fmt.Fprintf(
-			os.Stderr,
-			"out of bounds read on read/write memory: %x-%x\n",
-			address,
-			int(end),
-		)
-		return nil, ErrIllegalMemoryAccess
+		return nil, &AccessError{
+			Operation: "read",
+			Memory:    "read/write",
+			Address:   address,
+			Length:    length,
+		}
 	}
 
 	return readWrite[address:end], nil
@@ -34,13 +28,12 @@ func (readWrite *ReadWrite) Write(address Address, bytes []byte) error {
 	end := int(address) + length
 
 	if address < 0 || end > 256 {
-		fmt.Fprintf(
-			os.Stderr,
-			"out of bounds write on read/write memory: %x-%x\n",
-			address,
-			end,
-		)
-		return ErrIllegalMemoryAccess
+		return &AccessError{
+			Operation: "write",
+			Memory:    "read/write",
+			Address:   address,
+			Length:    length,
+		}
 	}
 
 	for index, value := range bytes {
@@ -0,0 +1,175 @@
+package memory
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Motorola S-records are written as S, a type digit, a byte count, an
+// address, data and a checksum byte, which is the ones' complement of the
+// sum of the count, address and data bytes
+const sRecordBytesPerRecord = 16
+
+// address length by S-record type, zero for types without data
+var sRecordAddressLengths = map[byte]int{
+	'0': 2, // header
+	'1': 2, // data
+	'2': 3, // data
+	'3': 4, // data
+	'5': 2, // record count
+	'6': 3, // record count
+	'7': 4, // termination
+	'8': 3, // termination
+	'9': 2, // termination
+}
+
+// ReadSRecord converts Motorola S-records to the bytes they describe. Gaps
+// between records are filled with zeros. An S7, S8 or S9 termination record
+// must end the data
+func ReadSRecord(reader io.Reader) ([]byte, error) {
+	image := &sparseImage{}
+
+	scanner := bufio.NewScanner(reader)
+	lineNumber := 0
+	dataRecords := 0
+
+	for scanner.Scan() {
+		lineNumber++
+
+		lineError := func(format string, arguments ...interface{}) error {
+			return &LineError{
+				Err:    ErrInvalidSRecord,
+				Line:   lineNumber,
+				Reason: fmt.Sprintf(format, arguments...),
+			}
+		}
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if len(line) < 2 || line[0] != 'S' {
+			return nil, lineError("record does not start with S")
+		}
+
+		recordType := line[1]
+
+		addressLength, ok := sRecordAddressLengths[recordType]
+		if !ok {
+			return nil, lineError("unknown record type S%c", recordType)
+		}
+
+		record, err := hex.DecodeString(line[2:])
+		if err != nil {
+			return nil, lineError("invalid hex")
+		}
+
+		if len(record) < 2+addressLength || len(record) != 1+int(record[0]) {
+			return nil, lineError("record length does not match its byte count")
+		}
+
+		if checksum(record) != 0xff {
+			return nil, lineError(
+				"checksum is %02x, expected %02x",
+				record[len(record)-1],
+				^checksum(record[:len(record)-1]),
+			)
+		}
+
+		address := 0
+		for _, addressByte := range record[1 : 1+addressLength] {
+			address = address<<8 | int(addressByte)
+		}
+
+		data := record[1+addressLength : len(record)-1]
+
+		switch recordType {
+		case '1', '2', '3':
+			dataRecords++
+
+			err = image.write(address, data)
+			if err != nil {
+				return nil, lineError("%s", err)
+			}
+		case '5', '6':
+			if address != dataRecords {
+				return nil, lineError(
+					"record count is %d, expected %d",
+					address,
+					dataRecords,
+				)
+			}
+		case '7', '8', '9':
+			return image.bytes(), nil
+		}
+	}
+
+	err := scanner.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, &LineError{
+		Err:    ErrInvalidSRecord,
+		Line:   lineNumber,
+		Reason: "missing termination record",
+	}
+}
+
+// WriteSRecord writes data as an S0 header, S1 data records, an S5 record
+// count and an S9 termination record
+func WriteSRecord(writer io.Writer, data []byte) error {
+	err := writeSRecord(writer, '0', 0, []byte("rcc"))
+	if err != nil {
+		return err
+	}
+
+	records := 0
+
+	for address := 0; address < len(data); address += sRecordBytesPerRecord {
+		end := address + sRecordBytesPerRecord
+		if end > len(data) {
+			end = len(data)
+		}
+
+		err = writeSRecord(writer, '1', address, data[address:end])
+		if err != nil {
+			return err
+		}
+
+		records++
+	}
+
+	err = writeSRecord(writer, '5', records, nil)
+	if err != nil {
+		return err
+	}
+
+	return writeSRecord(writer, '9', 0, nil)
+}
+
+func writeSRecord(
+	writer io.Writer,
+	recordType byte,
+	address int,
+	data []byte,
+) error {
+	addressLength := sRecordAddressLengths[recordType]
+
+	record := []byte{byte(addressLength + len(data) + 1)}
+
+	for shift := 8 * (addressLength - 1); shift >= 0; shift -= 8 {
+		record = append(record, byte(address>>uint(shift)))
+	}
+
+	record = append(record, data...)
+	record = append(record, ^checksum(record))
+
+	_, err := fmt.Fprintf(writer, "S%c%X\n", recordType, record)
+
+	return err
+}
@@ -0,0 +1,33 @@
+package pipeline
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrMismatch pipeline and processor differ
+var ErrMismatch = errors.New("pipeline and processor differ")
+
+// MismatchError identifies the first state Lockstep found different
+type MismatchError struct {
+	Step      uint64 // the instruction retired before they differed
+	State     string // e.g. X, main memory or output
+	Pipeline  string
+	Processor string
+}
+
+func (err *MismatchError) Error() string {
+	return fmt.Sprintf(
+		"%s after step %d: %s is %s, processor's is %s",
+		ErrMismatch,
+		err.Step,
+		err.State,
+		err.Pipeline,
+		err.Processor,
+	)
+}
+
+// Unwrap allows errors.Is(err, ErrMismatch)
+func (err *MismatchError) Unwrap() error {
+	return ErrMismatch
+}
@@ -0,0 +1,344 @@
+package pipeline
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/tmornini/rigetti-computing/memory"
+	"github.com/tmornini/rigetti-computing/processor"
+)
+
+// execute the instruction in execute, as processor.Processor would. Every
+// instruction before it has retired, so the registers and flags it reads
+// are architectural, as forwarding or stalling in decode guarantees
+func (pipeline *Pipeline) execute() {
+	s := pipeline.stages[execute]
+	if s == nil || s.bubble {
+		return
+	}
+
+	maxSteps := pipeline.configuration.MaxSteps
+
+	if maxSteps != 0 && pipeline.steps >= maxSteps {
+		pipeline.stop()
+
+		pipeline.done = true
+		pipeline.err = &processor.StepLimitError{Steps: pipeline.steps}
+
+		return
+	}
+
+	s.next = s.address
+
+	if s.fetchErr != nil {
+		programCounterAdvance, ok := pipeline.fault(s, s.fetchErr, s.length)
+		if ok {
+			pipeline.advanceProgramCounter(s, programCounterAdvance)
+		}
+	} else {
+		programCounterAdvance, err := pipeline.compute(s)
+
+		switch {
+		case errors.Is(err, processor.ErrHLTExecuted):
+			s.halted = true
+		case err != nil:
+			programCounterAdvance, ok := pipeline.fault(s, err, programCounterAdvance)
+			if ok {
+				pipeline.advanceProgramCounter(s, programCounterAdvance)
+			}
+		default:
+			pipeline.advanceProgramCounter(s, programCounterAdvance)
+		}
+	}
+
+	if s.halted || s.err != nil {
+		pipeline.stop()
+
+		return
+	}
+
+	if s.next != pipeline.expectedAddress() {
+		pipeline.flush(redirectReason(s), s.next)
+	}
+}
+
+// compute an instruction's results, returning how far to advance the PC,
+// as processor.InstructionSet functions do. Only STR's write to main memory
+// takes effect before writeback
+func (pipeline *Pipeline) compute(s *slot) (programCounterAdvance int, err error) {
+	registers := &pipeline.registers
+
+	switch s.opcode {
+	case processor.NOP:
+		return 1, nil
+	case processor.ADD, processor.SUB, processor.MUL, processor.DIV:
+		err = unknownRegisterError(s.r1, s.r2, s.r3)
+		if err != nil {
+			return 4, err
+		}
+
+		a, b := registers[s.r1], registers[s.r2]
+
+		var result byte
+
+		switch s.opcode {
+		case processor.ADD:
+			result = a + b
+		case processor.SUB:
+			result = a - b
+		case processor.MUL:
+			result = a * b
+		default:
+			if b == 0 {
+				return 4, processor.ErrDivideByZero
+			}
+
+			result = a / b
+		}
+
+		s.write(s.r3, result)
+
+		return 4, nil
+	case processor.LDM:
+		err = unknownRegisterError(s.r1, s.r2)
+		if err != nil {
+			return 3, err
+		}
+
+		bytes, err := pipeline.mainMemory.Read(memory.Address(registers[s.r1]), 1)
+		if err != nil {
+			return 3, err
+		}
+
+		s.write(s.r2, bytes[0])
+
+		return 3, nil
+	case processor.LDI:
+		err = unknownRegisterError(s.r1)
+		if err != nil {
+			return 3, err
+		}
+
+		s.write(s.r1, s.imm)
+
+		return 3, nil
+	case processor.STR:
+		err = unknownRegisterError(s.r1, s.r2)
+		if err != nil {
+			return 3, err
+		}
+
+		return 3, pipeline.mainMemory.Write(
+			memory.Address(registers[s.r2]),
+			[]byte{registers[s.r1]},
+		)
+	case processor.SWP:
+		err = unknownRegisterError(s.r1, s.r2)
+		if err != nil {
+			return 3, err
+		}
+
+		s.write(s.r1, registers[s.r2])
+		s.write(s.r2, registers[s.r1])
+
+		return 3, nil
+	case processor.EQL, processor.NQL:
+		err = unknownRegisterError(s.r1, s.r2)
+		if err != nil {
+			return 3, err
+		}
+
+		s.writesC = true
+		s.c = (registers[s.r1] == registers[s.r2]) == (s.opcode == processor.EQL)
+
+		return 3, nil
+	case processor.JMP:
+		s.next = memory.Address(s.imm)
+
+		return 0, nil
+	case processor.JMC:
+		if pipeline.c {
+			s.next = memory.Address(s.imm)
+			s.writesC, s.c = true, false
+
+			return 0, nil
+		}
+
+		return 2, nil
+	case processor.JME:
+		if pipeline.e {
+			s.next = memory.Address(s.imm)
+			s.writesE, s.e = true, false
+
+			return 0, nil
+		}
+
+		return 2, nil
+	case processor.PRN:
+		err = unknownRegisterError(s.r1)
+		if err != nil {
+			return 2, err
+		}
+
+		s.prints = true
+		s.character = registers[s.r1]
+
+		return 2, nil
+	case processor.HLT:
+		return 1, processor.ErrHLTExecuted
+	default:
+		return 1, processor.ErrUnknownOpcode
+	}
+}
+
+func (s *slot) write(register byte, value byte) {
+	s.registerWrites = append(
+		s.registerWrites,
+		registerWrite{register: register, value: value},
+	)
+}
+
+// fault sets E and applies the fault policy for err, returning how far to
+// advance the PC and whether execution continues
+func (pipeline *Pipeline) fault(
+	s *slot,
+	err error,
+	programCounterAdvance int,
+) (int, bool) {
+	s.writesE, s.e = true, true
+
+	class, ok := processor.FaultClassOf(err)
+	if !ok {
+		s.err = pipeline.executionError(s, err)
+
+		return 0, false
+	}
+
+	switch pipeline.configuration.FaultPolicies[class] {
+	case processor.SetE:
+		return programCounterAdvance, true
+	case processor.Trap:
+		s.next = pipeline.configuration.FaultHandler
+		s.trapped = true
+
+		return 0, true
+	default:
+		s.err = pipeline.executionError(s, err)
+
+		return 0, false
+	}
+}
+
+func (pipeline *Pipeline) advanceProgramCounter(
+	s *slot,
+	programCounterAdvance int,
+) {
+	if int(s.next)+programCounterAdvance < len(pipeline.programMemory) {
+		s.next += memory.Address(programCounterAdvance)
+
+		return
+	}
+
+	switch pipeline.configuration.ProgramCounterOverflow {
+	case processor.Wrap:
+		s.next += memory.Address(programCounterAdvance)
+	case processor.Halt:
+		s.halted = true
+	default:
+		programCounterAdvance, ok := pipeline.fault(
+			s,
+			processor.ErrProgramCounterOverflow,
+			programCounterAdvance,
+		)
+		if ok {
+			s.next += memory.Address(programCounterAdvance)
+		}
+	}
+}
+
+func (pipeline *Pipeline) executionError(s *slot, err error) error {
+	length := s.length
+	remaining := len(pipeline.programMemory) - int(s.address)
+
+	if length > remaining &&
+		pipeline.configuration.FetchBeyondEnd != processor.Wrap {
+		length = remaining
+	}
+
+	instructionBytes := make([]byte, length)
+
+	for index := range instructionBytes {
+		instructionBytes[index] = pipeline.programMemory[s.address+memory.Address(index)]
+	}
+
+	return &processor.ExecutionError{
+		Err:            err,
+		Step:           pipeline.steps + 1,
+		ProgramCounter: s.address,
+		Opcode:         pipeline.programMemory[s.address],
+		Instruction:    instructionBytes,
+	}
+}
+
+// unknownRegisterError reports the first of r1, r2 and r3 that is unknown
+func unknownRegisterError(registers ...byte) error {
+	for index, register := range registers {
+		if register > 3 {
+			return &processor.RegisterError{Operand: index + 1, Register: register}
+		}
+	}
+
+	return nil
+}
+
+// writeBack retires the instruction in writeback, or counts the bubble
+func (pipeline *Pipeline) writeBack() {
+	s := pipeline.stages[writeback]
+
+	switch {
+	case s == nil:
+		pipeline.statistics.Stalls[Fill]++
+
+		return
+	case s.bubble:
+		pipeline.statistics.Stalls[s.reason]++
+
+		return
+	}
+
+	pipeline.steps++
+	pipeline.statistics.Instructions++
+
+	for _, write := range s.registerWrites {
+		pipeline.registers[write.register] = write.value
+	}
+
+	if s.writesC {
+		pipeline.c = s.c
+	}
+
+	if s.writesE {
+		pipeline.e = s.e
+	}
+
+	if s.prints {
+		fmt.Fprint(pipeline.output, string(rune(s.character)))
+
+		if pipeline.reference != nil {
+			fmt.Fprint(&pipeline.printed, string(rune(s.character)))
+		}
+	}
+
+	pipeline.programCounter = s.next
+	pipeline.halted = s.halted
+	pipeline.err = s.err
+	pipeline.done = s.halted || s.err != nil
+
+	if pipeline.reference != nil {
+		err := pipeline.compare()
+		if err != nil {
+			pipeline.done = true
+			pipeline.err = err
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package pipeline
+
+import (
+	"github.com/tmornini/rigetti-computing/memory"
+	"github.com/tmornini/rigetti-computing/processor"
+)
+
+// StallReason is why a cycle retired no instruction
+type StallReason int
+
+const (
+	noStall StallReason = iota
+
+	// Fill the pipeline filling after it starts
+	Fill
+
+	// DataHazard decode reading a register or flag execute writes, without
+	// forwarding
+	DataHazard
+
+	// LoadUseHazard decode reading the register an LDM in execute loads,
+	// which main memory returns too late to forward
+	LoadUseHazard
+
+	// JumpPenalty the instruction fetched after a JMP, which decode
+	// redirects
+	JumpPenalty
+
+	// BranchPenalty the instructions fetched after a JMC or JME that
+	// jumps, which execute redirects
+	BranchPenalty
+
+	// TrapPenalty the instructions fetched after a fault that traps
+	TrapPenalty
+
+	// RedirectPenalty the instructions fetched after any other change of
+	// flow execute redirects
+	RedirectPenalty
+
+	numberOfStallReasons
+)
+
+var stallReasonNames = [numberOfStallReasons]string{
+	"none",
+	"pipeline fill",
+	"data hazard",
+	"load-use hazard",
+	"JMP",
+	"JMC/JME taken",
+	"fault trapped",
+	"redirect",
+}
+
+func (reason StallReason) String() string {
+	if reason < 0 || reason >= numberOfStallReasons {
+		return "???"
+	}
+
+	return stallReasonNames[reason]
+}
+
+// decode redirects fetch to the target of a JMP, and reports whether the
+// instruction in decode must wait for the one in execute
+func (pipeline *Pipeline) decode() StallReason {
+	s := pipeline.stages[decode]
+	if s == nil || s.bubble || s.fetchErr != nil {
+		return noStall
+	}
+
+	stall := pipeline.hazard(s)
+	if stall != noStall {
+		return stall
+	}
+
+	if s.opcode == processor.JMP {
+		pipeline.stages[fetch] = bubble(JumpPenalty)
+		pipeline.fetchAddress = memory.Address(s.imm)
+	}
+
+	return noStall
+}
+
+// hazard reports whether s reads a register or flag the instruction in
+// execute writes, before it can be read
+func (pipeline *Pipeline) hazard(s *slot) StallReason {
+	producer := pipeline.stages[execute]
+	if producer == nil || producer.bubble {
+		return noStall
+	}
+
+	reads := registersRead(s)
+
+	for _, write := range producer.registerWrites {
+		if !reads[write.register] {
+			continue
+		}
+
+		switch {
+		case producer.opcode == processor.LDM:
+			return LoadUseHazard
+		case !pipeline.options.Forwarding:
+			return DataHazard
+		}
+	}
+
+	if pipeline.options.Forwarding {
+		return noStall
+	}
+
+	if (s.opcode == processor.JMC && producer.writesC) ||
+		(s.opcode == processor.JME && producer.writesE) {
+		return DataHazard
+	}
+
+	return noStall
+}
+
+// registersRead by an instruction, ignoring unknown registers, which fault
+func registersRead(s *slot) (reads [4]bool) {
+	var registers []byte
+
+	switch s.opcode {
+	case processor.ADD, processor.SUB, processor.MUL, processor.DIV,
+		processor.STR, processor.SWP, processor.EQL, processor.NQL:
+		registers = []byte{s.r1, s.r2}
+	case processor.LDM, processor.PRN:
+		registers = []byte{s.r1}
+	}
+
+	for _, register := range registers {
+		if int(register) < len(reads) {
+			reads[register] = true
+		}
+	}
+
+	return reads
+}
+
+// redirectReason is why execute flushes the instructions behind s
+func redirectReason(s *slot) StallReason {
+	switch {
+	case s.trapped:
+		return TrapPenalty
+	case s.opcode == processor.JMC || s.opcode == processor.JME:
+		return BranchPenalty
+	default:
+		return RedirectPenalty
+	}
+}
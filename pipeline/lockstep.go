@@ -0,0 +1,75 @@
+package pipeline
+
+import (
+	"fmt"
+
+	"github.com/tmornini/rigetti-computing/memory"
+)
+
+// compare the architectural state with the reference processor's, once it
+// has processed the instruction just retired
+func (pipeline *Pipeline) compare() error {
+	halted, err := pipeline.reference.Step()
+
+	mismatch := func(state string, format string, mine, theirs interface{}) error {
+		return &MismatchError{
+			Step:      pipeline.steps,
+			State:     state,
+			Pipeline:  fmt.Sprintf(format, mine),
+			Processor: fmt.Sprintf(format, theirs),
+		}
+	}
+
+	mine, theirs := pipeline.Registers(), pipeline.reference.Registers()
+
+	states := []struct {
+		name   string
+		format string
+		mine   interface{}
+		theirs interface{}
+	}{
+		{"PC", "%02x", byte(mine.ProgramCounter), byte(theirs.ProgramCounter)},
+		{"X", "%02x", mine.X, theirs.X},
+		{"Y", "%02x", mine.Y, theirs.Y},
+		{"Z", "%02x", mine.Z, theirs.Z},
+		{"W", "%02x", mine.W, theirs.W},
+		{"C", "%t", mine.C, theirs.C},
+		{"E", "%t", mine.E, theirs.E},
+		{"steps", "%d", pipeline.steps, pipeline.reference.Steps()},
+		{"halted", "%t", pipeline.halted, halted},
+		{"error", "%q", errorString(pipeline.err), errorString(err)},
+		{"output", "%q", pipeline.printed.String(), pipeline.referenceOutput.String()},
+	}
+
+	for _, state := range states {
+		if state.mine != state.theirs {
+			return mismatch(state.name, state.format, state.mine, state.theirs)
+		}
+	}
+
+	pipeline.printed.Reset()
+	pipeline.referenceOutput.Reset()
+
+	referenceMainMemory := pipeline.reference.MainMemory()
+
+	for address := range pipeline.mainMemory {
+		if pipeline.mainMemory[address] != referenceMainMemory[address] {
+			return mismatch(
+				fmt.Sprintf("main memory %02x", memory.Address(address)),
+				"%02x",
+				pipeline.mainMemory[address],
+				referenceMainMemory[address],
+			)
+		}
+	}
+
+	return nil
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	return err.Error()
+}
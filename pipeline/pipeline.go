@@ -0,0 +1,293 @@
+package pipeline
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/tmornini/rigetti-computing/memory"
+	"github.com/tmornini/rigetti-computing/processor"
+)
+
+// Pipeline executes programs as a classic in-order pipeline would, one
+// stage per clock cycle:
+//
+//	fetch      reads the instruction at the fetch address, predicting that
+//	           execution continues with the instruction that follows it
+//	decode     reads registers and flags, stalling until any instruction in
+//	           execute that writes them has written them back, and
+//	           redirects fetch to the target of a JMP
+//	execute    computes results, accesses main memory and resolves JMC,
+//	           JME and faults, flushing fetch and decode when they hold
+//	           instructions that should not execute
+//	writeback  writes registers and flags, prints and retires instructions
+//
+// Architecturally it is identical to a processor.Processor, which Lockstep
+// checks after every instruction retired. Only its timing differs
+type Pipeline struct {
+	configuration processor.Configuration
+	options       Options
+
+	programMemory memory.ReadOnly
+	mainMemory    memory.ReadWrite
+
+	output  io.Writer
+	printed bytes.Buffer // for Lockstep
+
+	// architectural state, as of the last instruction retired
+	programCounter memory.Address
+	registers      [4]byte
+	c              bool
+	e              bool
+	steps          uint64
+
+	stages       [numberOfStages]*slot
+	fetchAddress memory.Address
+	fetching     bool
+
+	statistics Statistics
+
+	reference       *processor.Processor // nil unless Options.Lockstep
+	referenceOutput bytes.Buffer
+
+	initial *processor.Snapshot
+
+	done   bool
+	halted bool
+	err    error
+}
+
+// Options select how the pipeline is built
+type Options struct {
+	// Forwarding passes results from execute straight to the instruction
+	// behind, so only instructions using the result of an LDM stall
+	Forwarding bool
+
+	// Lockstep executes a processor.Processor alongside, stopping with a
+	// *MismatchError as soon as their architectural state differs
+	Lockstep bool
+}
+
+type stage int
+
+const (
+	fetch stage = iota
+	decode
+	execute
+	writeback
+
+	numberOfStages
+)
+
+// New creates a pipeline in the state of p, with its configuration, leaving
+// p untouched. Observers and Timing are not applied to the pipeline
+func New(p *processor.Processor, options Options) (*Pipeline, error) {
+	snapshot := p.Snapshot()
+	registers := p.Registers()
+
+	pipeline := &Pipeline{
+		configuration: p.Configuration(),
+		options:       options,
+
+		programMemory: *p.ProgramMemory(),
+		mainMemory:    *p.MainMemory(),
+
+		output: p.Configuration().Output,
+
+		programCounter: registers.ProgramCounter,
+		registers:      [4]byte{registers.X, registers.Y, registers.Z, registers.W},
+		c:              registers.C,
+		e:              registers.E,
+		steps:          p.Steps(),
+
+		fetchAddress: registers.ProgramCounter,
+		fetching:     !snapshot.Halted,
+
+		initial: snapshot,
+
+		done:   snapshot.Halted,
+		halted: snapshot.Halted,
+	}
+
+	if pipeline.output == nil {
+		pipeline.output = os.Stdout
+	}
+
+	if snapshot.Error != nil {
+		executionError, err := snapshot.Error.ExecutionError()
+		if err != nil {
+			return nil, err
+		}
+
+		pipeline.fetching = false
+		pipeline.done = true
+		pipeline.err = executionError
+	}
+
+	if options.Lockstep {
+		configuration := pipeline.configuration
+		configuration.Output = &pipeline.referenceOutput
+		configuration.TraceOutput = ioutil.Discard
+		configuration.Observers = nil
+		configuration.Timing = nil
+
+		reference, err := processor.Restore(
+			processor.NormalInstructionSet,
+			configuration,
+			snapshot,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		pipeline.reference = reference
+	}
+
+	return pipeline, nil
+}
+
+// Run cycles until HLT retires, or an error that is not continuable, e.g.
+// a *processor.ExecutionError, *processor.StepLimitError or *MismatchError
+func (pipeline *Pipeline) Run() error {
+	for {
+		halted, err := pipeline.Cycle()
+		if halted || err != nil {
+			return err
+		}
+	}
+}
+
+// Cycle advances every stage by one clock cycle, reporting whether HLT has
+// retired
+func (pipeline *Pipeline) Cycle() (halted bool, err error) {
+	if pipeline.done {
+		return pipeline.halted, pipeline.err
+	}
+
+	pipeline.statistics.Cycles++
+
+	if pipeline.stages[fetch] == nil && pipeline.fetching {
+		pipeline.stages[fetch] = pipeline.fetchInstruction()
+	}
+
+	pipeline.writeBack()
+
+	if pipeline.done {
+		return pipeline.halted, pipeline.err
+	}
+
+	pipeline.execute()
+
+	stall := pipeline.decode()
+
+	pipeline.advance(stall)
+
+	return false, nil
+}
+
+// advance moves each instruction to its next stage. A stall holds decode
+// and fetch where they are, sending a bubble to execute in their place
+func (pipeline *Pipeline) advance(stall StallReason) {
+	stages := &pipeline.stages
+
+	stages[writeback] = stages[execute]
+
+	if stall != noStall {
+		stages[execute] = bubble(stall)
+
+		return
+	}
+
+	stages[execute] = stages[decode]
+	stages[decode] = stages[fetch]
+	stages[fetch] = nil
+}
+
+// flush replaces fetch and decode with bubbles, continuing fetch at address
+func (pipeline *Pipeline) flush(reason StallReason, address memory.Address) {
+	pipeline.stages[fetch] = bubble(reason)
+	pipeline.stages[decode] = bubble(reason)
+
+	pipeline.fetchAddress = address
+}
+
+// stop fetching, as the instruction in execute halts or aborts
+func (pipeline *Pipeline) stop() {
+	pipeline.stages[fetch] = nil
+	pipeline.stages[decode] = nil
+
+	pipeline.fetching = false
+}
+
+// expectedAddress is where the instruction following the one in execute
+// has been fetched from
+func (pipeline *Pipeline) expectedAddress() memory.Address {
+	for _, stage := range []stage{decode, fetch} {
+		s := pipeline.stages[stage]
+
+		if s != nil && !s.bubble {
+			return s.address
+		}
+	}
+
+	return pipeline.fetchAddress
+}
+
+// Registers returns the program counter, registers and flags as of the last
+// instruction retired
+func (pipeline *Pipeline) Registers() processor.Registers {
+	return processor.Registers{
+		ProgramCounter: pipeline.programCounter,
+
+		X: pipeline.registers[0],
+		Y: pipeline.registers[1],
+		Z: pipeline.registers[2],
+		W: pipeline.registers[3],
+
+		C: pipeline.c,
+		E: pipeline.e,
+	}
+}
+
+// MainMemory as of the last instruction executed
+func (pipeline *Pipeline) MainMemory() *memory.ReadWrite {
+	return &pipeline.mainMemory
+}
+
+// Steps number of instructions retired, including any that faulted
+func (pipeline *Pipeline) Steps() uint64 {
+	return pipeline.steps
+}
+
+// Statistics of the cycles so far
+func (pipeline *Pipeline) Statistics() Statistics {
+	return pipeline.statistics
+}
+
+// Snapshot captures the architectural state of the pipeline, which
+// processor.Restore continues from, or dumps
+func (pipeline *Pipeline) Snapshot() *processor.Snapshot {
+	snapshot := *pipeline.initial
+	registers := pipeline.Registers()
+
+	snapshot.ProgramCounter = byte(registers.ProgramCounter)
+
+	snapshot.X = registers.X
+	snapshot.Y = registers.Y
+	snapshot.Z = registers.Z
+	snapshot.W = registers.W
+
+	snapshot.C = registers.C
+	snapshot.E = registers.E
+
+	snapshot.Steps = pipeline.steps
+	snapshot.Halted = pipeline.halted
+	snapshot.Note = ""
+	snapshot.Error = processor.NewSnapshotError(pipeline.err)
+
+	snapshot.MainMemory = hex.EncodeToString(pipeline.mainMemory[:])
+
+	return &snapshot
+}
@@ -0,0 +1,277 @@
+package pipeline
+
+import (
+	"errors"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tmornini/rigetti-computing/memory"
+	"github.com/tmornini/rigetti-computing/processor"
+)
+
+const maxSteps = 1000
+
+var optionsByName = map[string]Options{
+	"stalling":   {Lockstep: true},
+	"forwarding": {Forwarding: true, Lockstep: true},
+}
+
+// run the program in lockstep, failing on a mismatch or statistics that
+// do not add up. Other errors are the program's own
+func run(
+	t *testing.T,
+	configuration processor.Configuration,
+	programMemory *memory.ReadOnly,
+	mainMemory *memory.ReadWrite,
+	options Options,
+) *Pipeline {
+	t.Helper()
+
+	configuration.MaxSteps = maxSteps
+	configuration.Output = ioutil.Discard
+
+	p := processor.New(processor.NormalInstructionSet, configuration, programMemory, mainMemory)
+
+	pipeline, err := New(p, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = pipeline.Run()
+	if errors.Is(err, ErrMismatch) {
+		t.Fatalf("%v\n%x", err, programMemory[:])
+	}
+
+	statistics := pipeline.Statistics()
+
+	cycles := statistics.Instructions
+
+	for _, stalls := range statistics.Stalls {
+		cycles += stalls
+	}
+
+	if cycles != statistics.Cycles {
+		t.Fatalf("%+v has stalls and instructions adding up to %d cycles", statistics, cycles)
+	}
+
+	if statistics.Instructions != pipeline.Steps() {
+		t.Fatalf("%d instructions retired in %d steps", statistics.Instructions, pipeline.Steps())
+	}
+
+	return pipeline
+}
+
+// TestFixtures runs every spec program that loads in lockstep, with and
+// without forwarding, each from zeroed main memory
+func TestFixtures(t *testing.T) {
+	var programs []string
+
+	for _, pattern := range []string{
+		"../spec-successes/*.hex",
+		"../spec-successes/*.ihex",
+		"../spec-successes/*.srec",
+		"../spec-failures/*.hex",
+	} {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		programs = append(programs, matches...)
+	}
+
+	if len(programs) == 0 {
+		t.Fatal("no spec programs found")
+	}
+
+	for _, program := range programs {
+		t.Run(filepath.Base(program), func(t *testing.T) {
+			file, err := os.Open(program)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer file.Close()
+
+			programMemory, err := memory.NewProgramFromImage(file)
+			if err != nil {
+				t.Skip(err)
+			}
+
+			for _, options := range optionsByName {
+				run(t, processor.DefaultConfiguration, programMemory, &memory.ReadWrite{}, options)
+			}
+		})
+	}
+}
+
+// TestRandomPrograms runs random programs and main memory in lockstep,
+// with random policies, so that faults trap and continue as well as abort
+func TestRandomPrograms(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+
+	for count := 0; count < 2000; count++ {
+		programMemory := randomProgram(random)
+
+		var mainMemory memory.ReadWrite
+		random.Read(mainMemory[:])
+
+		configuration := randomConfiguration(random)
+
+		for name, options := range optionsByName {
+			t.Run(name, func(t *testing.T) {
+				run(t, configuration, programMemory, &mainMemory, options)
+			})
+		}
+	}
+}
+
+// randomProgram is mostly valid instructions, with occasional unknown
+// opcodes and registers, jumping within the program and near its end
+func randomProgram(random *rand.Rand) *memory.ReadOnly {
+	var programMemory memory.ReadOnly
+
+	size := 1 + random.Intn(48)
+
+	start := 0
+	if random.Intn(8) == 0 {
+		start = len(programMemory) - size // so that it runs past the end
+	}
+
+	sometimes := func(usually int, otherwise int) byte {
+		if random.Intn(20) == 0 {
+			return byte(random.Intn(otherwise))
+		}
+
+		return byte(random.Intn(usually))
+	}
+
+	for address := start; address < start+size; {
+		opcode := sometimes(processor.HLT+1, 256)
+		programMemory[address] = opcode
+		address++
+
+		for _, operand := range processor.OpcodeOperands(opcode) {
+			if address == len(programMemory) {
+				break
+			}
+
+			switch {
+			case operand == 'r':
+				programMemory[address] = sometimes(4, 256)
+			case opcode == processor.JMP || processor.IsConditionalJump(opcode):
+				programMemory[address] = byte(start + random.Intn(size))
+			default:
+				programMemory[address] = byte(random.Intn(256))
+			}
+
+			address++
+		}
+	}
+
+	if start != 0 {
+		programMemory[0] = processor.JMP
+		programMemory[1] = byte(start)
+	}
+
+	return &programMemory
+}
+
+// randomConfiguration selects random end of program memory and fault
+// policies, and fault handler
+func randomConfiguration(random *rand.Rand) processor.Configuration {
+	configuration := processor.DefaultConfiguration
+
+	endOfProgramMemoryPolicies := []processor.EndOfProgramMemoryPolicy{
+		processor.Fault,
+		processor.Wrap,
+		processor.Halt,
+	}
+
+	configuration.FetchBeyondEnd = endOfProgramMemoryPolicies[random.Intn(3)]
+	configuration.ProgramCounterOverflow = endOfProgramMemoryPolicies[random.Intn(3)]
+
+	faultPolicies := []processor.FaultPolicy{processor.Abort, processor.SetE, processor.Trap}
+
+	for class := range configuration.FaultPolicies {
+		configuration.FaultPolicies[class] = faultPolicies[random.Intn(3)]
+	}
+
+	configuration.FaultHandler = memory.Address(random.Intn(64))
+
+	return configuration
+}
+
+// TestStatistics counts the stalls of a program with each kind of hazard
+// and change of flow, with and without forwarding
+func TestStatistics(t *testing.T) {
+	programMemory := &memory.ReadOnly{
+		0x06, 0x10, 0x00, //       LDI 0x10 X
+		0x06, 0x00, 0x03, //       LDI 0x00 W
+		0x05, 0x00, 0x01, //       LDM X Y
+		0x01, 0x01, 0x01, 0x02, // ADD Y Y Z, which uses Y just loaded
+		0x01, 0x02, 0x02, 0x03, // ADD Z Z W
+		0x0b, 0x15, //             JMP 0x15
+		0x0f, 0x0f, //             HLT HLT, which are skipped
+		0x09, 0x00, 0x00, //       EQL X X
+		0x0c, 0x1c, //             JMC 0x1c, which is taken
+		0x0f, 0x0f, //             HLT HLT, which are skipped
+		0x0f, //                   HLT
+	}
+
+	var mainMemory memory.ReadWrite
+	mainMemory[0x10] = 0x03
+
+	for _, test := range []struct {
+		name    string
+		options Options
+		stalls  [numberOfStallReasons]uint64
+	}{
+		{
+			name:    "stalling",
+			options: optionsByName["stalling"],
+			// the data hazards are on Z and C
+			stalls: [numberOfStallReasons]uint64{
+				Fill:          3,
+				DataHazard:    2,
+				LoadUseHazard: 1,
+				JumpPenalty:   1,
+				BranchPenalty: 2,
+			},
+		},
+		{
+			name:    "forwarding",
+			options: optionsByName["forwarding"],
+			stalls: [numberOfStallReasons]uint64{
+				Fill:          3,
+				LoadUseHazard: 1,
+				JumpPenalty:   1,
+				BranchPenalty: 2,
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			pipeline := run(t, processor.DefaultConfiguration, programMemory, &mainMemory, test.options)
+
+			registers := pipeline.Registers()
+
+			if !pipeline.halted || registers.ProgramCounter != 0x1c || registers.W != 0x0c {
+				t.Fatalf("halted %t at %s, want HLT at 1c with W 0c", pipeline.halted, registers)
+			}
+
+			statistics := pipeline.Statistics()
+
+			if statistics.Instructions != 9 || statistics.Stalls != test.stalls {
+				t.Errorf("got %+v, want 9 instructions and stalls %v", statistics, test.stalls)
+			}
+
+			cycles := 9 + 3 + 1 + 1 + 2 + test.stalls[DataHazard]
+
+			if statistics.Cycles != cycles || statistics.CPI() != float64(cycles)/9 {
+				t.Errorf("got %d cycles, CPI %.3f, want %d", statistics.Cycles, statistics.CPI(), cycles)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package pipeline
+
+import (
+	"github.com/tmornini/rigetti-computing/memory"
+	"github.com/tmornini/rigetti-computing/processor"
+)
+
+// slot is an instruction, or a bubble, in a stage of the pipeline
+type slot struct {
+	bubble bool
+	reason StallReason // why this is a bubble
+
+	address memory.Address
+	opcode  byte
+	length  int
+
+	r1  byte
+	r2  byte
+	r3  byte
+	imm byte
+
+	fetchErr error // processor.ErrFetchBeyondEnd, when it faults
+
+	// results of execute, which writeback writes
+	registerWrites []registerWrite
+	writesC        bool
+	c              bool
+	writesE        bool
+	e              bool
+	prints         bool
+	character      byte
+
+	next    memory.Address // the PC once it retires
+	halted  bool
+	trapped bool
+	err     error // not continuable
+}
+
+type registerWrite struct {
+	register byte
+	value    byte
+}
+
+func bubble(reason StallReason) *slot {
+	return &slot{bubble: true, reason: reason}
+}
+
+// fetchInstruction reads the instruction at the fetch address, applying
+// the FetchBeyondEnd policy as processor.Processor does, and predicts the
+// next instruction follows it
+func (pipeline *Pipeline) fetchInstruction() *slot {
+	address := pipeline.fetchAddress
+	opcode := pipeline.programMemory[address]
+
+	s := &slot{
+		address: address,
+		opcode:  opcode,
+		length:  processor.InstructionLength(opcode),
+	}
+
+	if int(address)+s.length > len(pipeline.programMemory) {
+		switch pipeline.configuration.FetchBeyondEnd {
+		case processor.Wrap:
+		case processor.Halt:
+			s.opcode = processor.HLT
+		default:
+			s.fetchErr = processor.ErrFetchBeyondEnd
+		}
+	}
+
+	parameters := make([]byte, s.length-1)
+
+	for index := range parameters {
+		parameters[index] = pipeline.programMemory[address+1+memory.Address(index)]
+	}
+
+	switch len(parameters) {
+	case 1:
+		s.r1, s.imm = parameters[0], parameters[0]
+	case 2:
+		if s.opcode == processor.LDI {
+			s.imm, s.r1 = parameters[0], parameters[1]
+		} else {
+			s.r1, s.r2 = parameters[0], parameters[1]
+		}
+	case 3:
+		s.r1, s.r2, s.r3 = parameters[0], parameters[1], parameters[2]
+	}
+
+	pipeline.fetchAddress = address + memory.Address(s.length)
+
+	return s
+}
@@ -0,0 +1,56 @@
+package pipeline
+
+import (
+	"fmt"
+	"io"
+)
+
+// Statistics counts cycles, and why cycles retired no instruction. Each
+// cycle retires at most one, so Cycles is Instructions plus the stalls
+type Statistics struct {
+	Cycles       uint64
+	Instructions uint64
+	Stalls       [numberOfStallReasons]uint64
+}
+
+// CPI cycles per instruction retired, 0 before any has retired
+func (statistics Statistics) CPI() float64 {
+	if statistics.Instructions == 0 {
+		return 0
+	}
+
+	return float64(statistics.Cycles) / float64(statistics.Instructions)
+}
+
+// Write reports the CPI, and the cycles each stall reason cost
+func (statistics Statistics) Write(writer io.Writer) error {
+	_, err := fmt.Fprintf(
+		writer,
+		"%d instructions in %d cycles, CPI %.2f\n",
+		statistics.Instructions,
+		statistics.Cycles,
+		statistics.CPI(),
+	)
+	if err != nil {
+		return err
+	}
+
+	for reason, stalls := range statistics.Stalls {
+		if stalls == 0 {
+			continue
+		}
+
+		_, err = fmt.Fprintf(
+			writer,
+			"%8d cycles %-16s %5.1f%%\n",
+			stalls,
+			StallReason(reason),
+			100*float64(stalls)/float64(statistics.Cycles),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,364 @@
+package processor
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/tmornini/rigetti-computing/memory"
+)
+
+// assembly language is one instruction or directive per line, optionally
+// preceded by a label and followed by a comment:
+//
+//	LOOP:   LDM X Y         ; Y is character at address
+//	        JMC DONE        // labels may be used before they are defined
+//	        .org 0xf0       ; continue assembling at #xF0
+//	DONE:   HLT
+//	        .byte 0x48 0x69 ; raw bytes
+//
+// operands are separated by spaces or commas. Registers are X, Y, Z, W or a
+// byte. Bytes are hex, with an optional 0x or #x prefix
+const (
+	registerOperand  = 'r'
+	immediateOperand = 'i'
+
+	jumpOperands = "i"
+)
+
+// operand kinds by opcode, in the order they are encoded
+var opcodeOperands = [256]string{
+	"",    // NOP
+	"rrr", // ADD
+	"rrr", // SUB
+	"rrr", // MUL
+	"rrr", // DIV
+	"rr",  // LDM
+	"ir",  // LDI
+	"rr",  // STR
+	"rr",  // SWP
+	"rr",  // EQL
+	"rr",  // NQL
+	"i",   // JMP
+	"i",   // JMC
+	"i",   // JME
+	"r",   // PRN
+	"",    // HLT
+}
+
+// OpcodeName returns the mnemonic of an opcode, ??? when it is unknown
+func OpcodeName(opcode byte) string {
+	return opcodeNames[opcode]
+}
+
+// OpcodeOperands returns the kinds of an opcode's operands in the order
+// they are encoded, r for a register and i for an immediate byte
+func OpcodeOperands(opcode byte) string {
+	return opcodeOperands[opcode]
+}
+
+// ParseOpcode returns the opcode of a mnemonic, e.g. NOP or nop
+func ParseOpcode(mnemonic string) (byte, error) {
+	upper := strings.ToUpper(mnemonic)
+
+	for opcode, name := range opcodeNames {
+		if name == upper && name != "???" {
+			return byte(opcode), nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w: %s", ErrUnknownOpcode, mnemonic)
+}
+
+// assemblyStatement is an instruction or .byte directive, assembled once
+// every label is known
+type assemblyStatement struct {
+	lineNumber int
+	address    int
+	opcode     byte
+	operands   []string
+	directive  bool
+}
+
+// Assemble converts assembly language to the bytes of a program. Gaps left
+// by .org are filled with zeros, which are NOPs
+func Assemble(reader io.Reader) ([]byte, error) {
+	program, _, err := AssembleWithLines(reader)
+
+	return program, err
+}
+
+// AssembleWithLines is Assemble, also returning the source line number of
+// the instruction assembled at each address, 0 where none begins
+func AssembleWithLines(reader io.Reader) ([]byte, [256]int, error) {
+	lines := [256]int{}
+
+	var statements []assemblyStatement
+
+	labels := map[string]int{}
+
+	scanner := bufio.NewScanner(reader)
+	lineNumber := 0
+	address := 0
+
+	for scanner.Scan() {
+		lineNumber++
+
+		lineError := func(format string, arguments ...interface{}) error {
+			return assemblyError(lineNumber, format, arguments...)
+		}
+
+		fields := assemblyFields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		if strings.HasSuffix(fields[0], ":") {
+			label := strings.TrimSuffix(fields[0], ":")
+
+			if label == "" {
+				return nil, lines, lineError("empty label")
+			}
+
+			if _, ok := labels[label]; ok {
+				return nil, lines, lineError("label %s is already defined", label)
+			}
+
+			labels[label] = address
+			fields = fields[1:]
+
+			if len(fields) == 0 {
+				continue
+			}
+		}
+
+		switch strings.ToLower(fields[0]) {
+		case ".org":
+			if len(fields) != 2 {
+				return nil, lines, lineError(".org takes 1 address")
+			}
+
+			origin, err := parseAssemblyByte(fields[1])
+			if err != nil {
+				return nil, lines, lineError("%s", err)
+			}
+
+			address = int(origin)
+		case ".byte":
+			statements = append(statements, assemblyStatement{
+				lineNumber: lineNumber,
+				address:    address,
+				operands:   fields[1:],
+				directive:  true,
+			})
+
+			address += len(fields) - 1
+		default:
+			opcode, err := ParseOpcode(fields[0])
+			if err != nil {
+				return nil, lines, lineError("%s", err)
+			}
+
+			operands := fields[1:]
+
+			if len(operands) != len(opcodeOperands[opcode]) {
+				return nil, lines, lineError(
+					"%s takes %d operands, has %d",
+					opcodeNames[opcode],
+					len(opcodeOperands[opcode]),
+					len(operands),
+				)
+			}
+
+			statements = append(statements, assemblyStatement{
+				lineNumber: lineNumber,
+				address:    address,
+				opcode:     opcode,
+				operands:   operands,
+			})
+
+			address += 1 + len(operands)
+		}
+	}
+
+	err := scanner.Err()
+	if err != nil {
+		return nil, lines, err
+	}
+
+	for _, statement := range statements {
+		if !statement.directive && statement.address < len(lines) {
+			lines[statement.address] = statement.lineNumber
+		}
+	}
+
+	program, err := assembleStatements(statements, labels)
+
+	return program, lines, err
+}
+
+func assembleStatements(
+	statements []assemblyStatement,
+	labels map[string]int,
+) ([]byte, error) {
+	var program []byte
+
+	written := [256]bool{}
+
+	for _, statement := range statements {
+		statementBytes, err := statement.assemble(labels)
+		if err != nil {
+			return nil, assemblyError(statement.lineNumber, "%s", err)
+		}
+
+		end := statement.address + len(statementBytes)
+
+		if end > len(written) {
+			return nil, assemblyError(
+				statement.lineNumber,
+				"%x-%x is beyond the end of program memory at ff",
+				statement.address,
+				end-1,
+			)
+		}
+
+		if end > len(program) {
+			program = append(program, make([]byte, end-len(program))...)
+		}
+
+		for index, value := range statementBytes {
+			address := statement.address + index
+
+			if written[address] {
+				return nil, assemblyError(
+					statement.lineNumber,
+					"%02x has already been assembled",
+					address,
+				)
+			}
+
+			written[address] = true
+			program[address] = value
+		}
+	}
+
+	return program, nil
+}
+
+func (statement assemblyStatement) assemble(
+	labels map[string]int,
+) ([]byte, error) {
+	if statement.directive {
+		var statementBytes []byte
+
+		for _, operand := range statement.operands {
+			value, err := parseAssemblyByte(operand)
+			if err != nil {
+				return nil, err
+			}
+
+			statementBytes = append(statementBytes, value)
+		}
+
+		return statementBytes, nil
+	}
+
+	statementBytes := []byte{statement.opcode}
+
+	for index, kind := range opcodeOperands[statement.opcode] {
+		operand := statement.operands[index]
+
+		var value byte
+		var err error
+
+		if kind == registerOperand {
+			value, err = parseAssemblyRegister(operand)
+		} else {
+			value, err = parseAssemblyImmediate(operand, labels)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		statementBytes = append(statementBytes, value)
+	}
+
+	return statementBytes, nil
+}
+
+// assemblyFields splits a line into label, mnemonic and operands, dropping
+// any ; or // comment
+func assemblyFields(line string) []string {
+	if index := strings.Index(line, ";"); index >= 0 {
+		line = line[:index]
+	}
+
+	if index := strings.Index(line, "//"); index >= 0 {
+		line = line[:index]
+	}
+
+	return strings.FieldsFunc(line, func(character rune) bool {
+		return character == ',' || character == ' ' || character == '\t'
+	})
+}
+
+func parseAssemblyRegister(operand string) (byte, error) {
+	for register, name := range registerNames[:w+1] {
+		if strings.EqualFold(operand, name) {
+			return byte(register), nil
+		}
+	}
+
+	value, err := parseAssemblyByte(operand)
+	if err != nil {
+		return 0, fmt.Errorf("%s is not a register", operand)
+	}
+
+	return value, nil
+}
+
+func parseAssemblyImmediate(
+	operand string,
+	labels map[string]int,
+) (byte, error) {
+	if address, ok := labels[operand]; ok {
+		return byte(address), nil
+	}
+
+	value, err := parseAssemblyByte(operand)
+	if err != nil {
+		return 0, fmt.Errorf("%s is not a byte or a label", operand)
+	}
+
+	return value, nil
+}
+
+func parseAssemblyByte(operand string) (byte, error) {
+	digits := operand
+
+	for _, prefix := range []string{"0x", "0X", "#x", "#X"} {
+		digits = strings.TrimPrefix(digits, prefix)
+	}
+
+	value, err := strconv.ParseUint(digits, 16, 8)
+	if err != nil {
+		return 0, fmt.Errorf("%s is not a hex byte from 00 to ff", operand)
+	}
+
+	return byte(value), nil
+}
+
+func assemblyError(
+	lineNumber int,
+	format string,
+	arguments ...interface{},
+) error {
+	return &memory.LineError{
+		Err:    ErrInvalidAssembly,
+		Line:   lineNumber,
+		Reason: fmt.Sprintf(format, arguments...),
+	}
+}
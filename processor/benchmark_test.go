@@ -0,0 +1,98 @@
+package processor
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/tmornini/rigetti-computing/workload"
+)
+
+var benchmarkConfiguration = func() Configuration {
+	configuration := DefaultConfiguration
+
+	configuration.Output = ioutil.Discard
+	configuration.TraceOutput = ioutil.Discard
+
+	return configuration
+}()
+
+func BenchmarkOpcodes(b *testing.B) {
+	for opcode := 0; opcode <= HLT; opcode++ {
+		i := instruction{opcode: byte(opcode), r1: x, r2: y, r3: z, imm: 0x10}
+
+		b.Run(i.Name(), func(b *testing.B) {
+			program := workload.StringLength.ProgramMemory
+			mainMemory := workload.StringLength.MainMemory
+
+			p := New(
+				NormalInstructionSet,
+				benchmarkConfiguration,
+				&program,
+				&mainMemory,
+			)
+			p.registers = [4]byte{0x03, 0x02, 0x01, 0x00}
+
+			instructionFunc := p.instructionSet[i.opcode]
+
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for n := 0; n < b.N; n++ {
+				instructionFunc(p, i)
+			}
+		})
+	}
+}
+
+func benchmarkWorkload(
+	b *testing.B,
+	w workload.Workload,
+	instructionSet InstructionSet,
+	decodeEveryStep bool,
+) {
+	b.ReportAllocs()
+
+	var steps uint64
+
+	for n := 0; n < b.N; n++ {
+		program := w.ProgramMemory
+		mainMemory := w.MainMemory
+
+		p := New(instructionSet, benchmarkConfiguration, &program, &mainMemory)
+
+		if decodeEveryStep {
+			p.decodedInstructions = nil
+		}
+
+		err := p.Run()
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		steps = p.Steps()
+	}
+
+	b.ReportMetric(float64(steps), "instructions/op")
+}
+
+func BenchmarkWorkloads(b *testing.B) {
+	for _, w := range workload.All {
+		w := w
+
+		b.Run(w.Name, func(b *testing.B) {
+			benchmarkWorkload(b, w, NormalInstructionSet, false)
+		})
+
+		b.Run(w.Name+"/debug", func(b *testing.B) {
+			benchmarkWorkload(b, w, DebugInstructionSet, false)
+		})
+	}
+}
+
+func BenchmarkStringLengthDecodedAtBoot(b *testing.B) {
+	benchmarkWorkload(b, workload.StringLength, NormalInstructionSet, false)
+}
+
+func BenchmarkStringLengthDecodedEveryStep(b *testing.B) {
+	benchmarkWorkload(b, workload.StringLength, NormalInstructionSet, true)
+}
@@ -0,0 +1,53 @@
+package processor
+
+import (
+	"io"
+
+	"github.com/tmornini/rigetti-computing/memory"
+)
+
+// Configuration selects processor behavior not dictated by the ISA
+type Configuration struct {
+	// FetchBeyondEnd applies when an instruction's parameters extend past
+	// the last byte of program memory
+	FetchBeyondEnd EndOfProgramMemoryPolicy
+
+	// ProgramCounterOverflow applies when advancing the PC past the last
+	// byte of program memory
+	ProgramCounterOverflow EndOfProgramMemoryPolicy
+
+	// FaultPolicies selects a FaultPolicy for each FaultClass. The
+	// fetch-beyond-end and pc-overflow classes only occur when
+	// FetchBeyondEnd and ProgramCounterOverflow are Fault
+	FaultPolicies FaultPolicies
+
+	// FaultHandler is where the Trap fault policy continues execution
+	FaultHandler memory.Address
+
+	// MaxSteps stops Run with a *StepLimitError after this many steps, zero
+	// is unlimited
+	MaxSteps uint64
+
+	// Timing counts cycles when not nil
+	Timing *Timing
+
+	// Observers are told about every instruction processed
+	Observers []Observer
+
+	// Output receives PRN characters, os.Stdout when nil
+	Output io.Writer
+
+	// TraceOutput receives DebugInstructionSet traces, os.Stderr when nil
+	TraceOutput io.Writer
+}
+
+// DefaultConfiguration faults on fetches beyond the end of program memory,
+// lets the PC wrap around and continues only after divide by zero
+var DefaultConfiguration = Configuration{
+	FetchBeyondEnd:         Fault,
+	ProgramCounterOverflow: Wrap,
+
+	FaultPolicies: FaultPolicies{
+		DivideByZeroFault: SetE,
+	},
+}
@@ -0,0 +1,215 @@
+package processor
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/tmornini/rigetti-computing/memory"
+)
+
+// DisassembledInstruction is the assembly language for the instruction at
+// Address. Err is the error executing it would fault with before touching
+// registers or memory, e.g. an unknown register
+type DisassembledInstruction struct {
+	Address  memory.Address
+	Bytes    []byte
+	Assembly string
+	Err      error
+}
+
+// Disassemble the instruction at address. Unknown opcodes and instructions
+// that extend past #xFF are disassembled as .byte directives
+func Disassemble(
+	programMemory *memory.ReadOnly,
+	address memory.Address,
+) DisassembledInstruction {
+	opcode := programMemory[address]
+	length := 1 + opcodeParameterLengths[opcode]
+
+	disassembled := DisassembledInstruction{Address: address}
+
+	if opcodeNames[opcode] == "???" {
+		disassembled.Bytes = programMemory[address : int(address)+1]
+		disassembled.Assembly = byteDirective(disassembled.Bytes)
+		disassembled.Err = ErrUnknownOpcode
+
+		return disassembled
+	}
+
+	if int(address)+length > len(programMemory) {
+		disassembled.Bytes = programMemory[address:]
+		disassembled.Assembly = byteDirective(disassembled.Bytes)
+		disassembled.Err = ErrFetchBeyondEnd
+
+		return disassembled
+	}
+
+	disassembled.Bytes = programMemory[address : int(address)+length]
+
+	assembly := []string{opcodeNames[opcode]}
+	var registers []byte
+
+	for index, kind := range opcodeOperands[opcode] {
+		operand := disassembled.Bytes[1+index]
+
+		if kind == registerOperand {
+			registers = append(registers, operand)
+
+			if !unknownRegister(operand) {
+				assembly = append(assembly, registerNames[operand])
+
+				continue
+			}
+		}
+
+		assembly = append(assembly, fmt.Sprintf("0x%02x", operand))
+	}
+
+	disassembled.Assembly = strings.Join(assembly, " ")
+	disassembled.Err = unknownRegisterError(registers...)
+
+	return disassembled
+}
+
+// DisassembleProgram disassembles program memory from #x00 in a single
+// sweep. Runs of two or more zero bytes are NOP padding, and are skipped
+func DisassembleProgram(
+	programMemory *memory.ReadOnly,
+) []DisassembledInstruction {
+	var disassembled []DisassembledInstruction
+
+	padding := false
+
+	for address := 0; address < len(programMemory); {
+		if programMemory[address] == 0 {
+			padding = padding ||
+				address+1 < len(programMemory) && programMemory[address+1] == 0
+
+			if padding {
+				address++
+
+				continue
+			}
+		} else {
+			padding = false
+		}
+
+		instruction := Disassemble(programMemory, memory.Address(address))
+
+		disassembled = append(disassembled, instruction)
+
+		address += len(instruction.Bytes)
+	}
+
+	return disassembled
+}
+
+// WriteListing writes disassembled instructions as assembly language that
+// Assemble accepts, labelling jump targets and commenting each instruction
+// with its address and bytes, plus annotate's text when annotate is not nil
+func WriteListing(
+	writer io.Writer,
+	disassembled []DisassembledInstruction,
+	annotate func(memory.Address) string,
+) error {
+	labels := map[memory.Address]string{}
+
+	for _, instruction := range disassembled {
+		labels[instruction.Address] = fmt.Sprintf("L%02x", instruction.Address)
+	}
+
+	jumpTargets := map[memory.Address]bool{}
+
+	for _, instruction := range disassembled {
+		if isJump(instruction) {
+			jumpTargets[memory.Address(instruction.Bytes[1])] = true
+		}
+	}
+
+	next := memory.Address(0)
+
+	for _, instruction := range disassembled {
+		if instruction.Address != next {
+			_, err := fmt.Fprintf(writer, "        .org 0x%02x\n", instruction.Address)
+			if err != nil {
+				return err
+			}
+		}
+
+		next = instruction.Address + memory.Address(len(instruction.Bytes))
+
+		label := ""
+		if jumpTargets[instruction.Address] {
+			label = labels[instruction.Address] + ":"
+		}
+
+		assembly := instruction.Assembly
+
+		if isJump(instruction) {
+			target, ok := labels[memory.Address(instruction.Bytes[1])]
+			if ok {
+				assembly = opcodeNames[instruction.Bytes[0]] + " " + target
+			}
+		}
+
+		comment := fmt.Sprintf("%02x: % x", instruction.Address, instruction.Bytes)
+
+		if instruction.Err != nil {
+			comment += " | " + instruction.Err.Error()
+		}
+
+		if annotate != nil {
+			annotation := annotate(instruction.Address)
+			if annotation != "" {
+				comment += " | " + annotation
+			}
+		}
+
+		_, err := fmt.Fprintf(writer, "%-8s%-20s; %s\n", label, assembly, comment)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ListingLines returns the line number WriteListing writes each instruction
+// on, counting .org lines
+func ListingLines(
+	disassembled []DisassembledInstruction,
+) map[memory.Address]int {
+	lines := map[memory.Address]int{}
+
+	line := 0
+	next := memory.Address(0)
+
+	for _, instruction := range disassembled {
+		if instruction.Address != next {
+			line++
+		}
+
+		line++
+		lines[instruction.Address] = line
+
+		next = instruction.Address + memory.Address(len(instruction.Bytes))
+	}
+
+	return lines
+}
+
+func isJump(instruction DisassembledInstruction) bool {
+	return instruction.Err == nil &&
+		opcodeOperands[instruction.Bytes[0]] == jumpOperands
+}
+
+func byteDirective(bytes []byte) string {
+	operands := []string{".byte"}
+
+	for _, value := range bytes {
+		operands = append(operands, fmt.Sprintf("0x%02x", value))
+	}
+
+	return strings.Join(operands, " ")
+}
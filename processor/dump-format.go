@@ -0,0 +1,188 @@
+package processor
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/tmornini/rigetti-computing/memory"
+)
+
+// DumpFormat selects how Dump writes the state of a processor
+type DumpFormat int
+
+const (
+	// StateDump registers, flags and both memories on single lines, as
+	// String has always written them
+	StateDump DumpFormat = iota
+
+	// HexDump registers and flags, then both memories as xxd writes them,
+	// 16 bytes per row with ASCII
+	HexDump
+
+	// JSONDump a JSON document of the PC, registers, flags, steps and both
+	// memories as hex
+	JSONDump
+
+	// DiffDump registers and flags, then only the main memory bytes that
+	// changed from the initial image
+	DiffDump
+)
+
+var dumpFormatNames = map[DumpFormat]string{
+	StateDump: "state",
+	HexDump:   "hexdump",
+	JSONDump:  "json",
+	DiffDump:  "diff",
+}
+
+func (format DumpFormat) String() string {
+	name, ok := dumpFormatNames[format]
+	if !ok {
+		return "???"
+	}
+
+	return name
+}
+
+// ParseDumpFormat converts state, hexdump, json or diff to a format
+func ParseDumpFormat(name string) (DumpFormat, error) {
+	for format, formatName := range dumpFormatNames {
+		if formatName == name {
+			return format, nil
+		}
+	}
+
+	return StateDump, ErrUnknownDumpFormat
+}
+
+// Dump writes the state of the processor in format
+func (p *Processor) Dump(writer io.Writer, format DumpFormat) error {
+	switch format {
+	case StateDump:
+		_, err := fmt.Fprintln(writer, p)
+
+		return err
+	case HexDump:
+		return p.hexDump(writer)
+	case JSONDump:
+		return p.jsonDump(writer)
+	case DiffDump:
+		return p.diffDump(writer)
+	default:
+		return ErrUnknownDumpFormat
+	}
+}
+
+func (p *Processor) hexDump(writer io.Writer) error {
+	_, err := fmt.Fprintf(
+		writer,
+		"Registers and Flags:\n%s%s\n\nProgram memory:\n",
+		p.registersAndFlagsAsString(),
+		p.timeAsString(),
+	)
+	if err != nil {
+		return err
+	}
+
+	err = memory.WriteHexDump(writer, p.programMemory[:])
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprint(writer, "\nMain memory:\n")
+	if err != nil {
+		return err
+	}
+
+	return memory.WriteHexDump(writer, p.mainMemory[:])
+}
+
+// jsonState is the document JSONDump writes
+type jsonState struct {
+	ProgramCounter byte `json:"pc"`
+
+	X byte `json:"x"`
+	Y byte `json:"y"`
+	Z byte `json:"z"`
+	W byte `json:"w"`
+
+	C bool `json:"c"`
+	E bool `json:"e"`
+
+	Steps uint64 `json:"steps"`
+
+	Cycles  *uint64 `json:"cycles,omitempty"`  // when cycles are counted
+	Seconds float64 `json:"seconds,omitempty"` // when the clock is known
+
+	ProgramMemory string `json:"programMemory"`
+	MainMemory    string `json:"mainMemory"`
+}
+
+func (p *Processor) jsonDump(writer io.Writer) error {
+	registers := p.Registers()
+
+	state := jsonState{
+		ProgramCounter: byte(registers.ProgramCounter),
+
+		X: registers.X,
+		Y: registers.Y,
+		Z: registers.Z,
+		W: registers.W,
+
+		C: registers.C,
+		E: registers.E,
+
+		Steps: p.steps,
+
+		ProgramMemory: hex.EncodeToString(p.programMemory[:]),
+		MainMemory:    hex.EncodeToString(p.mainMemory[:]),
+	}
+
+	if p.configuration.Timing != nil {
+		state.Cycles = &p.cycles
+		state.Seconds = p.Time().Seconds()
+	}
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(state)
+}
+
+// diffDump writes each changed byte as address: initial -> final
+func (p *Processor) diffDump(writer io.Writer) error {
+	_, err := fmt.Fprintf(
+		writer,
+		"Registers and Flags:\n%s%s\n\nMain memory changes:\n",
+		p.registersAndFlagsAsString(),
+		p.timeAsString(),
+	)
+	if err != nil {
+		return err
+	}
+
+	changes := 0
+
+	for address, initial := range p.initialMainMemory {
+		final := p.mainMemory[address]
+
+		if final == initial {
+			continue
+		}
+
+		changes++
+
+		_, err = fmt.Fprintf(writer, "%02x: %02x -> %02x\n", address, initial, final)
+		if err != nil {
+			return err
+		}
+	}
+
+	if changes == 0 {
+		_, err = fmt.Fprintln(writer, "none")
+	}
+
+	return err
+}
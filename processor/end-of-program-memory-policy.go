@@ -0,0 +1,42 @@
+package processor
+
+// EndOfProgramMemoryPolicy selects what happens when execution runs past
+// the last byte of program memory
+type EndOfProgramMemoryPolicy int
+
+const (
+	// Fault stops execution with an error naming the PC and opcode
+	Fault EndOfProgramMemoryPolicy = iota
+
+	// Wrap continues at the start of program memory
+	Wrap
+
+	// Halt behaves as if a HLT instruction had been executed
+	Halt
+)
+
+var endOfProgramMemoryPolicyNames = map[EndOfProgramMemoryPolicy]string{
+	Fault: "fault",
+	Wrap:  "wrap",
+	Halt:  "halt",
+}
+
+func (policy EndOfProgramMemoryPolicy) String() string {
+	name, ok := endOfProgramMemoryPolicyNames[policy]
+	if !ok {
+		return "???"
+	}
+
+	return name
+}
+
+// ParseEndOfProgramMemoryPolicy converts fault, wrap or halt to a policy
+func ParseEndOfProgramMemoryPolicy(name string) (EndOfProgramMemoryPolicy, error) {
+	for policy, policyName := range endOfProgramMemoryPolicyNames {
+		if policyName == name {
+			return policy, nil
+		}
+	}
+
+	return Fault, ErrUnknownEndOfProgramMemoryPolicy
+}
@@ -1,6 +1,11 @@
 package processor
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+
+	"github.com/tmornini/rigetti-computing/memory"
+)
 
 // ErrDivideByZero divide by zero
 var ErrDivideByZero = errors.New("divide by zero")
@@ -17,6 +22,124 @@ var ErrUnknownFlag = errors.New("unknown fkag")
 // ErrHLTExecuted HLT instruction
 var ErrHLTExecuted = errors.New("HLT instruction")
 
-func errorIsNotContinuable(err error) bool {
-	return err != nil && err != ErrDivideByZero
+// ErrFetchBeyondEnd instruction extends past the end of program memory
+var ErrFetchBeyondEnd = errors.New("fetch beyond end of program memory")
+
+// ErrProgramCounterOverflow PC advanced past the end of program memory
+var ErrProgramCounterOverflow = errors.New("program counter overflow")
+
+// ErrUnknownEndOfProgramMemoryPolicy unknown end of program memory policy
+var ErrUnknownEndOfProgramMemoryPolicy = errors.New(
+	"unknown end of program memory policy, must be fault, wrap or halt",
+)
+
+// ErrUnknownFaultClass unknown fault class
+var ErrUnknownFaultClass = errors.New("unknown fault class")
+
+// ErrUnknownFaultPolicy unknown fault policy
+var ErrUnknownFaultPolicy = errors.New(
+	"unknown fault policy, must be abort, set-e or trap",
+)
+
+// ErrStepLimitExceeded Configuration.MaxSteps instructions processed
+var ErrStepLimitExceeded = errors.New("step limit exceeded")
+
+// ErrInvalidAssembly assembly language that cannot be assembled
+var ErrInvalidAssembly = errors.New("invalid assembly")
+
+// ErrUnknownDumpFormat unknown dump format
+var ErrUnknownDumpFormat = errors.New(
+	"unknown dump format, must be state, hexdump, json or diff",
+)
+
+// ErrInvalidSnapshot snapshot that cannot be restored
+var ErrInvalidSnapshot = errors.New("invalid snapshot")
+
+// ErrInvalidTiming invalid timing file
+var ErrInvalidTiming = errors.New("invalid timing")
+
+// ErrInvalidClockFrequency invalid clock frequency
+var ErrInvalidClockFrequency = errors.New(
+	"invalid clock frequency, must be e.g. 4MHz, 1.5GHz or 32768",
+)
+
+// ErrInvalidInput input that cannot be preset
+var ErrInvalidInput = errors.New("invalid input")
+
+// RegisterError identifies the operand naming an unknown register
+type RegisterError struct {
+	Operand  int // 1-3, as in r1-r3
+	Register byte
+}
+
+func (err *RegisterError) Error() string {
+	return fmt.Sprintf(
+		"%s: r%d is %02x",
+		ErrUnknownRegister,
+		err.Operand,
+		err.Register,
+	)
+}
+
+// Unwrap allows errors.Is(err, ErrUnknownRegister)
+func (err *RegisterError) Unwrap() error {
+	return ErrUnknownRegister
+}
+
+// InputError identifies why Preset refused the inputs
+type InputError struct {
+	Inputs []Input
+	Reason string
+}
+
+func (err *InputError) Error() string {
+	return fmt.Sprintf("%s: %v, %s", ErrInvalidInput, err.Inputs, err.Reason)
+}
+
+// Unwrap allows errors.Is(err, ErrInvalidInput)
+func (err *InputError) Unwrap() error {
+	return ErrInvalidInput
+}
+
+// ExecutionError describes where in execution an error occurred
+//
+// Err is one of the sentinels above, a *RegisterError or a
+// *memory.AccessError, so errors.Is and errors.As see through it
+type ExecutionError struct {
+	Err error
+
+	Step           uint64 // 1 is the first instruction executed
+	ProgramCounter memory.Address
+	Opcode         byte
+	Instruction    []byte // opcode and parameters, as read from memory
+}
+
+func (err *ExecutionError) Error() string {
+	return fmt.Sprintf(
+		"step %d, PC:%02x, %s %x: %s",
+		err.Step,
+		byte(err.ProgramCounter),
+		opcodeNames[err.Opcode],
+		err.Instruction,
+		err.Err,
+	)
+}
+
+// Unwrap allows errors.Is and errors.As to inspect Err
+func (err *ExecutionError) Unwrap() error {
+	return err.Err
+}
+
+// StepLimitError reports how many steps were processed before the limit
+type StepLimitError struct {
+	Steps uint64
+}
+
+func (err *StepLimitError) Error() string {
+	return fmt.Sprintf("%s: %d steps", ErrStepLimitExceeded, err.Steps)
+}
+
+// Unwrap allows errors.Is(err, ErrStepLimitExceeded)
+func (err *StepLimitError) Unwrap() error {
+	return ErrStepLimitExceeded
 }
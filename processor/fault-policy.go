@@ -0,0 +1,124 @@
+package processor
+
+import (
+	"errors"
+
+	"github.com/tmornini/rigetti-computing/memory"
+)
+
+// FaultClass groups the errors that share a FaultPolicy
+type FaultClass int
+
+const (
+	// DivideByZeroFault DIV with a zero divisor
+	DivideByZeroFault FaultClass = iota
+
+	// UnknownRegisterFault register operand above #x03
+	UnknownRegisterFault
+
+	// UnknownOpcodeFault opcode above #x0F
+	UnknownOpcodeFault
+
+	// IllegalMemoryAccessFault out of bounds main memory access
+	IllegalMemoryAccessFault
+
+	// FetchBeyondEndFault instruction extends past the end of program memory
+	FetchBeyondEndFault
+
+	// ProgramCounterOverflowFault PC advanced past the end of program memory
+	ProgramCounterOverflowFault
+
+	numberOfFaultClasses
+)
+
+var faultClassNames = [numberOfFaultClasses]string{
+	"divide-by-zero",
+	"unknown-register",
+	"unknown-opcode",
+	"illegal-memory-access",
+	"fetch-beyond-end",
+	"pc-overflow",
+}
+
+var faultClassErrors = [numberOfFaultClasses]error{
+	ErrDivideByZero,
+	ErrUnknownRegister,
+	ErrUnknownOpcode,
+	memory.ErrIllegalMemoryAccess,
+	ErrFetchBeyondEnd,
+	ErrProgramCounterOverflow,
+}
+
+func (class FaultClass) String() string {
+	if class < 0 || class >= numberOfFaultClasses {
+		return "???"
+	}
+
+	return faultClassNames[class]
+}
+
+// ParseFaultClass converts a name such as unknown-register to a fault class
+func ParseFaultClass(name string) (FaultClass, error) {
+	for class, className := range faultClassNames {
+		if className == name {
+			return FaultClass(class), nil
+		}
+	}
+
+	return 0, ErrUnknownFaultClass
+}
+
+// FaultClassOf returns the class of fault an execution error belongs to,
+// false when it belongs to none and always aborts
+func FaultClassOf(err error) (FaultClass, bool) {
+	for class, classErr := range faultClassErrors {
+		if errors.Is(err, classErr) {
+			return FaultClass(class), true
+		}
+	}
+
+	return 0, false
+}
+
+// FaultPolicy selects what happens when a fault occurs. All of them set E
+type FaultPolicy int
+
+const (
+	// Abort stops execution with an error
+	Abort FaultPolicy = iota
+
+	// SetE continues with the instruction following the faulting one
+	SetE
+
+	// Trap continues at Configuration.FaultHandler
+	Trap
+)
+
+var faultPolicyNames = map[FaultPolicy]string{
+	Abort: "abort",
+	SetE:  "set-e",
+	Trap:  "trap",
+}
+
+func (policy FaultPolicy) String() string {
+	name, ok := faultPolicyNames[policy]
+	if !ok {
+		return "???"
+	}
+
+	return name
+}
+
+// ParseFaultPolicy converts abort, set-e or trap to a fault policy
+func ParseFaultPolicy(name string) (FaultPolicy, error) {
+	for policy, policyName := range faultPolicyNames {
+		if policyName == name {
+			return policy, nil
+		}
+	}
+
+	return Abort, ErrUnknownFaultPolicy
+}
+
+// FaultPolicies holds a FaultPolicy for each FaultClass
+type FaultPolicies [numberOfFaultClasses]FaultPolicy
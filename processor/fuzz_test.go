@@ -0,0 +1,202 @@
+package processor
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tmornini/rigetti-computing/memory"
+)
+
+// specPrograms are the programs of the spec fixtures
+func specPrograms(f *testing.F) [][]byte {
+	files, err := filepath.Glob(filepath.Join("..", "spec-*", "*"))
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	var programs [][]byte
+
+	for _, file := range files {
+		contents, err := ioutil.ReadFile(file)
+		if err != nil {
+			f.Fatal(err)
+		}
+
+		var program []byte
+
+		switch filepath.Ext(file) {
+		case ".asm":
+			program, err = Assemble(bytes.NewReader(contents))
+		case ".hex", ".ihex", ".srec":
+			program, err = memory.ReadImage(bytes.NewReader(contents))
+		default:
+			continue
+		}
+
+		if err == nil {
+			programs = append(programs, program)
+		}
+	}
+
+	return programs
+}
+
+// programMemory is data truncated or padded with 0 to 256 bytes
+func programMemory(data []byte) *memory.ReadOnly {
+	programMemory := &memory.ReadOnly{}
+	copy(programMemory[:], data)
+
+	return programMemory
+}
+
+// isSentinel is whether err is one of the documented errors
+func isSentinel(err error, sentinels ...error) bool {
+	for _, sentinel := range sentinels {
+		if errors.Is(err, sentinel) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func FuzzDecode(f *testing.F) {
+	for _, program := range specPrograms(f) {
+		for _, address := range []byte{0x00, 0x03, byte(len(program) - 1), 0xfd, 0xff} {
+			f.Add(program, address, byte(Fault))
+		}
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte, address byte, fetchBeyondEnd byte) {
+		configuration := DefaultConfiguration
+		configuration.FetchBeyondEnd = EndOfProgramMemoryPolicy(fetchBeyondEnd % 3)
+
+		p := New(NormalInstructionSet, configuration, programMemory(data), &memory.ReadWrite{})
+
+		decoded, err := p.decodeInstruction(memory.Address(address))
+
+		cached := p.decodedInstructions[address]
+		if !reflect.DeepEqual(decoded, cached.instruction) || !reflect.DeepEqual(err, cached.err) {
+			t.Fatalf("not deterministic: %+v %v then %+v %v", cached.instruction, cached.err, decoded, err)
+		}
+
+		if err != nil && !isSentinel(err, ErrFetchBeyondEnd) {
+			t.Fatalf("undocumented error: %v", err)
+		}
+
+		disassembled := Disassemble(p.programMemory, memory.Address(address))
+
+		if disassembled.Err != nil && !isSentinel(
+			disassembled.Err,
+			ErrUnknownOpcode,
+			ErrUnknownRegister,
+			ErrFetchBeyondEnd,
+		) {
+			t.Fatalf("undocumented error: %v", disassembled.Err)
+		}
+
+		assembled, err := Assemble(strings.NewReader(disassembled.Assembly))
+		if err != nil || !bytes.Equal(assembled, disassembled.Bytes) {
+			t.Fatalf(
+				"%s assembles to %x, %v, not %x",
+				disassembled.Assembly,
+				assembled,
+				err,
+				disassembled.Bytes,
+			)
+		}
+	})
+}
+
+// fuzzedConfiguration selects each end of program memory policy and fault
+// policy from policies, with up to 1,000 steps
+func fuzzedConfiguration(policies uint64, faultHandler byte) Configuration {
+	configuration := Configuration{
+		FetchBeyondEnd:         EndOfProgramMemoryPolicy(policies % 3),
+		ProgramCounterOverflow: EndOfProgramMemoryPolicy(policies / 3 % 3),
+		FaultHandler:           memory.Address(faultHandler),
+		MaxSteps:               1000,
+		TraceOutput:            ioutil.Discard,
+	}
+
+	policies /= 9
+
+	for class := range configuration.FaultPolicies {
+		configuration.FaultPolicies[class] = FaultPolicy(policies % 3)
+		policies /= 3
+	}
+
+	return configuration
+}
+
+// run the program, returning everything that may differ between runs
+func run(
+	instructionSet InstructionSet,
+	configuration Configuration,
+	programMemory *memory.ReadOnly,
+	mainMemory memory.ReadWrite,
+) (string, error) {
+	var output bytes.Buffer
+	configuration.Output = &output
+
+	p := New(instructionSet, configuration, programMemory, &mainMemory)
+
+	err := p.Run()
+
+	return p.String() + "\n" + p.Registers().String() + "\n" + output.String(), err
+}
+
+func FuzzRun(f *testing.F) {
+	for _, program := range specPrograms(f) {
+		f.Add(program, []byte{}, uint64(3), byte(0), false)
+		f.Add(program, []byte{0x52, 0x43, 0x43}, uint64(0x7fff), byte(0x10), true)
+	}
+
+	f.Fuzz(func(
+		t *testing.T,
+		program []byte,
+		mainMemoryData []byte,
+		policies uint64,
+		faultHandler byte,
+		debug bool,
+	) {
+		instructionSet := NormalInstructionSet
+		if debug {
+			instructionSet = DebugInstructionSet
+		}
+
+		configuration := fuzzedConfiguration(policies, faultHandler)
+
+		var mainMemory memory.ReadWrite
+		copy(mainMemory[:], mainMemoryData)
+
+		state, err := run(instructionSet, configuration, programMemory(program), mainMemory)
+		again, againErr := run(instructionSet, configuration, programMemory(program), mainMemory)
+
+		if state != again || !reflect.DeepEqual(err, againErr) {
+			t.Fatalf("not deterministic: %v\n%s\nthen %v\n%s", err, state, againErr, again)
+		}
+
+		if err == nil || errors.Is(err, ErrStepLimitExceeded) {
+			return
+		}
+
+		var executionError *ExecutionError
+		if !errors.As(err, &executionError) || !isSentinel(
+			err,
+			ErrDivideByZero,
+			ErrUnknownRegister,
+			ErrUnknownOpcode,
+			memory.ErrIllegalMemoryAccess,
+			ErrFetchBeyondEnd,
+			ErrProgramCounterOverflow,
+		) {
+			t.Fatalf("undocumented error: %v", err)
+		}
+	})
+}
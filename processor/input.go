@@ -0,0 +1,56 @@
+package processor
+
+import "fmt"
+
+// Input is a register, or a main memory byte, preset before a program runs,
+// so that it can be run, or explored, for each of its values
+type Input struct {
+	Memory bool
+	Index  byte // register 0-3 for X-W, or main memory address
+}
+
+func (input Input) String() string {
+	switch {
+	case input.Memory:
+		return fmt.Sprintf("[%02x]", input.Index)
+	case !input.Valid():
+		return fmt.Sprintf("register %02x", input.Index)
+	}
+
+	return registerNames[input.Index]
+}
+
+// Valid is false for a register input other than X-W. Every main memory
+// address is valid
+func (input Input) Valid() bool {
+	return input.Memory || input.Index < 4
+}
+
+// Preset the inputs to their values, usually before Run. Main memory
+// inputs are part of the initial image DiffDump compares with. Invalid
+// inputs, or too few values, return an *InputError, presetting nothing
+func (p *Processor) Preset(inputs []Input, values []byte) error {
+	if len(values) != len(inputs) {
+		return &InputError{
+			Inputs: inputs,
+			Reason: fmt.Sprintf("%d values for %d inputs", len(values), len(inputs)),
+		}
+	}
+
+	for _, input := range inputs {
+		if !input.Valid() {
+			return &InputError{Inputs: inputs, Reason: input.String() + " is not X-W"}
+		}
+	}
+
+	for index, input := range inputs {
+		if input.Memory {
+			p.mainMemory[input.Index] = values[index]
+			p.initialMainMemory[input.Index] = values[index]
+		} else {
+			p.registers[input.Index] = values[index]
+		}
+	}
+
+	return nil
+}
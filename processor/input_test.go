@@ -0,0 +1,44 @@
+package processor
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/tmornini/rigetti-computing/memory"
+)
+
+// TestPreset sets registers and main memory, refusing registers other
+// than X-W and a value count that differs from the input count
+func TestPreset(t *testing.T) {
+	p := New(NormalInstructionSet, DefaultConfiguration, &memory.ReadOnly{HLT}, &memory.ReadWrite{})
+
+	err := p.Preset([]Input{{Index: 2}, {Memory: true, Index: 0x10}}, []byte{0x42, 0x99})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if p.Registers().Z != 0x42 || p.MainMemory()[0x10] != 0x99 || p.initialMainMemory[0x10] != 0x99 {
+		t.Errorf("got Z %02x and [10] %02x, want 42 and 99", p.Registers().Z, p.MainMemory()[0x10])
+	}
+
+	for _, test := range []struct {
+		inputs []Input
+		values []byte
+	}{
+		{[]Input{{Index: 0}, {Index: 7}}, []byte{0x01, 0x02}},
+		{[]Input{{Index: 0}}, nil},
+	} {
+		p := New(NormalInstructionSet, DefaultConfiguration, &memory.ReadOnly{HLT}, &memory.ReadWrite{})
+
+		err := p.Preset(test.inputs, test.values)
+
+		var inputError *InputError
+		if !errors.As(err, &inputError) || !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("%v: got %v, want an *InputError", test.inputs, err)
+		}
+
+		if p.Registers().X != 0 {
+			t.Errorf("%v: X is %02x, want nothing preset", test.inputs, p.Registers().X)
+		}
+	}
+}
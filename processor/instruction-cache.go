@@ -0,0 +1,49 @@
+package processor
+
+import "github.com/tmornini/rigetti-computing/memory"
+
+// program memory is immutable, so the instruction starting at each of its
+// 256 addresses only needs to be decoded once
+type decodedInstruction struct {
+	instruction instruction
+	err         error
+}
+
+func (p *Processor) decodeInstructions() *[256]decodedInstruction {
+	decodedInstructions := &[256]decodedInstruction{}
+
+	for address := range decodedInstructions {
+		instruction, err := p.decodeInstruction(memory.Address(address))
+
+		decodedInstructions[address] = decodedInstruction{
+			instruction: instruction,
+			err:         err,
+		}
+	}
+
+	return decodedInstructions
+}
+
+func (p *Processor) fetchInstruction() (instruction, error) {
+	var i instruction
+	var err error
+
+	if p.decodedInstructions == nil {
+		i, err = p.decodeInstruction(p.programCounter)
+	} else {
+		decoded := &p.decodedInstructions[p.programCounter]
+		i, err = decoded.instruction, decoded.err
+	}
+
+	if p.note != "" {
+		if i.note != "" {
+			i.note = p.note + ", " + i.note
+		} else {
+			i.note = p.note
+		}
+
+		p.note = ""
+	}
+
+	return i, err
+}
@@ -1,195 +1,119 @@
 package processor
 
-import (
-	"fmt"
-	"os"
-)
+import "fmt"
 
 func nopDebug(p *Processor, i instruction) (programCounterAdvance int, err error) {
-	fmt.Fprintln(
-		os.Stderr,
-		p.registersAndFlagsAsString()+
-			"   |   "+
-			i.noParameterInstructionString(),
-	)
+	p.trace(i, i.noParameterInstructionString())
 
 	return nop(p, i)
 }
 
 func addDebug(p *Processor, i instruction) (programCounterAdvance int, err error) {
-	fmt.Fprintln(
-		os.Stderr,
-		p.registersAndFlagsAsString()+
-			"   |   "+
-			i.threeRegisterInstructionString(),
-	)
+	p.trace(i, i.threeRegisterInstructionString())
 
 	return add(p, i)
 }
 
 func subDebug(p *Processor, i instruction) (programCounterAdvance int, err error) {
-	fmt.Fprintln(
-		os.Stderr,
-		p.registersAndFlagsAsString()+
-			"   |   "+
-			i.threeRegisterInstructionString(),
-	)
+	p.trace(i, i.threeRegisterInstructionString())
 
 	return sub(p, i)
 }
 
 func mulDebug(p *Processor, i instruction) (programCounterAdvance int, err error) {
-	fmt.Fprintln(
-		os.Stderr,
-		p.registersAndFlagsAsString()+
-			"   |   "+
-			i.threeRegisterInstructionString(),
-	)
+	p.trace(i, i.threeRegisterInstructionString())
 
 	return mul(p, i)
 }
 
 func divDebug(p *Processor, i instruction) (programCounterAdvance int, err error) {
-	fmt.Fprintln(
-		os.Stderr,
-		p.registersAndFlagsAsString()+
-			"   |   "+
-			i.threeRegisterInstructionString(),
-	)
+	p.trace(i, i.threeRegisterInstructionString())
 
 	return div(p, i)
 }
 
 func ldmDebug(p *Processor, i instruction) (programCounterAdvance int, err error) {
-	fmt.Fprintln(
-		os.Stderr,
-		p.registersAndFlagsAsString()+
-			"   |   "+
-			i.twoRegisterInstructionString(),
-	)
+	p.trace(i, i.twoRegisterInstructionString())
 
 	return ldm(p, i)
 }
 
 func ldiDebug(p *Processor, i instruction) (programCounterAdvance int, err error) {
-	fmt.Fprintln(
-		os.Stderr,
-		p.registersAndFlagsAsString()+
-			"   |   "+
-			i.oneImmediateOneRegisterInstructionString(),
-	)
+	p.trace(i, i.oneImmediateOneRegisterInstructionString())
 
 	return ldi(p, i)
 }
 
 func strDebug(p *Processor, i instruction) (programCounterAdvance int, err error) {
-	fmt.Fprintln(
-		os.Stderr,
-		p.registersAndFlagsAsString()+
-			"   |   "+
-			i.twoRegisterInstructionString(),
-	)
+	p.trace(i, i.twoRegisterInstructionString())
 
 	return str(p, i)
 }
 
 func swpDebug(p *Processor, i instruction) (programCounterAdvance int, err error) {
-	fmt.Fprintln(
-		os.Stderr,
-		p.registersAndFlagsAsString()+
-			"   |   "+
-			i.twoRegisterInstructionString(),
-	)
+	p.trace(i, i.twoRegisterInstructionString())
 
 	return swp(p, i)
 }
 
 func eqlDebug(p *Processor, i instruction) (programCounterAdvance int, err error) {
-	fmt.Fprintln(
-		os.Stderr,
-		p.registersAndFlagsAsString()+
-			"   |   "+
-			i.twoRegisterInstructionString(),
-	)
+	p.trace(i, i.twoRegisterInstructionString())
 
 	return eql(p, i)
 }
 
 func nqlDebug(p *Processor, i instruction) (programCounterAdvance int, err error) {
-	fmt.Fprintln(
-		os.Stderr,
-		p.registersAndFlagsAsString()+
-			"   |   "+
-			i.twoRegisterInstructionString(),
-	)
+	p.trace(i, i.twoRegisterInstructionString())
 
 	return nql(p, i)
 }
 
 func jmpDebug(p *Processor, i instruction) (programCounterAdvance int, err error) {
-	fmt.Fprintln(
-		os.Stderr,
-		p.registersAndFlagsAsString()+
-			"   |   "+
-			i.oneImmediateInstructionString(),
-	)
+	p.trace(i, i.oneImmediateInstructionString())
 
 	return jmp(p, i)
 }
 
 func jmcDebug(p *Processor, i instruction) (programCounterAdvance int, err error) {
-	fmt.Fprintln(
-		os.Stderr,
-		p.registersAndFlagsAsString()+
-			"   |   "+
-			i.oneImmediateInstructionString(),
-	)
+	p.trace(i, i.oneImmediateInstructionString())
 
 	return jmc(p, i)
 }
 
 func jmeDebug(p *Processor, i instruction) (programCounterAdvance int, err error) {
-	fmt.Fprintln(
-		os.Stderr,
-		p.registersAndFlagsAsString()+
-			"   |   "+
-			i.oneImmediateInstructionString(),
-	)
+	p.trace(i, i.oneImmediateInstructionString())
 
 	return jme(p, i)
 }
 
 func prnDebug(p *Processor, i instruction) (programCounterAdvance int, err error) {
-	fmt.Fprintln(
-		os.Stderr,
-		p.registersAndFlagsAsString()+
-			"   |   "+
-			i.oneRegisterInstructionString(),
-	)
+	p.trace(i, i.oneRegisterInstructionString())
 
 	return prn(p, i)
 }
 
 func hltDebug(p *Processor, i instruction) (programCounterAdvance int, err error) {
-	fmt.Fprintln(
-		os.Stderr,
-		p.registersAndFlagsAsString()+
-			"   |   "+
-			i.noParameterInstructionString(),
-	)
+	p.trace(i, i.noParameterInstructionString())
 
 	return hlt(p, i)
 }
 
 func unknownDebug(p *Processor, i instruction) (programCounterAdvance int, err error) {
-	fmt.Fprintln(
-		os.Stderr,
-		p.registersAndFlagsAsString()+"   |   ???",
-	)
+	p.trace(i, "???")
 
 	return unknown(p, i)
 }
 
+func (p *Processor) trace(i instruction, instructionString string) {
+	line := p.registersAndFlagsAsString() + "   |   " + instructionString
+
+	if i.note != "" {
+		line += "   |   " + i.note
+	}
+
+	fmt.Fprintln(p.traceOutput, line)
+}
+
 func (i instruction) Name() string {
 	return opcodeNames[i.opcode]
 }
@@ -316,7 +240,7 @@ var registerNames = [256]string{
 }
 
 // DebugInstructionSet wraps InstructionSetNormal to provide debug output
-var DebugInstructionSet = instructionSet{
+var DebugInstructionSet = InstructionSet{
 	nopDebug, addDebug, subDebug, mulDebug,
 	divDebug, ldmDebug, ldiDebug, strDebug,
 	swpDebug, eqlDebug, nqlDebug, jmpDebug,
@@ -383,3 +307,19 @@ var DebugInstructionSet = instructionSet{
 	unknownDebug, unknownDebug, unknownDebug, unknownDebug,
 	unknownDebug, unknownDebug, unknownDebug, unknownDebug,
 }
+
+// TracedInstructionSet uses DebugInstructionSet for the opcodes traced and
+// NormalInstructionSet for the rest, e.g. to leave NOPs out of traces
+func TracedInstructionSet(traced [256]bool) InstructionSet {
+	var instructionSet InstructionSet
+
+	for opcode := range instructionSet {
+		if traced[opcode] {
+			instructionSet[opcode] = DebugInstructionSet[opcode]
+		} else {
+			instructionSet[opcode] = NormalInstructionSet[opcode]
+		}
+	}
+
+	return instructionSet
+}
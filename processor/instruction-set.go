@@ -6,15 +6,22 @@ import (
 	"github.com/tmornini/rigetti-computing/memory"
 )
 
-type instructionSet [256]instructionFunc
+// InstructionSet maps each opcode to the function that executes it
+type InstructionSet [256]instructionFunc
+
+// IsConditionalJump reports whether opcode is JMC or JME
+func IsConditionalJump(opcode byte) bool {
+	return opcode == JMC || opcode == JME
+}
 
 func nop(p *Processor, i instruction) (programCounterAdvance int, err error) {
 	return 1, nil
 }
 
 func add(p *Processor, i instruction) (programCounterAdvance int, err error) {
-	if unknownRegister(i.r1) || unknownRegister(i.r2) || unknownRegister(i.r3) {
-		return 4, ErrUnknownRegister
+	err = unknownRegisterError(i.r1, i.r2, i.r3)
+	if err != nil {
+		return 4, err
 	}
 
 	p.registers[i.r3] = p.registers[i.r1] + p.registers[i.r2]
@@ -23,8 +30,9 @@ func add(p *Processor, i instruction) (programCounterAdvance int, err error) {
 }
 
 func sub(p *Processor, i instruction) (programCounterAdvance int, err error) {
-	if unknownRegister(i.r1) || unknownRegister(i.r2) || unknownRegister(i.r3) {
-		return 4, ErrUnknownRegister
+	err = unknownRegisterError(i.r1, i.r2, i.r3)
+	if err != nil {
+		return 4, err
 	}
 
 	p.registers[i.r3] = p.registers[i.r1] - p.registers[i.r2]
@@ -33,8 +41,9 @@ func sub(p *Processor, i instruction) (programCounterAdvance int, err error) {
 }
 
 func mul(p *Processor, i instruction) (programCounterAdvance int, err error) {
-	if unknownRegister(i.r1) || unknownRegister(i.r2) || unknownRegister(i.r3) {
-		return 4, ErrUnknownRegister
+	err = unknownRegisterError(i.r1, i.r2, i.r3)
+	if err != nil {
+		return 4, err
 	}
 
 	p.registers[i.r3] = p.registers[i.r1] * p.registers[i.r2]
@@ -43,8 +52,9 @@ func mul(p *Processor, i instruction) (programCounterAdvance int, err error) {
 }
 
 func div(p *Processor, i instruction) (programCounterAdvance int, err error) {
-	if unknownRegister(i.r1) || unknownRegister(i.r2) || unknownRegister(i.r3) {
-		return 4, ErrUnknownRegister
+	err = unknownRegisterError(i.r1, i.r2, i.r3)
+	if err != nil {
+		return 4, err
 	}
 
 	if p.registers[i.r2] == 0 {
@@ -60,8 +70,9 @@ func div(p *Processor, i instruction) (programCounterAdvance int, err error) {
 }
 
 func ldm(p *Processor, i instruction) (programCounterAdvance int, err error) {
-	if unknownRegister(i.r1) || unknownRegister(i.r2) {
-		return 3, ErrUnknownRegister
+	err = unknownRegisterError(i.r1, i.r2)
+	if err != nil {
+		return 3, err
 	}
 
 	address := memory.Address(p.registers[i.r1])
@@ -77,8 +88,9 @@ func ldm(p *Processor, i instruction) (programCounterAdvance int, err error) {
 }
 
 func ldi(p *Processor, i instruction) (programCounterAdvance int, err error) {
-	if unknownRegister(i.r1) {
-		return 3, ErrUnknownRegister
+	err = unknownRegisterError(i.r1)
+	if err != nil {
+		return 3, err
 	}
 
 	p.registers[i.r1] = i.imm
@@ -87,8 +99,9 @@ func ldi(p *Processor, i instruction) (programCounterAdvance int, err error) {
 }
 
 func str(p *Processor, i instruction) (programCounterAdvance int, err error) {
-	if unknownRegister(i.r1) || unknownRegister(i.r2) {
-		return 3, ErrUnknownRegister
+	err = unknownRegisterError(i.r1, i.r2)
+	if err != nil {
+		return 3, err
 	}
 
 	r2Address := memory.Address(p.registers[i.r2])
@@ -102,8 +115,9 @@ func str(p *Processor, i instruction) (programCounterAdvance int, err error) {
 }
 
 func swp(p *Processor, i instruction) (programCounterAdvance int, err error) {
-	if unknownRegister(i.r1) || unknownRegister(i.r2) {
-		return 3, ErrUnknownRegister
+	err = unknownRegisterError(i.r1, i.r2)
+	if err != nil {
+		return 3, err
 	}
 
 	p.registers[i.r1], p.registers[i.r2] = p.registers[i.r2], p.registers[i.r1]
@@ -112,8 +126,9 @@ func swp(p *Processor, i instruction) (programCounterAdvance int, err error) {
 }
 
 func eql(p *Processor, i instruction) (programCounterAdvance int, err error) {
-	if unknownRegister(i.r1) || unknownRegister(i.r2) {
-		return 3, ErrUnknownRegister
+	err = unknownRegisterError(i.r1, i.r2)
+	if err != nil {
+		return 3, err
 	}
 
 	p.flags[c] = p.registers[i.r1] == p.registers[i.r2]
@@ -122,8 +137,9 @@ func eql(p *Processor, i instruction) (programCounterAdvance int, err error) {
 }
 
 func nql(p *Processor, i instruction) (programCounterAdvance int, err error) {
-	if unknownRegister(i.r1) || unknownRegister(i.r2) {
-		return 3, ErrUnknownRegister
+	err = unknownRegisterError(i.r1, i.r2)
+	if err != nil {
+		return 3, err
 	}
 
 	p.flags[c] = p.registers[i.r1] != p.registers[i.r2]
@@ -158,11 +174,13 @@ func jme(p *Processor, i instruction) (programCounterAdvance int, err error) {
 }
 
 func prn(p *Processor, i instruction) (programCounterAdvance int, err error) {
-	if unknownRegister(i.r1) {
-		return 2, ErrUnknownRegister
+	err = unknownRegisterError(i.r1)
+	if err != nil {
+		return 2, err
 	}
 
-	fmt.Print(
+	fmt.Fprint(
+		p.output,
 		string(
 			p.registers[i.r1],
 		),
@@ -176,11 +194,11 @@ func hlt(p *Processor, i instruction) (programCounterAdvance int, err error) {
 }
 
 func unknown(p *Processor, i instruction) (programCounterAdvance int, err error) {
-	return 0, ErrUnknownOpcode
+	return 1, ErrUnknownOpcode
 }
 
 // NormalInstructionSet is the non-debugging instruction set
-var NormalInstructionSet = instructionSet{
+var NormalInstructionSet = InstructionSet{
 	nop, add, sub, mul, div, ldm, ldi, str,
 	swp, eql, nql, jmp, jmc, jme, prn, hlt,
 
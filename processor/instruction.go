@@ -8,6 +8,8 @@ type instruction struct {
 	r3 byte
 
 	imm byte
+
+	note string // why the processor deviated from normal execution
 }
 
 type instructionFunc func(*Processor, instruction) (programCounterAdvance int, err error)
@@ -0,0 +1,93 @@
+package processor
+
+import "github.com/tmornini/rigetti-computing/memory"
+
+// Observer is told about every instruction Step processes, e.g. to profile
+// a program or measure its coverage
+type Observer interface {
+	Observe(event Event)
+}
+
+// Event describes one instruction processed by Step
+type Event struct {
+	Step           uint64         // 1 for the first instruction
+	ProgramCounter memory.Address // where the instruction is
+	Opcode         byte
+	Next           memory.Address // where execution continues
+
+	// Jumped is true for JMP, and for JMC or JME with their flag set
+	Jumped bool
+
+	// Conditional is true for JMC and JME, whether they jumped or not
+	Conditional bool
+
+	// MainMemoryRead is true for LDM, MainMemoryWrite for STR
+	MainMemoryRead    bool
+	MainMemoryWrite   bool
+	MainMemoryAddress memory.Address
+
+	Halted bool
+	Err    error // not continuable
+}
+
+// observedStep is Step's step when Configuration.Observers is not empty.
+// Memory accesses and jumps are determined before the instruction executes,
+// as executing it can change the registers and flags they depend on
+func (p *Processor) observedStep() (halted bool, err error) {
+	event := Event{
+		Step:           p.steps + 1,
+		ProgramCounter: p.programCounter,
+		Opcode:         p.programMemory[p.programCounter],
+	}
+
+	decoded := p.decodedInstructionAt(p.programCounter)
+
+	if decoded.err == nil {
+		i := decoded.instruction
+
+		switch i.opcode {
+		case LDM:
+			if unknownRegisterError(i.r1, i.r2) == nil {
+				event.MainMemoryRead = true
+				event.MainMemoryAddress = memory.Address(p.registers[i.r1])
+			}
+		case STR:
+			if unknownRegisterError(i.r1, i.r2) == nil {
+				event.MainMemoryWrite = true
+				event.MainMemoryAddress = memory.Address(p.registers[i.r2])
+			}
+		case JMP:
+			event.Jumped = true
+		case JMC:
+			event.Jumped = p.flags[c]
+		case JME:
+			event.Jumped = p.flags[e]
+		}
+
+		event.Conditional = IsConditionalJump(i.opcode)
+	}
+
+	halted, err = p.step()
+
+	event.Next = p.programCounter
+	event.Halted = halted
+	event.Err = err
+
+	for _, observer := range p.configuration.Observers {
+		observer.Observe(event)
+	}
+
+	return halted, err
+}
+
+func (p *Processor) decodedInstructionAt(
+	address memory.Address,
+) decodedInstruction {
+	if p.decodedInstructions == nil {
+		i, err := p.decodeInstruction(address)
+
+		return decodedInstruction{instruction: i, err: err}
+	}
+
+	return p.decodedInstructions[address]
+}
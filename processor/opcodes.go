@@ -0,0 +1,23 @@
+package processor
+
+// Opcodes of the instruction set, for packages that execute or generate
+// instructions rather than repeating their numbers. Opcodes above HLT are
+// unknown
+const (
+	NOP = iota
+	ADD
+	SUB
+	MUL
+	DIV
+	LDM
+	LDI
+	STR
+	SWP
+	EQL
+	NQL
+	JMP
+	JMC
+	JME
+	PRN
+	HLT
+)
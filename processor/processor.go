@@ -1,113 +1,381 @@
 package processor
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"time"
 
 	"github.com/tmornini/rigetti-computing/memory"
 )
 
 // Processor represents the Rigetti Classical Computer
 type Processor struct {
-	instructionSet instructionSet
+	instructionSet InstructionSet
+	configuration  Configuration
 	programMemory  *memory.ReadOnly
 	mainMemory     *memory.ReadWrite
 
+	initialMainMemory memory.ReadWrite // for DiffDump
+
+	decodedInstructions *[256]decodedInstruction // nil decodes every step
+
+	output      io.Writer // PRN destination
+	traceOutput io.Writer // DebugInstructionSet destination
+
 	programCounter memory.Address
 
+	note string // recorded against the next instruction traced
+
+	steps  uint64
+	cycles uint64 // when Configuration.Timing is not nil
+
+	halted bool  // HLT executed
+	err    error // not continuable, returned by every later Step
+
 	registers [4]byte // 0x00-0x03
 
 	flags [6]bool // 0x04-0x05
 }
 
-// Boot create a new processor and make it process
-func Boot(
-	instructionSet instructionSet,
+// New create a new processor, ready to Run
+func New(
+	instructionSet InstructionSet,
+	configuration Configuration,
 	programMemory *memory.ReadOnly,
 	mainMemory *memory.ReadWrite,
-) error {
+) *Processor {
 	p := &Processor{
 		instructionSet: instructionSet,
+		configuration:  configuration,
 		programMemory:  programMemory,
 		mainMemory:     mainMemory,
+
+		initialMainMemory: *mainMemory,
+
+		output:      configuration.Output,
+		traceOutput: configuration.TraceOutput,
 	}
 
-	err := p.process()
-	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
+	if p.output == nil {
+		p.output = os.Stdout
 	}
 
-	fmt.Println(p)
+	if p.traceOutput == nil {
+		p.traceOutput = os.Stderr
+	}
 
-	return err
+	p.decodedInstructions = p.decodeInstructions()
+
+	return p
 }
 
-func (p *Processor) process() error {
+// Run process instructions until HLT or an error that is not continuable
+func (p *Processor) Run() error {
 	for {
-		instruction, err := p.decodeInstruction()
-		if err != nil {
-			p.flags[e] = true
-
+		halted, err := p.Step()
+		if halted || err != nil {
 			return err
 		}
+	}
+}
+
+// Configuration the processor was created with
+func (p *Processor) Configuration() Configuration {
+	return p.configuration
+}
+
+// ProgramMemory the program being processed
+func (p *Processor) ProgramMemory() *memory.ReadOnly {
+	return p.programMemory
+}
+
+// MainMemory the memory LDM, STR and SWP address
+func (p *Processor) MainMemory() *memory.ReadWrite {
+	return p.mainMemory
+}
+
+// Steps number of instructions processed, including any that faulted
+func (p *Processor) Steps() uint64 {
+	return p.steps
+}
+
+// Cycles estimated by Configuration.Timing, 0 when it is nil
+func (p *Processor) Cycles() uint64 {
+	return p.cycles
+}
+
+// Time the cycles would take at the timing's clock frequency, 0 when either
+// is unknown
+func (p *Processor) Time() time.Duration {
+	if p.configuration.Timing == nil {
+		return 0
+	}
+
+	return p.configuration.Timing.Duration(p.cycles)
+}
+
+// Boot create a new processor and make it process
+func Boot(
+	instructionSet InstructionSet,
+	configuration Configuration,
+	programMemory *memory.ReadOnly,
+	mainMemory *memory.ReadWrite,
+) error {
+	p := New(instructionSet, configuration, programMemory, mainMemory)
+
+	err := p.Run()
+
+	fmt.Fprintln(p.output, p)
+
+	return err
+}
+
+// Step process one instruction, reporting whether HLT has been executed.
+// Once Step returns an error that is not continuable it returns it again
+func (p *Processor) Step() (halted bool, err error) {
+	if p.halted || p.err != nil {
+		return p.halted, p.err
+	}
+
+	if p.configuration.MaxSteps != 0 && p.steps >= p.configuration.MaxSteps {
+		p.err = &StepLimitError{Steps: p.steps}
+
+		return false, p.err
+	}
+
+	if len(p.configuration.Observers) > 0 {
+		p.halted, p.err = p.observedStep()
+	} else {
+		p.halted, p.err = p.step()
+	}
+
+	return p.halted, p.err
+}
+
+func (p *Processor) step() (halted bool, err error) {
+	p.steps++
+
+	instruction, err := p.fetchInstruction()
+	if err != nil {
+		p.charge(p.programMemory[p.programCounter], err)
+
+		programCounterAdvance, err := p.fault(err, p.instructionLength())
+		if err != nil {
+			return false, err
+		}
+
+		return p.advance(programCounterAdvance)
+	}
+
+	programCounterAdvance, err := p.execute(instruction)
 
-		programCounterAdvance, err := p.execute(instruction)
+	p.charge(instruction.opcode, err)
+
+	if errors.Is(err, ErrHLTExecuted) {
+		return true, nil
+	}
+
+	if err != nil {
+		programCounterAdvance, err = p.fault(err, programCounterAdvance)
 		if err != nil {
-			if err == ErrHLTExecuted {
-				return nil
-			}
+			return false, err
+		}
+	}
+
+	return p.advance(programCounterAdvance)
+}
 
-			p.flags[e] = true
+// advance the PC past the instruction processed, applying the PC overflow
+// policy, whether it executed or faulted
+func (p *Processor) advance(programCounterAdvance int) (halted bool, err error) {
+	err = p.advanceProgramCounter(programCounterAdvance)
+	if errors.Is(err, ErrHLTExecuted) {
+		return true, nil
+	}
 
-			if errorIsNotContinuable(err) {
-				return err
-			}
+	if err != nil {
+		programCounterAdvance, err = p.fault(err, programCounterAdvance)
+		if err != nil {
+			return false, err
 		}
 
 		p.programCounter += memory.Address(programCounterAdvance)
 	}
+
+	return false, nil
+}
+
+// charge the cycles an instruction costs, when Configuration.Timing is
+// not nil
+func (p *Processor) charge(opcode byte, err error) {
+	if p.configuration.Timing != nil {
+		p.cycles += p.configuration.Timing.cycles(opcode, err)
+	}
+}
+
+// fault sets E and applies the fault policy for err, returning how far to
+// advance the PC if execution continues
+func (p *Processor) fault(
+	err error,
+	programCounterAdvance int,
+) (int, error) {
+	p.flags[e] = true
+
+	class, ok := FaultClassOf(err)
+	if !ok {
+		return 0, p.executionError(err)
+	}
+
+	switch p.configuration.FaultPolicies[class] {
+	case SetE:
+		return programCounterAdvance, nil
+	case Trap:
+		p.programCounter = p.configuration.FaultHandler
+		p.note = class.String() + ": " + Trap.String()
+
+		return 0, nil
+	default:
+		return 0, p.executionError(err)
+	}
 }
 
 func (p *Processor) execute(i instruction) (programCounterAdvance int, err error) {
 	return p.instructionSet[i.opcode](p, i)
 }
 
-func (p *Processor) decodeInstruction() (instruction, error) {
-	opcodeBytes, err := p.programMemory.Read(p.programCounter, 1)
+func (p *Processor) advanceProgramCounter(programCounterAdvance int) error {
+	if int(p.programCounter)+programCounterAdvance < len(p.programMemory) {
+		p.programCounter += memory.Address(programCounterAdvance)
+
+		return nil
+	}
+
+	switch p.configuration.ProgramCounterOverflow {
+	case Wrap:
+		p.programCounter += memory.Address(programCounterAdvance)
+		p.note = "PC overflow: " + Wrap.String()
+
+		return nil
+	case Halt:
+		_, err := p.execute(
+			instruction{
+				opcode: HLT,
+				note:   "PC overflow: " + Halt.String(),
+			},
+		)
+
+		p.charge(HLT, err)
+
+		return err
+	default:
+		return ErrProgramCounterOverflow
+	}
+}
+
+func (p *Processor) decodeInstruction(
+	address memory.Address,
+) (instruction, error) {
+	opcodeBytes, err := p.programMemory.Read(address, 1)
 
 	if err != nil {
 		return instruction{}, err
 	}
 
 	opcode := opcodeBytes[0]
+	parameterLength := opcodeParameterLengths[opcode]
 
-	parameterBytes, err := p.programMemory.Read(
-		p.programCounter+1,
-		opcodeParameterLengths[opcode],
-	)
-	if err != nil {
-		return instruction{}, err
+	note := ""
+
+	if int(address)+1+parameterLength > len(p.programMemory) {
+		switch p.configuration.FetchBeyondEnd {
+		case Wrap:
+			note = "fetch beyond end: " + Wrap.String()
+		case Halt:
+			return instruction{
+				opcode: HLT,
+				note:   "fetch beyond end: " + Halt.String(),
+			}, nil
+		default:
+			return instruction{}, ErrFetchBeyondEnd
+		}
+	}
+
+	parameterBytes := make([]byte, parameterLength)
+
+	for index := range parameterBytes {
+		parameterBytes[index] = p.programMemory[address+1+memory.Address(index)]
 	}
 
-	return opcodeDecodeFuncs[opcode](opcode, parameterBytes)
+	decodedInstruction, err := opcodeDecodeFuncs[opcode](opcode, parameterBytes)
+	decodedInstruction.note = note
+
+	return decodedInstruction, err
+}
+
+func (p *Processor) instructionLength() int {
+	return InstructionLength(p.programMemory[p.programCounter])
+}
+
+// InstructionLength bytes in the instruction an opcode begins, 1 for an
+// unknown opcode
+func InstructionLength(opcode byte) int {
+	return 1 + opcodeParameterLengths[opcode]
 }
 
+func (p *Processor) executionError(err error) error {
+	opcode := p.programMemory[p.programCounter]
+
+	length := p.instructionLength()
+	remaining := len(p.programMemory) - int(p.programCounter)
+
+	if length > remaining && p.configuration.FetchBeyondEnd != Wrap {
+		length = remaining
+	}
+
+	instructionBytes := make([]byte, length)
+
+	for index := range instructionBytes {
+		instructionBytes[index] = p.programMemory[p.programCounter+memory.Address(index)]
+	}
+
+	return &ExecutionError{
+		Err:            err,
+		Step:           p.steps,
+		ProgramCounter: p.programCounter,
+		Opcode:         opcode,
+		Instruction:    instructionBytes,
+	}
+}
+
+// registersAndFlagsAsString includes the cycles, when they are counted
 func (p Processor) registersAndFlagsAsString() string {
+	if p.configuration.Timing == nil {
+		return p.Registers().String()
+	}
+
+	return fmt.Sprintf("%s   CY:%d", p.Registers(), p.cycles)
+}
+
+// timeAsString is a line of the time taken, when the clock frequency is
+// known, for the final state
+func (p Processor) timeAsString() string {
+	if p.configuration.Timing == nil || p.configuration.Timing.ClockFrequency <= 0 {
+		return ""
+	}
+
 	return fmt.Sprintf(
-		"PC:%x   X:%x   Y:%x   Z:%x   W:%x   C:%s   E:%s",
-		[]byte{byte(p.programCounter)},
-		[]byte{p.registers[x]},
-		[]byte{p.registers[y]},
-		[]byte{p.registers[z]},
-		[]byte{p.registers[w]},
-		string(fmt.Sprintf("%t", p.flags[c])[0]),
-		string(fmt.Sprintf("%t", p.flags[e])[0]),
+		"\n%d cycles at %s take %s",
+		p.cycles,
+		FormatClockFrequency(p.configuration.Timing.ClockFrequency),
+		p.Time(),
 	)
 }
 
 func (p Processor) String() string {
 	output := "Registers and Flags:\n"
-	output += p.registersAndFlagsAsString() + "\n\n"
+	output += p.registersAndFlagsAsString() + p.timeAsString() + "\n\n"
 
 	output += "Program memory:\n"
 	output += fmt.Sprintf("%x\n\n", *p.programMemory)
@@ -17,3 +17,14 @@ func unknownRegister(register byte) bool {
 
 	return false
 }
+
+// unknownRegisterError reports the first of r1, r2 and r3 that is unknown
+func unknownRegisterError(registers ...byte) error {
+	for index, register := range registers {
+		if unknownRegister(register) {
+			return &RegisterError{Operand: index + 1, Register: register}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package processor
+
+import (
+	"fmt"
+
+	"github.com/tmornini/rigetti-computing/memory"
+)
+
+// Registers is the state a program can observe: the program counter,
+// registers and flags
+type Registers struct {
+	ProgramCounter memory.Address
+
+	X byte
+	Y byte
+	Z byte
+	W byte
+
+	C bool
+	E bool
+}
+
+// Registers returns the program counter, registers and flags
+func (p *Processor) Registers() Registers {
+	return Registers{
+		ProgramCounter: p.programCounter,
+
+		X: p.registers[x],
+		Y: p.registers[y],
+		Z: p.registers[z],
+		W: p.registers[w],
+
+		C: p.flags[c],
+		E: p.flags[e],
+	}
+}
+
+// SetRegisters presets the program counter, registers and flags, usually
+// before Run
+func (p *Processor) SetRegisters(registers Registers) {
+	p.programCounter = registers.ProgramCounter
+
+	p.registers[x] = registers.X
+	p.registers[y] = registers.Y
+	p.registers[z] = registers.Z
+	p.registers[w] = registers.W
+
+	p.flags[c] = registers.C
+	p.flags[e] = registers.E
+}
+
+func (registers Registers) String() string {
+	return fmt.Sprintf(
+		"PC:%02x   X:%02x   Y:%02x   Z:%02x   W:%02x   C:%s   E:%s",
+		byte(registers.ProgramCounter),
+		registers.X,
+		registers.Y,
+		registers.Z,
+		registers.W,
+		string(fmt.Sprintf("%t", registers.C)[0]),
+		string(fmt.Sprintf("%t", registers.E)[0]),
+	)
+}
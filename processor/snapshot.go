@@ -0,0 +1,318 @@
+package processor
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/tmornini/rigetti-computing/memory"
+)
+
+// SnapshotVersion is written to every snapshot. ReadSnapshot rejects other
+// versions, so the format can change without misreading old files
+const SnapshotVersion = 1
+
+// SnapshotISA names the instruction set architecture snapshots are taken
+// from, so they are not restored into a processor that would misread them
+const SnapshotISA = "rcc"
+
+// Snapshot is the complete state of a processor, written as JSON by Write
+// and read by ReadSnapshot. Policies are written by name, so snapshots
+// stay readable if their constants are renumbered
+type Snapshot struct {
+	Version int    `json:"version"`
+	ISA     string `json:"isa"`
+
+	ProgramCounter byte `json:"pc"`
+
+	X byte `json:"x"`
+	Y byte `json:"y"`
+	Z byte `json:"z"`
+	W byte `json:"w"`
+
+	C bool `json:"c"`
+	E bool `json:"e"`
+
+	Steps  uint64 `json:"steps"`
+	Cycles uint64 `json:"cycles,omitempty"`
+	Halted bool   `json:"halted"`
+	Note   string `json:"note,omitempty"` // for the next instruction traced
+
+	// Error aborted execution, so a restored processor stays stopped
+	Error *SnapshotError `json:"error,omitempty"`
+
+	FetchBeyondEnd         string            `json:"fetchBeyondEnd"`
+	ProgramCounterOverflow string            `json:"pcOverflow"`
+	FaultPolicies          map[string]string `json:"faultPolicies"`
+	FaultHandler           byte              `json:"faultHandler"`
+
+	ProgramMemory     string `json:"programMemory"`
+	MainMemory        string `json:"mainMemory"`
+	InitialMainMemory string `json:"initialMainMemory"` // for DiffDump
+}
+
+// SnapshotError is the ExecutionError that aborted execution. Reaching
+// MaxSteps is not captured, so a restored processor can continue
+type SnapshotError struct {
+	Step           uint64 `json:"step"`
+	ProgramCounter byte   `json:"pc"`
+	Instruction    string `json:"instruction"`     // hex, opcode first
+	Fault          string `json:"fault,omitempty"` // class, if it has one
+	Reason         string `json:"reason"`          // ExecutionError.Err
+}
+
+// NewSnapshotError captures an ExecutionError, nil for any other error
+func NewSnapshotError(err error) *SnapshotError {
+	var executionError *ExecutionError
+	if !errors.As(err, &executionError) {
+		return nil
+	}
+
+	fault := ""
+
+	class, ok := FaultClassOf(executionError.Err)
+	if ok {
+		fault = class.String()
+	}
+
+	return &SnapshotError{
+		Step:           executionError.Step,
+		ProgramCounter: byte(executionError.ProgramCounter),
+		Instruction:    hex.EncodeToString(executionError.Instruction),
+		Fault:          fault,
+		Reason:         executionError.Err.Error(),
+	}
+}
+
+// ExecutionError recreates the ExecutionError, whose Err has the message
+// and sentinel of the original
+func (snapshotError *SnapshotError) ExecutionError() (*ExecutionError, error) {
+	instruction, err := hex.DecodeString(snapshotError.Instruction)
+	if err != nil || len(instruction) == 0 {
+		return nil, fmt.Errorf("%w: error: instruction must be hex", ErrInvalidSnapshot)
+	}
+
+	restored := &restoredError{reason: snapshotError.Reason}
+
+	if snapshotError.Fault != "" {
+		class, err := ParseFaultClass(snapshotError.Fault)
+		if err != nil {
+			return nil, fmt.Errorf("%w: error: %s: %s", ErrInvalidSnapshot, err, snapshotError.Fault)
+		}
+
+		restored.err = faultClassErrors[class]
+	}
+
+	return &ExecutionError{
+		Err:            restored,
+		Step:           snapshotError.Step,
+		ProgramCounter: memory.Address(snapshotError.ProgramCounter),
+		Opcode:         instruction[0],
+		Instruction:    instruction,
+	}, nil
+}
+
+// restoredError reads as the error a snapshot captured
+type restoredError struct {
+	reason string
+	err    error // the fault class's sentinel, nil without one
+}
+
+func (err *restoredError) Error() string {
+	return err.reason
+}
+
+// Unwrap allows errors.Is(err, ErrUnknownRegister) etc.
+func (err *restoredError) Unwrap() error {
+	return err.err
+}
+
+// Snapshot captures the complete state of the processor. Configuration
+// that does not change what the processor computes, such as MaxSteps,
+// Timing and output destinations, is not captured
+func (p *Processor) Snapshot() *Snapshot {
+	faultPolicies := map[string]string{}
+
+	for class, policy := range p.configuration.FaultPolicies {
+		faultPolicies[FaultClass(class).String()] = policy.String()
+	}
+
+	return &Snapshot{
+		Version: SnapshotVersion,
+		ISA:     SnapshotISA,
+
+		ProgramCounter: byte(p.programCounter),
+
+		X: p.registers[x],
+		Y: p.registers[y],
+		Z: p.registers[z],
+		W: p.registers[w],
+
+		C: p.flags[c],
+		E: p.flags[e],
+
+		Steps:  p.steps,
+		Cycles: p.cycles,
+		Halted: p.halted,
+		Note:   p.note,
+		Error:  NewSnapshotError(p.err),
+
+		FetchBeyondEnd:         p.configuration.FetchBeyondEnd.String(),
+		ProgramCounterOverflow: p.configuration.ProgramCounterOverflow.String(),
+		FaultPolicies:          faultPolicies,
+		FaultHandler:           byte(p.configuration.FaultHandler),
+
+		ProgramMemory:     hex.EncodeToString(p.programMemory[:]),
+		MainMemory:        hex.EncodeToString(p.mainMemory[:]),
+		InitialMainMemory: hex.EncodeToString(p.initialMainMemory[:]),
+	}
+}
+
+// Write the snapshot as indented JSON
+func (snapshot *Snapshot) Write(writer io.Writer) error {
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(snapshot)
+}
+
+// ReadSnapshot reads a snapshot written by Write, checking its version and
+// ISA
+func ReadSnapshot(reader io.Reader) (*Snapshot, error) {
+	snapshot := &Snapshot{}
+
+	err := json.NewDecoder(reader).Decode(snapshot)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidSnapshot, err)
+	}
+
+	if snapshot.Version != SnapshotVersion {
+		return nil, fmt.Errorf(
+			"%w: version is %d, expected %d",
+			ErrInvalidSnapshot,
+			snapshot.Version,
+			SnapshotVersion,
+		)
+	}
+
+	if snapshot.ISA != SnapshotISA {
+		return nil, fmt.Errorf(
+			"%w: ISA is %q, expected %q",
+			ErrInvalidSnapshot,
+			snapshot.ISA,
+			SnapshotISA,
+		)
+	}
+
+	return snapshot, nil
+}
+
+// Configuration returns the policies captured by the snapshot, on top of
+// base, which supplies the rest, e.g. DefaultConfiguration
+func (snapshot *Snapshot) Configuration(
+	base Configuration,
+) (Configuration, error) {
+	configuration := base
+
+	var err error
+
+	configuration.FetchBeyondEnd, err = ParseEndOfProgramMemoryPolicy(
+		snapshot.FetchBeyondEnd,
+	)
+	if err != nil {
+		return base, fmt.Errorf("%w: fetchBeyondEnd: %s", ErrInvalidSnapshot, err)
+	}
+
+	configuration.ProgramCounterOverflow, err = ParseEndOfProgramMemoryPolicy(
+		snapshot.ProgramCounterOverflow,
+	)
+	if err != nil {
+		return base, fmt.Errorf("%w: pcOverflow: %s", ErrInvalidSnapshot, err)
+	}
+
+	for className, policyName := range snapshot.FaultPolicies {
+		class, err := ParseFaultClass(className)
+		if err != nil {
+			return base, fmt.Errorf("%w: %s: %s", ErrInvalidSnapshot, err, className)
+		}
+
+		configuration.FaultPolicies[class], err = ParseFaultPolicy(policyName)
+		if err != nil {
+			return base, fmt.Errorf("%w: %s: %s", ErrInvalidSnapshot, className, err)
+		}
+	}
+
+	configuration.FaultHandler = memory.Address(snapshot.FaultHandler)
+
+	return configuration, nil
+}
+
+// Restore creates a processor in the state captured by a snapshot, ready to
+// Run. configuration should usually come from snapshot.Configuration
+func Restore(
+	instructionSet InstructionSet,
+	configuration Configuration,
+	snapshot *Snapshot,
+) (*Processor, error) {
+	programMemory := &memory.ReadOnly{}
+	mainMemory := &memory.ReadWrite{}
+	initialMainMemory := &memory.ReadWrite{}
+
+	memories := []struct {
+		name    string
+		encoded string
+		decoded []byte
+	}{
+		{"programMemory", snapshot.ProgramMemory, programMemory[:]},
+		{"mainMemory", snapshot.MainMemory, mainMemory[:]},
+		{"initialMainMemory", snapshot.InitialMainMemory, initialMainMemory[:]},
+	}
+
+	for _, m := range memories {
+		decoded, err := hex.DecodeString(m.encoded)
+		if err != nil || len(decoded) != len(m.decoded) {
+			return nil, fmt.Errorf(
+				"%w: %s must be %d bytes of hex",
+				ErrInvalidSnapshot,
+				m.name,
+				len(m.decoded),
+			)
+		}
+
+		copy(m.decoded, decoded)
+	}
+
+	p := New(instructionSet, configuration, programMemory, mainMemory)
+
+	p.initialMainMemory = *initialMainMemory
+
+	p.SetRegisters(Registers{
+		ProgramCounter: memory.Address(snapshot.ProgramCounter),
+
+		X: snapshot.X,
+		Y: snapshot.Y,
+		Z: snapshot.Z,
+		W: snapshot.W,
+
+		C: snapshot.C,
+		E: snapshot.E,
+	})
+
+	p.steps = snapshot.Steps
+	p.cycles = snapshot.Cycles
+	p.halted = snapshot.Halted
+	p.note = snapshot.Note
+
+	if snapshot.Error != nil {
+		executionError, err := snapshot.Error.ExecutionError()
+		if err != nil {
+			return nil, err
+		}
+
+		p.err = executionError
+	}
+
+	return p, nil
+}
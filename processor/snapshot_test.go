@@ -0,0 +1,130 @@
+package processor
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"github.com/tmornini/rigetti-computing/memory"
+)
+
+// roundTrip writes a snapshot of p, reads it back and restores it
+func roundTrip(t *testing.T, p *Processor, configuration Configuration) *Processor {
+	t.Helper()
+
+	var written bytes.Buffer
+
+	err := p.Snapshot().Write(&written)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot, err := ReadSnapshot(&written)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	restored, err := Restore(NormalInstructionSet, configuration, snapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return restored
+}
+
+// TestSnapshotAborted restores a processor that aborted, which returns the
+// same error rather than executing the faulting instruction again
+func TestSnapshotAborted(t *testing.T) {
+	programMemory := &memory.ReadOnly{
+		0x06, 0x01, 0x00, //       LDI 0x01 X
+		0x0e, 0x00, //             PRN X
+		0x01, 0x09, 0x00, 0x0f, // ADD 09 00 0f
+	}
+
+	configuration := DefaultConfiguration
+	configuration.Output = ioutil.Discard
+
+	p := New(NormalInstructionSet, configuration, programMemory, &memory.ReadWrite{})
+
+	runErr := p.Run()
+	if !errors.Is(runErr, ErrUnknownRegister) {
+		t.Fatalf("got %v, want %v", runErr, ErrUnknownRegister)
+	}
+
+	restored := roundTrip(t, p, configuration)
+
+	halted, err := restored.Step()
+	if halted || err == nil || err.Error() != runErr.Error() {
+		t.Errorf("step after restoring got %t, %v, want %v", halted, err, runErr)
+	}
+
+	if !errors.Is(err, ErrUnknownRegister) {
+		t.Errorf("restored %v is not %v", err, ErrUnknownRegister)
+	}
+
+	if restored.Steps() != p.Steps() || restored.Registers() != p.Registers() {
+		t.Errorf(
+			"restored steps %d and %v, want %d and %v",
+			restored.Steps(),
+			restored.Registers(),
+			p.Steps(),
+			p.Registers(),
+		)
+	}
+
+	again := roundTrip(t, restored, configuration)
+
+	err = again.Run()
+	if err == nil || err.Error() != runErr.Error() {
+		t.Errorf("restoring twice got %v, want %v", err, runErr)
+	}
+}
+
+// TestSnapshotHalted restores a processor that halted, which stays halted
+func TestSnapshotHalted(t *testing.T) {
+	programMemory := &memory.ReadOnly{
+		0x06, 0x01, 0x00, // LDI 0x01 X
+		0x0f, //             HLT
+	}
+
+	p := New(NormalInstructionSet, DefaultConfiguration, programMemory, &memory.ReadWrite{})
+
+	err := p.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	restored := roundTrip(t, p, DefaultConfiguration)
+
+	halted, err := restored.Step()
+	if !halted || err != nil || restored.Steps() != p.Steps() {
+		t.Errorf("step after restoring got %t, %v and %d steps, want halted after %d", halted, err, restored.Steps(), p.Steps())
+	}
+}
+
+// TestSnapshotStepLimit restores a processor that reached MaxSteps, which
+// continues
+func TestSnapshotStepLimit(t *testing.T) {
+	programMemory := &memory.ReadOnly{
+		0x06, 0x01, 0x00, // LDI 0x01 X
+		0x0f, //             HLT
+	}
+
+	configuration := DefaultConfiguration
+	configuration.MaxSteps = 1
+
+	p := New(NormalInstructionSet, configuration, programMemory, &memory.ReadWrite{})
+
+	err := p.Run()
+	if !errors.Is(err, ErrStepLimitExceeded) {
+		t.Fatalf("got %v, want %v", err, ErrStepLimitExceeded)
+	}
+
+	restored := roundTrip(t, p, DefaultConfiguration)
+
+	err = restored.Run()
+	if err != nil || restored.Steps() != 2 {
+		t.Errorf("got %v after %d steps, want to halt after 2", err, restored.Steps())
+	}
+}
@@ -0,0 +1,218 @@
+package processor
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tmornini/rigetti-computing/memory"
+)
+
+// Timing estimates how long programs would take in hardware. Each
+// instruction processed costs its opcode's cycles, plus MainMemoryRead for
+// LDM or MainMemoryWrite for STR. Instructions that fault, when fetched
+// or executed, cost their opcode's cycles, but not memory accesses that
+// did not happen. The HLT the halt policy executes at the end of program
+// memory costs HLT's cycles
+type Timing struct {
+	Opcodes [256]uint64
+
+	MainMemoryRead  uint64
+	MainMemoryWrite uint64
+
+	// ClockFrequency converts cycles to wall time, in Hz, 0 when unknown
+	ClockFrequency float64
+}
+
+// DefaultTiming costs every opcode 1 cycle, and every main memory access 1
+// more, with no clock frequency
+func DefaultTiming() *Timing {
+	timing := &Timing{MainMemoryRead: 1, MainMemoryWrite: 1}
+
+	for opcode := range timing.Opcodes {
+		timing.Opcodes[opcode] = 1
+	}
+
+	return timing
+}
+
+// cycles an instruction costs, given the error executing it returned
+func (timing *Timing) cycles(opcode byte, err error) uint64 {
+	cycles := timing.Opcodes[opcode]
+
+	if err != nil {
+		return cycles
+	}
+
+	switch opcode {
+	case LDM:
+		cycles += timing.MainMemoryRead
+	case STR:
+		cycles += timing.MainMemoryWrite
+	}
+
+	return cycles
+}
+
+// Duration converts cycles to wall time at ClockFrequency, 0 when it is
+// unknown
+func (timing *Timing) Duration(cycles uint64) time.Duration {
+	if timing.ClockFrequency <= 0 {
+		return 0
+	}
+
+	return time.Duration(float64(cycles) / timing.ClockFrequency * float64(time.Second))
+}
+
+// ParseTiming reads cycle costs on top of DefaultTiming, one per line:
+//
+//	# comments are ignored
+//	default 2       ; every opcode not listed
+//	LDM 3
+//	DIV 8
+//	read 4          ; main memory accesses
+//	write 4
+//	clock 4MHz
+//
+// default applies to every opcode, so it must come before the opcodes it
+// does not apply to
+func ParseTiming(reader io.Reader) (*Timing, error) {
+	timing := DefaultTiming()
+
+	scanner := bufio.NewScanner(reader)
+	lineNumber := 0
+
+	for scanner.Scan() {
+		lineNumber++
+
+		line := scanner.Text()
+
+		for _, comment := range []string{"#", ";"} {
+			if index := strings.Index(line, comment); index >= 0 {
+				line = line[:index]
+			}
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		if len(fields) != 2 {
+			return nil, timingError(lineNumber, "must be a name and a cost")
+		}
+
+		err := timing.set(fields[0], fields[1])
+		if err != nil {
+			return nil, timingError(lineNumber, "%s", err)
+		}
+	}
+
+	err := scanner.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return timing, nil
+}
+
+func (timing *Timing) set(name string, value string) error {
+	if strings.EqualFold(name, "clock") {
+		frequency, err := ParseClockFrequency(value)
+		if err != nil {
+			return err
+		}
+
+		timing.ClockFrequency = frequency
+
+		return nil
+	}
+
+	cycles, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%s is not a decimal number of cycles", value)
+	}
+
+	switch strings.ToLower(name) {
+	case "default":
+		for opcode := range timing.Opcodes {
+			timing.Opcodes[opcode] = cycles
+		}
+	case "read":
+		timing.MainMemoryRead = cycles
+	case "write":
+		timing.MainMemoryWrite = cycles
+	default:
+		opcode, err := ParseOpcode(name)
+		if err != nil {
+			return fmt.Errorf("%s is not an opcode, default, read, write or clock", name)
+		}
+
+		timing.Opcodes[opcode] = cycles
+	}
+
+	return nil
+}
+
+var frequencyUnits = []struct {
+	name  string
+	hertz float64
+}{
+	{"GHz", 1e9},
+	{"MHz", 1e6},
+	{"kHz", 1e3},
+	{"Hz", 1},
+}
+
+// ParseClockFrequency converts a frequency such as 4MHz, 1.5GHz or 32768 to
+// Hz
+func ParseClockFrequency(text string) (float64, error) {
+	number := strings.ToLower(text)
+	multiplier := 1.0
+
+	for _, unit := range frequencyUnits {
+		suffix := strings.ToLower(unit.name)
+
+		if strings.HasSuffix(number, suffix) {
+			number = strings.TrimSuffix(number, suffix)
+			multiplier = unit.hertz
+
+			break
+		}
+	}
+
+	frequency, err := strconv.ParseFloat(number, 64)
+	if err != nil || !(frequency > 0) || math.IsInf(frequency, 0) {
+		return 0, fmt.Errorf("%w: %s", ErrInvalidClockFrequency, text)
+	}
+
+	return frequency * multiplier, nil
+}
+
+// FormatClockFrequency writes Hz in the largest unit that ParseClockFrequency
+// reads, e.g. 4MHz
+func FormatClockFrequency(hertz float64) string {
+	for _, unit := range frequencyUnits {
+		if hertz >= unit.hertz {
+			return strconv.FormatFloat(hertz/unit.hertz, 'g', -1, 64) + unit.name
+		}
+	}
+
+	return strconv.FormatFloat(hertz, 'g', -1, 64) + "Hz"
+}
+
+func timingError(
+	lineNumber int,
+	format string,
+	arguments ...interface{},
+) error {
+	return &memory.LineError{
+		Err:    ErrInvalidTiming,
+		Line:   lineNumber,
+		Reason: fmt.Sprintf(format, arguments...),
+	}
+}
@@ -0,0 +1,219 @@
+package profile
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/tmornini/rigetti-computing/memory"
+	"github.com/tmornini/rigetti-computing/processor"
+)
+
+// WriteListing writes the program disassembled, each instruction annotated
+// with its executions, followed by executions by opcode, loop iterations
+// and main memory accesses. Everything but the disassembly is a comment,
+// so the listing still assembles
+func (profile *Profile) WriteListing(
+	writer io.Writer,
+	programMemory *memory.ReadOnly,
+) error {
+	_, err := fmt.Fprintf(writer, "; %d instructions executed\n\n", profile.Steps)
+	if err != nil {
+		return err
+	}
+
+	disassembled := processor.DisassembleProgram(programMemory)
+
+	err = processor.WriteListing(writer, disassembled, profile.annotation)
+	if err != nil {
+		return err
+	}
+
+	sections := []func() error{
+		func() error {
+			return profile.writeUnlisted(writer, programMemory, disassembled)
+		},
+		func() error { return profile.writeOpcodes(writer) },
+		func() error { return profile.writeLoops(writer) },
+		func() error { return profile.writeMainMemory(writer) },
+	}
+
+	for _, section := range sections {
+		_, err = fmt.Fprintln(writer)
+		if err != nil {
+			return err
+		}
+
+		err = section()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (profile *Profile) annotation(address memory.Address) string {
+	executions := profile.Executions[address]
+
+	if executions == 0 {
+		return "not executed"
+	}
+
+	return fmt.Sprintf("%d %s", executions, profile.percentage(executions))
+}
+
+func (profile *Profile) percentage(executions uint64) string {
+	return fmt.Sprintf("%.1f%%", 100*float64(executions)/float64(profile.Steps))
+}
+
+// writeUnlisted writes executions at addresses the listing skipped, e.g.
+// NOP padding, or the middle of an instruction jumped into. Runs of the same
+// opcode are written as a range, with their total executions
+func (profile *Profile) writeUnlisted(
+	writer io.Writer,
+	programMemory *memory.ReadOnly,
+	disassembled []processor.DisassembledInstruction,
+) error {
+	listed := [256]bool{}
+
+	for _, instruction := range disassembled {
+		listed[instruction.Address] = true
+	}
+
+	_, err := fmt.Fprintln(writer, "; executed outside the listing")
+	if err != nil {
+		return err
+	}
+
+	unlisted := func(address int) bool {
+		return address < len(profile.Executions) &&
+			profile.Executions[address] != 0 &&
+			!listed[address]
+	}
+
+	runs := 0
+
+	for start := 0; start < len(profile.Executions); start++ {
+		if !unlisted(start) {
+			continue
+		}
+
+		end := start
+		executions := profile.Executions[start]
+
+		for unlisted(end+1) && programMemory[end+1] == programMemory[start] {
+			end++
+			executions += profile.Executions[end]
+		}
+
+		addresses := fmt.Sprintf("%02x", start)
+		if end != start {
+			addresses += fmt.Sprintf("-%02x", end)
+		}
+
+		runs++
+
+		_, err = fmt.Fprintf(
+			writer,
+			";   %-5s %-4s %10d %7s\n",
+			addresses,
+			processor.OpcodeName(programMemory[start]),
+			executions,
+			profile.percentage(executions),
+		)
+		if err != nil {
+			return err
+		}
+
+		start = end
+	}
+
+	return writeNoneUnless(writer, runs)
+}
+
+func (profile *Profile) writeOpcodes(writer io.Writer) error {
+	_, err := fmt.Fprintln(writer, "; executions by opcode")
+	if err != nil {
+		return err
+	}
+
+	for opcode, executions := range profile.Opcodes {
+		if executions == 0 {
+			continue
+		}
+
+		_, err = fmt.Fprintf(
+			writer,
+			";   %02x    %-4s %10d %7s\n",
+			opcode,
+			processor.OpcodeName(byte(opcode)),
+			executions,
+			profile.percentage(executions),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (profile *Profile) writeLoops(writer io.Writer) error {
+	_, err := fmt.Fprintln(writer, "; loop iterations")
+	if err != nil {
+		return err
+	}
+
+	loops := profile.SortedLoops()
+
+	for _, loop := range loops {
+		_, err = fmt.Fprintf(
+			writer,
+			";   %02x-%02x %15d\n",
+			loop.Start,
+			loop.End,
+			profile.Loops[loop],
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return writeNoneUnless(writer, len(loops))
+}
+
+func (profile *Profile) writeMainMemory(writer io.Writer) error {
+	_, err := fmt.Fprintln(writer, "; main memory accesses    reads     writes")
+	if err != nil {
+		return err
+	}
+
+	accessed := 0
+
+	for address := range profile.Reads {
+		reads, writes := profile.Reads[address], profile.Writes[address]
+
+		if reads == 0 && writes == 0 {
+			continue
+		}
+
+		accessed++
+
+		_, err = fmt.Fprintf(writer, ";   %02x    %21d %10d\n", address, reads, writes)
+		if err != nil {
+			return err
+		}
+	}
+
+	return writeNoneUnless(writer, accessed)
+}
+
+func writeNoneUnless(writer io.Writer, count int) error {
+	if count > 0 {
+		return nil
+	}
+
+	_, err := fmt.Fprintln(writer, ";   none")
+
+	return err
+}
@@ -0,0 +1,263 @@
+package profile
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+
+	"github.com/tmornini/rigetti-computing/memory"
+	"github.com/tmornini/rigetti-computing/processor"
+)
+
+// pprof's profile.proto field numbers, see
+// https://github.com/google/pprof/blob/main/proto/profile.proto
+const (
+	profileSampleType  = 1
+	profileSample      = 2
+	profileLocation    = 4
+	profileFunction    = 5
+	profileStringTable = 6
+
+	profileDefaultSampleType = 14
+
+	valueTypeType = 1
+	valueTypeUnit = 2
+
+	sampleLocationID = 1
+	sampleValue      = 2
+	sampleLabel      = 3
+
+	labelKey = 1
+	labelStr = 2
+
+	locationID      = 1
+	locationAddress = 3
+	locationLine    = 4
+
+	lineFunctionID = 1
+	lineLine       = 2
+
+	functionID       = 1
+	functionName     = 2
+	functionFilename = 4
+)
+
+// WritePprof writes the profile as a gzipped pprof protocol buffer, for go
+// tool pprof. There is a sample for each instruction executed, valued by
+// its executions and main memory accesses and labelled with its opcode and
+// hex address. Its stack is the loops enclosing it, so flame graphs nest
+// instructions in loops, and loops in the program, named by program
+func (profile *Profile) WritePprof(
+	writer io.Writer,
+	programMemory *memory.ReadOnly,
+	program string,
+) error {
+	encoder := newPprofEncoder(program)
+
+	encoder.valueType("instructions", "count")
+	encoder.valueType("main_memory_accesses", "count")
+	encoder.defaultValueType("instructions")
+
+	programLocation := encoder.location(program, 0, 0)
+
+	loopLocations := map[Loop]uint64{}
+
+	for _, loop := range profile.SortedLoops() {
+		loopLocations[loop] = encoder.location(
+			fmt.Sprintf("loop %02x-%02x", loop.Start, loop.End),
+			uint64(loop.Start),
+			int64(loop.Start),
+		)
+	}
+
+	for address, executions := range profile.Executions {
+		if executions == 0 {
+			continue
+		}
+
+		instruction := processor.Disassemble(
+			programMemory,
+			memory.Address(address),
+		)
+
+		stack := []uint64{
+			encoder.location(
+				fmt.Sprintf("%02x %s", address, instruction.Assembly),
+				uint64(address),
+				int64(address),
+			),
+		}
+
+		for _, loop := range profile.enclosingLoops(memory.Address(address)) {
+			stack = append(stack, loopLocations[loop])
+		}
+
+		stack = append(stack, programLocation)
+
+		encoder.sample(
+			stack,
+			[]uint64{executions, profile.MainMemoryAccesses[address]},
+			processor.OpcodeName(programMemory[address]),
+			fmt.Sprintf("%02x", address),
+		)
+	}
+
+	compressor := gzip.NewWriter(writer)
+
+	_, err := compressor.Write(encoder.encode())
+	if err != nil {
+		return err
+	}
+
+	return compressor.Close()
+}
+
+// pprofEncoder builds a Profile message. Each location has a function of
+// the same name, with the same id
+type pprofEncoder struct {
+	program       string
+	profile       protobuf
+	stringIndexes map[string]int64
+	stringTable   []string
+	ids           uint64
+}
+
+func newPprofEncoder(program string) *pprofEncoder {
+	encoder := &pprofEncoder{
+		program:       program,
+		stringIndexes: map[string]int64{},
+	}
+
+	encoder.stringIndex("") // string_table[0] must be empty
+
+	return encoder
+}
+
+func (encoder *pprofEncoder) stringIndex(text string) int64 {
+	index, ok := encoder.stringIndexes[text]
+	if !ok {
+		index = int64(len(encoder.stringTable))
+		encoder.stringIndexes[text] = index
+		encoder.stringTable = append(encoder.stringTable, text)
+	}
+
+	return index
+}
+
+func (encoder *pprofEncoder) valueType(valueType string, unit string) {
+	message := protobuf{}
+	message.varint(valueTypeType, uint64(encoder.stringIndex(valueType)))
+	message.varint(valueTypeUnit, uint64(encoder.stringIndex(unit)))
+
+	encoder.profile.message(profileSampleType, message)
+}
+
+func (encoder *pprofEncoder) defaultValueType(valueType string) {
+	encoder.profile.varint(
+		profileDefaultSampleType,
+		uint64(encoder.stringIndex(valueType)),
+	)
+}
+
+func (encoder *pprofEncoder) location(
+	name string,
+	address uint64,
+	line int64,
+) uint64 {
+	encoder.ids++
+	id := encoder.ids
+
+	function := protobuf{}
+	function.varint(functionID, id)
+	function.varint(functionName, uint64(encoder.stringIndex(name)))
+	function.varint(functionFilename, uint64(encoder.stringIndex(encoder.program)))
+
+	encoder.profile.message(profileFunction, function)
+
+	locationLineMessage := protobuf{}
+	locationLineMessage.varint(lineFunctionID, id)
+	locationLineMessage.varint(lineLine, uint64(line))
+
+	location := protobuf{}
+	location.varint(locationID, id)
+	location.varint(locationAddress, address)
+	location.message(locationLine, locationLineMessage)
+
+	encoder.profile.message(profileLocation, location)
+
+	return id
+}
+
+func (encoder *pprofEncoder) sample(
+	stack []uint64,
+	values []uint64,
+	opcode string,
+	address string,
+) {
+	opcodeLabel := protobuf{}
+	opcodeLabel.varint(labelKey, uint64(encoder.stringIndex("opcode")))
+	opcodeLabel.varint(labelStr, uint64(encoder.stringIndex(opcode)))
+
+	addressLabel := protobuf{}
+	addressLabel.varint(labelKey, uint64(encoder.stringIndex("address")))
+	addressLabel.varint(labelStr, uint64(encoder.stringIndex(address)))
+
+	sample := protobuf{}
+	sample.packed(sampleLocationID, stack)
+	sample.packed(sampleValue, values)
+	sample.message(sampleLabel, opcodeLabel)
+	sample.message(sampleLabel, addressLabel)
+
+	encoder.profile.message(profileSample, sample)
+}
+
+func (encoder *pprofEncoder) encode() []byte {
+	for _, text := range encoder.stringTable {
+		encoder.profile.bytes(profileStringTable, []byte(text))
+	}
+
+	return encoder.profile
+}
+
+// protobuf is an encoded protocol buffer message, holding only the varint
+// and length delimited wire types pprof needs
+type protobuf []byte
+
+const (
+	varintWireType          = 0
+	lengthDelimitedWireType = 2
+)
+
+func (message *protobuf) uvarint(value uint64) {
+	for value >= 0x80 {
+		*message = append(*message, byte(value)|0x80)
+		value >>= 7
+	}
+
+	*message = append(*message, byte(value))
+}
+
+func (message *protobuf) varint(field int, value uint64) {
+	message.uvarint(uint64(field)<<3 | varintWireType)
+	message.uvarint(value)
+}
+
+func (message *protobuf) bytes(field int, value []byte) {
+	message.uvarint(uint64(field)<<3 | lengthDelimitedWireType)
+	message.uvarint(uint64(len(value)))
+	*message = append(*message, value...)
+}
+
+func (message *protobuf) message(field int, value protobuf) {
+	message.bytes(field, value)
+}
+
+func (message *protobuf) packed(field int, values []uint64) {
+	packed := protobuf{}
+
+	for _, value := range values {
+		packed.uvarint(value)
+	}
+
+	message.bytes(field, packed)
+}
@@ -0,0 +1,272 @@
+package profile
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"io/ioutil"
+	"reflect"
+	"testing"
+)
+
+// field of a decoded protocol buffer message, with its value as a varint
+// or bytes depending on its wire type
+type field struct {
+	number int
+	varint uint64
+	bytes  []byte
+}
+
+// decode a protocol buffer message of varint and length delimited fields
+func decode(t *testing.T, message []byte) []field {
+	t.Helper()
+
+	var fields []field
+
+	for len(message) > 0 {
+		key, length := binary.Uvarint(message)
+		if length <= 0 {
+			t.Fatalf("bad key in %x", message)
+		}
+
+		message = message[length:]
+
+		value, length := binary.Uvarint(message)
+		if length <= 0 {
+			t.Fatalf("bad value in %x", message)
+		}
+
+		message = message[length:]
+
+		f := field{number: int(key >> 3)}
+
+		switch key & 7 {
+		case varintWireType:
+			f.varint = value
+		case lengthDelimitedWireType:
+			if uint64(len(message)) < value {
+				t.Fatalf("%d bytes, want %d", len(message), value)
+			}
+
+			f.bytes = message[:value]
+			message = message[value:]
+		default:
+			t.Fatalf("wire type %d", key&7)
+		}
+
+		fields = append(fields, f)
+	}
+
+	return fields
+}
+
+// unpack a packed repeated varint field
+func unpack(t *testing.T, packed []byte) []uint64 {
+	t.Helper()
+
+	var values []uint64
+
+	for len(packed) > 0 {
+		value, length := binary.Uvarint(packed)
+		if length <= 0 {
+			t.Fatalf("bad varint in %x", packed)
+		}
+
+		values = append(values, value)
+		packed = packed[length:]
+	}
+
+	return values
+}
+
+// value of the first field numbered number
+func value(fields []field, number int) field {
+	for _, f := range fields {
+		if f.number == number {
+			return f
+		}
+	}
+
+	return field{}
+}
+
+// TestProtobuf encodes varints of 1 to 10 bytes, and packed fields
+func TestProtobuf(t *testing.T) {
+	message := protobuf{}
+	message.varint(1, 0)
+	message.varint(2, 300)
+	message.varint(3, 1<<63)
+	message.bytes(4, []byte("RCC"))
+	message.packed(5, []uint64{1, 128, 16384})
+
+	if !bytes.HasPrefix(message, []byte{0x08, 0x00, 0x10, 0xac, 0x02}) {
+		t.Errorf("got %x, want 0800 10ac02 first", []byte(message))
+	}
+
+	fields := decode(t, message)
+
+	if len(fields) != 5 ||
+		fields[1].varint != 300 ||
+		fields[2].varint != 1<<63 ||
+		string(fields[3].bytes) != "RCC" ||
+		!reflect.DeepEqual(unpack(t, fields[4].bytes), []uint64{1, 128, 16384}) {
+		t.Errorf("decoded %+v", fields)
+	}
+}
+
+// TestWritePprof decodes the profile of nested, checking its string table,
+// sample types, locations and the sample of each instruction executed
+func TestWritePprof(t *testing.T) {
+	profile := profiled(t)
+
+	var compressed bytes.Buffer
+
+	err := profile.WritePprof(&compressed, &nested, "nested.hex")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := gzip.NewReader(&compressed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded, err := ioutil.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var strings []string
+	var sampleTypes, samples, locations, functions []field
+	var defaultSampleType uint64
+
+	for _, f := range decode(t, encoded) {
+		switch f.number {
+		case profileStringTable:
+			strings = append(strings, string(f.bytes))
+		case profileSampleType:
+			sampleTypes = append(sampleTypes, f)
+		case profileSample:
+			samples = append(samples, f)
+		case profileLocation:
+			locations = append(locations, f)
+		case profileFunction:
+			functions = append(functions, f)
+		case profileDefaultSampleType:
+			defaultSampleType = f.varint
+		}
+	}
+
+	if len(strings) == 0 || strings[0] != "" {
+		t.Fatalf("string table %q does not begin with an empty string", strings)
+	}
+
+	text := func(index uint64) string {
+		if index >= uint64(len(strings)) {
+			t.Fatalf("string %d is beyond the string table", index)
+		}
+
+		return strings[index]
+	}
+
+	var types []string
+
+	for _, sampleType := range sampleTypes {
+		fields := decode(t, sampleType.bytes)
+		types = append(types, text(value(fields, valueTypeType).varint)+"/"+text(value(fields, valueTypeUnit).varint))
+	}
+
+	if !reflect.DeepEqual(types, []string{"instructions/count", "main_memory_accesses/count"}) ||
+		text(defaultSampleType) != "instructions" {
+		t.Errorf("got sample types %v, default %s", types, text(defaultSampleType))
+	}
+
+	// each location has the function of the same id, which names it
+	names := map[uint64]string{}
+
+	for _, function := range functions {
+		fields := decode(t, function.bytes)
+
+		if filename := text(value(fields, functionFilename).varint); filename != "nested.hex" {
+			t.Errorf("function in %s, want nested.hex", filename)
+		}
+
+		names[value(fields, functionID).varint] = text(value(fields, functionName).varint)
+	}
+
+	addresses := map[uint64]uint64{}
+
+	for index, location := range locations {
+		fields := decode(t, location.bytes)
+		id := value(fields, locationID).varint
+
+		if id != uint64(index+1) {
+			t.Errorf("location %d has id %d, want ids from 1", index, id)
+		}
+
+		line := decode(t, value(fields, locationLine).bytes)
+
+		if value(line, lineFunctionID).varint != id {
+			t.Errorf("location %d has function %d", id, value(line, lineFunctionID).varint)
+		}
+
+		addresses[id] = value(fields, locationAddress).varint
+	}
+
+	// 13 instructions, 2 loops and the program
+	if len(locations) != 16 || len(functions) != 16 {
+		t.Fatalf("got %d locations and %d functions, want 16", len(locations), len(functions))
+	}
+
+	var instructions uint64
+
+	var ldm []string
+	var ldmValues []uint64
+	var ldmLabels []string
+
+	for _, sample := range samples {
+		fields := decode(t, sample.bytes)
+
+		var stack []string
+
+		for _, id := range unpack(t, value(fields, sampleLocationID).bytes) {
+			stack = append(stack, names[id])
+		}
+
+		values := unpack(t, value(fields, sampleValue).bytes)
+		instructions += values[0]
+
+		if stack[0] != "09 LDM Y Z" {
+			continue
+		}
+
+		ldm, ldmValues = stack, values
+
+		if addresses[unpack(t, value(fields, sampleLocationID).bytes)[0]] != 0x09 {
+			t.Errorf("LDM's location is not at address 09")
+		}
+
+		for _, f := range fields {
+			if f.number == sampleLabel {
+				label := decode(t, f.bytes)
+				ldmLabels = append(ldmLabels, text(value(label, labelKey).varint)+"="+text(value(label, labelStr).varint))
+			}
+		}
+	}
+
+	if len(samples) != 13 || instructions != profile.Steps {
+		t.Errorf("got %d samples of %d instructions, want 13 of %d", len(samples), instructions, profile.Steps)
+	}
+
+	if want := []string{"09 LDM Y Z", "loop 09-16", "loop 06-22", "nested.hex"}; !reflect.DeepEqual(ldm, want) {
+		t.Errorf("LDM's stack is %q, want %q", ldm, want)
+	}
+
+	if !reflect.DeepEqual(ldmValues, []uint64{6, 6}) {
+		t.Errorf("LDM's values are %v, want 6 executions and 6 accesses", ldmValues)
+	}
+
+	if want := []string{"opcode=LDM", "address=09"}; !reflect.DeepEqual(ldmLabels, want) {
+		t.Errorf("LDM's labels are %q, want %q", ldmLabels, want)
+	}
+}
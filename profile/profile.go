@@ -0,0 +1,97 @@
+package profile
+
+import (
+	"sort"
+
+	"github.com/tmornini/rigetti-computing/memory"
+	"github.com/tmornini/rigetti-computing/processor"
+)
+
+// Profile counts where a program spends its time. Add it to
+// processor.Configuration.Observers before the program runs
+type Profile struct {
+	Steps uint64
+
+	Executions [256]uint64 // by program address
+	Opcodes    [256]uint64 // executions by opcode
+
+	// MainMemoryAccesses by program address of the LDM or STR that made them
+	MainMemoryAccesses [256]uint64
+
+	Reads  [256]uint64 // by main memory address
+	Writes [256]uint64 // by main memory address
+
+	// Loops counts iterations, each begun by a backward jump taken
+	Loops map[Loop]uint64
+}
+
+// Loop is the program memory from Start to the backward jump at End that
+// repeats it
+type Loop struct {
+	Start memory.Address
+	End   memory.Address
+}
+
+// New create an empty profile
+func New() *Profile {
+	return &Profile{Loops: map[Loop]uint64{}}
+}
+
+// Observe counts an instruction processed
+func (profile *Profile) Observe(event processor.Event) {
+	profile.Steps++
+
+	profile.Executions[event.ProgramCounter]++
+	profile.Opcodes[event.Opcode]++
+
+	if event.Jumped && event.Next <= event.ProgramCounter {
+		profile.Loops[Loop{Start: event.Next, End: event.ProgramCounter}]++
+	}
+
+	if event.MainMemoryRead {
+		profile.Reads[event.MainMemoryAddress]++
+		profile.MainMemoryAccesses[event.ProgramCounter]++
+	}
+
+	if event.MainMemoryWrite {
+		profile.Writes[event.MainMemoryAddress]++
+		profile.MainMemoryAccesses[event.ProgramCounter]++
+	}
+}
+
+// SortedLoops returns the loops by Start, longest first, so enclosing loops
+// precede the loops they enclose
+func (profile *Profile) SortedLoops() []Loop {
+	var loops []Loop
+
+	for loop := range profile.Loops {
+		loops = append(loops, loop)
+	}
+
+	sort.Slice(loops, func(i, j int) bool {
+		if loops[i].Start != loops[j].Start {
+			return loops[i].Start < loops[j].Start
+		}
+
+		return loops[i].End > loops[j].End
+	})
+
+	return loops
+}
+
+// enclosingLoops returns the loops containing address, innermost first
+func (profile *Profile) enclosingLoops(address memory.Address) []Loop {
+	var loops []Loop
+
+	for _, loop := range profile.SortedLoops() {
+		if loop.Start <= address && address <= loop.End {
+			loops = append(loops, loop)
+		}
+	}
+
+	sort.SliceStable(loops, func(i, j int) bool {
+		return loops[i].End-loops[i].Start < loops[j].End-loops[j].Start
+	})
+
+	return loops
+}
@@ -0,0 +1,128 @@
+package profile
+
+import (
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	"github.com/tmornini/rigetti-computing/memory"
+	"github.com/tmornini/rigetti-computing/processor"
+)
+
+// nested counts Y down from 3 inside a loop counting X down from 2, reading
+// [Y] in the inner loop and writing [X] in the outer
+var nested = memory.ReadOnly{
+	0x06, 0x02, 0x00, //       LDI 0x02 X
+	0x06, 0x01, 0x03, //       LDI 0x01 W
+	0x06, 0x03, 0x01, // OUTER LDI 0x03 Y
+	0x05, 0x01, 0x02, // INNER LDM Y Z
+	0x02, 0x01, 0x03, 0x01, // SUB Y W Y
+	0x06, 0x00, 0x02, //       LDI 0x00 Z
+	0x0a, 0x01, 0x02, //       NQL Y Z
+	0x0c, 0x09, //             JMC INNER
+	0x07, 0x00, 0x00, //       STR X X
+	0x02, 0x00, 0x03, 0x00, // SUB X W X
+	0x0a, 0x00, 0x02, //       NQL X Z
+	0x0c, 0x06, //             JMC OUTER
+	0x0f, //                   HLT
+}
+
+var (
+	outer = Loop{Start: 0x06, End: 0x22}
+	inner = Loop{Start: 0x09, End: 0x16}
+)
+
+// profiled runs nested, observed by a new profile
+func profiled(t *testing.T) *Profile {
+	t.Helper()
+
+	profile := New()
+
+	configuration := processor.DefaultConfiguration
+	configuration.Output = ioutil.Discard
+	configuration.Observers = []processor.Observer{profile}
+
+	err := processor.New(
+		processor.NormalInstructionSet,
+		configuration,
+		&nested,
+		&memory.ReadWrite{},
+	).Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return profile
+}
+
+// TestObserve counts executions, loop iterations and main memory accesses
+// by instruction and by main memory address
+func TestObserve(t *testing.T) {
+	profile := profiled(t)
+
+	// 2 before the loops, 2 outer iterations of an LDI, 3 inner iterations
+	// of 5 instructions and 4 more, then HLT
+	if profile.Steps != 2+2*(1+3*5+4)+1 {
+		t.Errorf("got %d steps, want %d", profile.Steps, 2+2*(1+3*5+4)+1)
+	}
+
+	executions := map[memory.Address]uint64{0x00: 1, 0x06: 2, 0x09: 6, 0x18: 2, 0x24: 1}
+
+	for address, want := range executions {
+		if profile.Executions[address] != want {
+			t.Errorf("%02x executed %d times, want %d", address, profile.Executions[address], want)
+		}
+	}
+
+	if profile.Opcodes[processor.LDM] != 6 || profile.Opcodes[processor.NQL] != 8 {
+		t.Errorf("got %d LDM and %d NQL, want 6 and 8", profile.Opcodes[processor.LDM], profile.Opcodes[processor.NQL])
+	}
+
+	loops := map[Loop]uint64{outer: 1, inner: 4}
+
+	if !reflect.DeepEqual(profile.Loops, loops) {
+		t.Errorf("got loops %v, want %v", profile.Loops, loops)
+	}
+
+	if profile.MainMemoryAccesses[0x09] != 6 || profile.MainMemoryAccesses[0x18] != 2 {
+		t.Errorf(
+			"LDM made %d accesses and STR %d, want 6 and 2",
+			profile.MainMemoryAccesses[0x09],
+			profile.MainMemoryAccesses[0x18],
+		)
+	}
+
+	var reads, writes [256]uint64
+	reads[0x01], reads[0x02], reads[0x03] = 2, 2, 2
+	writes[0x01], writes[0x02] = 1, 1
+
+	if profile.Reads != reads || profile.Writes != writes {
+		t.Errorf("got reads %v and writes %v", profile.Reads[:4], profile.Writes[:4])
+	}
+}
+
+// TestLoops sorts enclosing loops before those they enclose, and finds the
+// loops enclosing an address innermost first
+func TestLoops(t *testing.T) {
+	profile := profiled(t)
+
+	if loops := profile.SortedLoops(); !reflect.DeepEqual(loops, []Loop{outer, inner}) {
+		t.Errorf("got sorted loops %v, want %v", loops, []Loop{outer, inner})
+	}
+
+	for _, test := range []struct {
+		address memory.Address
+		loops   []Loop
+	}{
+		{0x00, nil},
+		{0x06, []Loop{outer}},
+		{0x0c, []Loop{inner, outer}},
+		{0x16, []Loop{inner, outer}},
+		{0x22, []Loop{outer}},
+		{0x24, nil},
+	} {
+		if loops := profile.enclosingLoops(test.address); !reflect.DeepEqual(loops, test.loops) {
+			t.Errorf("%02x is enclosed by %v, want %v", test.address, loops, test.loops)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"runtime"
+	"testing"
+
+	"github.com/tmornini/rigetti-computing/generator"
+	"github.com/tmornini/rigetti-computing/processor"
+	"github.com/tmornini/rigetti-computing/workload"
+)
+
+// generatedOptions generate a workload of three nested loops, mixing every
+// instruction
+var generatedOptions = func() generator.Options {
+	options := generator.DefaultOptions
+	options.Loops = 3
+	options.MaxDepth = 3
+
+	return options
+}()
+
+// rcc-bench prints one line per workload in Go benchmark format, so results
+// from different commits can be compared with benchstat
+func main() {
+	if len(os.Args) != 1 {
+		fmt.Fprintln(os.Stderr, "usage: "+os.Args[0])
+		os.Exit(2)
+	}
+
+	testing.Init()
+
+	configuration := processor.DefaultConfiguration
+	configuration.Output = ioutil.Discard
+	configuration.TraceOutput = ioutil.Discard
+
+	fmt.Printf("goos: %s\n", runtime.GOOS)
+	fmt.Printf("goarch: %s\n", runtime.GOARCH)
+	fmt.Printf("pkg: github.com/tmornini/rigetti-computing/workload\n")
+
+	generated, err := generator.Generate(generatedOptions)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(4)
+	}
+
+	workloads := append(workload.All, generated.Workload("generated"))
+
+	for _, w := range workloads {
+		report(w, "normal", processor.NormalInstructionSet, configuration)
+		report(w, "debug", processor.DebugInstructionSet, configuration)
+	}
+}
+
+func report(
+	w workload.Workload,
+	mode string,
+	instructionSet processor.InstructionSet,
+	configuration processor.Configuration,
+) {
+	var err error
+	var instructions uint64
+
+	result := testing.Benchmark(func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			programMemory := w.ProgramMemory
+			mainMemory := w.MainMemory
+
+			p := processor.New(
+				instructionSet,
+				configuration,
+				&programMemory,
+				&mainMemory,
+			)
+
+			err = p.Run()
+			instructions = p.Steps()
+		}
+	})
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, w.Name+": "+err.Error())
+		os.Exit(4)
+	}
+
+	executed := float64(instructions) * float64(result.N)
+
+	fmt.Printf(
+		"BenchmarkWorkload/%s/%s\t%d\t%d ns/op\t%.2f MIPS\t%.4f allocs/instruction\t%d instructions/op\n",
+		w.Name,
+		mode,
+		result.N,
+		result.NsPerOp(),
+		executed/result.T.Seconds()/1e6,
+		float64(result.MemAllocs)/executed,
+		instructions,
+	)
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/tmornini/rigetti-computing/memory"
+	"github.com/tmornini/rigetti-computing/processor"
+)
+
+// imageWriters write a program in each memory.Format
+var imageWriters = map[string]func(io.Writer, []byte) error{
+	"binary":    writeBinary,
+	"hex-dump":  memory.WriteHexDump,
+	"intel-hex": memory.WriteIntelHex,
+	"s-record":  memory.WriteSRecord,
+}
+
+func asmCommand(
+	name string,
+	args []string,
+	stdin io.Reader,
+	stdout io.Writer,
+	stderr io.Writer,
+) (exitCode int) {
+	flags := newFlagSet(name, "assembly language file", stderr)
+
+	format := flags.String(
+		"format",
+		"hex-dump",
+		"program `format`: binary, hex-dump, intel-hex or s-record",
+	)
+	outputFile := flags.String(
+		"output",
+		"",
+		"write the program to `file` rather than stdout",
+	)
+
+	err := flags.Parse(args)
+	if err != nil {
+		return 2
+	}
+
+	writeImage, ok := imageWriters[*format]
+	if !ok {
+		fmt.Fprintln(
+			stderr,
+			"-format must be one of binary, hex-dump, intel-hex or s-record",
+		)
+		return 2
+	}
+
+	sourceReader, closeSource, exitCode := openArgument(flags, stdin, stderr)
+	if exitCode != 0 {
+		return exitCode
+	}
+	defer closeSource()
+
+	program, err := processor.Assemble(sourceReader)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 3
+	}
+
+	output := stdout
+
+	if *outputFile != "" {
+		file, err := os.Create(*outputFile)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+		defer file.Close()
+
+		output = file
+	}
+
+	err = writeImage(output, program)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	return 0
+}
+
+func writeBinary(writer io.Writer, data []byte) error {
+	_, err := writer.Write(data)
+
+	return err
+}
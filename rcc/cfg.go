@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/tmornini/rigetti-computing/analysis"
+	"github.com/tmornini/rigetti-computing/coverage"
+)
+
+// cfgCommand writes the basic blocks of the code reachable from #x00 as
+// Graphviz DOT or Mermaid, weighted by a coverage file's counts
+func cfgCommand(
+	name string,
+	args []string,
+	stdin io.Reader,
+	stdout io.Writer,
+	stderr io.Writer,
+) (exitCode int) {
+	flags := newFlagSet(name, "program", stderr)
+
+	format := flags.String(
+		"format",
+		"dot",
+		"graph `format`: dot or mermaid",
+	)
+	countsFile := flags.String(
+		"counts",
+		"",
+		"weight blocks and edges by the executions in a -coverage `file`",
+	)
+
+	err := flags.Parse(args)
+	if err != nil {
+		return 2
+	}
+
+	if *format != "dot" && *format != "mermaid" {
+		fmt.Fprintln(stderr, "-format must be dot or mermaid")
+		return 2
+	}
+
+	programMemory, exitCode := readProgram(flags, stdin, stderr)
+	if exitCode != 0 {
+		return exitCode
+	}
+
+	var counts *coverage.Coverage
+
+	if *countsFile != "" {
+		counts, exitCode = readCoverage(*countsFile, stderr)
+		if exitCode != 0 {
+			return exitCode
+		}
+
+		if counts.ProgramMemory != *programMemory {
+			fmt.Fprintf(stderr, "%s: %s\n", *countsFile, coverage.ErrProgramMismatch)
+			return 3
+		}
+	}
+
+	graph := analysis.NewGraph(programMemory)
+
+	if *format == "dot" {
+		err = graph.WriteDOT(stdout, counts)
+	} else {
+		err = graph.WriteMermaid(stdout, counts)
+	}
+
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	return 0
+}
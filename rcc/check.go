@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/tmornini/rigetti-computing/analysis"
+)
+
+// checkCommand analyzes the code reachable from #x00, exiting with code 5
+// when it finds errors, or warnings with -strict
+func checkCommand(
+	name string,
+	args []string,
+	stdin io.Reader,
+	stdout io.Writer,
+	stderr io.Writer,
+) (exitCode int) {
+	flags := newFlagSet(name, "program", stderr)
+
+	var strict bool
+
+	flags.BoolVar(
+		&strict,
+		"strict",
+		false,
+		"exit with code 5 for warnings too",
+	)
+
+	err := flags.Parse(args)
+	if err != nil {
+		return 2
+	}
+
+	programMemory, exitCode := readProgram(flags, stdin, stderr)
+	if exitCode != 0 {
+		return exitCode
+	}
+
+	for _, finding := range analysis.Check(programMemory) {
+		fmt.Fprintln(stdout, finding)
+
+		if strict || finding.Severity == analysis.Error {
+			exitCode = 5
+		}
+	}
+
+	return exitCode
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tmornini/rigetti-computing/coverage"
+	"github.com/tmornini/rigetti-computing/memory"
+	"github.com/tmornini/rigetti-computing/processor"
+)
+
+// coverageCommand reports coverage files written by -coverage, merging them
+// when there are more than one
+func coverageCommand(
+	name string,
+	args []string,
+	stdin io.Reader,
+	stdout io.Writer,
+	stderr io.Writer,
+) (exitCode int) {
+	flags := newFlagSet(name, "coverage file...", stderr)
+
+	format := flags.String(
+		"format",
+		"listing",
+		"report `format`: listing or lcov",
+	)
+	sourceFile := flags.String(
+		"source",
+		"",
+		"assembly language `file` the program was assembled from, for lcov",
+	)
+
+	err := flags.Parse(args)
+	if err != nil {
+		return 2
+	}
+
+	if *format != "listing" && *format != "lcov" {
+		fmt.Fprintln(stderr, "-format must be listing or lcov")
+		return 2
+	}
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	var merged *coverage.Coverage
+
+	for _, pathname := range flags.Args() {
+		programCoverage, exitCode := readCoverage(pathname, stderr)
+		if exitCode != 0 {
+			return exitCode
+		}
+
+		if merged == nil {
+			merged = programCoverage
+			continue
+		}
+
+		err = merged.Merge(programCoverage)
+		if err != nil {
+			fmt.Fprintf(stderr, "%s: %s\n", pathname, err)
+			return 3
+		}
+	}
+
+	if *format == "listing" {
+		err = merged.WriteListing(stdout)
+	} else {
+		var sourceLines *[256]int
+
+		// without -source, lines are rcc disasm's, written beside the
+		// first coverage file
+		source := strings.TrimSuffix(flags.Arg(0), filepath.Ext(flags.Arg(0))) + ".asm"
+
+		if *sourceFile != "" {
+			source = *sourceFile
+
+			sourceLines, exitCode = readSourceLines(*sourceFile, merged, stderr)
+			if exitCode != 0 {
+				return exitCode
+			}
+		}
+
+		err = merged.WriteLCOV(stdout, source, sourceLines)
+	}
+
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	return 0
+}
+
+func readCoverage(
+	pathname string,
+	stderr io.Writer,
+) (*coverage.Coverage, int) {
+	file, err := os.Open(pathname)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return nil, 1
+	}
+	defer file.Close()
+
+	programCoverage, err := coverage.Read(file)
+	if err != nil {
+		fmt.Fprintf(stderr, "%s: %s\n", pathname, err)
+		return nil, 3
+	}
+
+	return programCoverage, 0
+}
+
+// readSourceLines assembles -source, making certain it is the program
+// covered
+func readSourceLines(
+	pathname string,
+	programCoverage *coverage.Coverage,
+	stderr io.Writer,
+) (*[256]int, int) {
+	file, err := os.Open(pathname)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return nil, 1
+	}
+	defer file.Close()
+
+	program, lines, err := processor.AssembleWithLines(file)
+	if err != nil {
+		fmt.Fprintf(stderr, "%s: %s\n", pathname, err)
+		return nil, 3
+	}
+
+	programMemory := memory.ReadOnly{}
+	copy(programMemory[:], program)
+
+	if programMemory != programCoverage.ProgramMemory {
+		fmt.Fprintf(stderr, "%s: %s\n", pathname, coverage.ErrProgramMismatch)
+		return nil, 3
+	}
+
+	return &lines, 0
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tmornini/rigetti-computing/memory"
+	"github.com/tmornini/rigetti-computing/processor"
+)
+
+const debugHelp = `commands:
+  step [n]       execute n instructions, default 1
+  continue       execute until a breakpoint, HLT or an error
+  break [addr]   toggle a breakpoint at a hex address, or list them
+  registers      show the PC, registers and flags
+  memory         show main memory as a hex dump
+  disassemble    show the instruction at the PC
+  dump           show the final state in the -dump format
+  snapshot file  write a snapshot that -restore resumes
+  quit           stop debugging
+commands other than dump and snapshot may be abbreviated to their first letter`
+
+// debugger reads commands from stdin and traces to stdout, so prompts and
+// traces interleave
+type debugger struct {
+	machine     *machine
+	breakpoints map[memory.Address]bool
+	output      io.Writer
+	err         error // execution error, reported by the exit code
+	halted      bool
+}
+
+func debugCommand(
+	name string,
+	args []string,
+	stdin io.Reader,
+	stdout io.Writer,
+	stderr io.Writer,
+) (exitCode int) {
+	flags := newFlagSet(name, "program", stderr)
+	execution := newExecutionFlags(flags, true)
+
+	err := flags.Parse(args)
+	if err != nil {
+		return 2
+	}
+
+	if flags.NArg() != 1 && execution.restoreFile == "" {
+		fmt.Fprintln(stderr, "debug reads commands from stdin, so it needs a program or -restore file")
+		flags.Usage()
+		return 2
+	}
+
+	if execution.traceOutputFile == "" {
+		stderr = stdout
+	}
+
+	m, exitCode := execution.boot(flags, true, nil, stdout, stderr)
+	if exitCode != 0 {
+		return exitCode
+	}
+	defer m.close()
+
+	d := &debugger{
+		machine:     m,
+		breakpoints: map[memory.Address]bool{},
+		output:      stdout,
+	}
+
+	scanner := bufio.NewScanner(stdin)
+
+	for {
+		fmt.Fprint(stdout, "(rcc) ")
+
+		if !scanner.Scan() {
+			fmt.Fprintln(stdout)
+			break
+		}
+
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		if !d.command(fields[0], fields[1:]) {
+			break
+		}
+	}
+
+	err = execution.saveCoverage(m)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		exitCode = 1
+	}
+
+	// the debugger has printed the error execution exited with, which wins
+	// as it does for run
+	if d.err != nil {
+		return 4
+	}
+
+	return exitCode
+}
+
+// command executes one command, returning false to quit
+func (d *debugger) command(command string, arguments []string) bool {
+	switch command {
+	case "s", "step":
+		steps := uint64(1)
+
+		if len(arguments) > 0 {
+			parsed, err := strconv.ParseUint(arguments[0], 10, 64)
+			if err != nil {
+				fmt.Fprintln(d.output, "step takes a decimal number of instructions")
+				return true
+			}
+
+			steps = parsed
+		}
+
+		for step := uint64(0); step < steps; step++ {
+			if !d.step() {
+				break
+			}
+		}
+	case "c", "continue":
+		for d.step() {
+			programCounter := d.machine.processor.Registers().ProgramCounter
+
+			if d.breakpoints[programCounter] {
+				fmt.Fprintf(d.output, "breakpoint at %02x\n", programCounter)
+				break
+			}
+		}
+	case "b", "break":
+		d.toggleBreakpoints(arguments)
+	case "r", "registers":
+		fmt.Fprintln(d.output, d.machine.processor.Registers())
+	case "m", "memory":
+		memory.WriteHexDump(d.output, d.machine.processor.MainMemory()[:])
+	case "d", "disassemble":
+		p := d.machine.processor
+
+		instruction := processor.Disassemble(
+			p.ProgramMemory(),
+			p.Registers().ProgramCounter,
+		)
+
+		fmt.Fprintf(
+			d.output,
+			"%02x: %s\n",
+			instruction.Address,
+			instruction.Assembly,
+		)
+	case "dump":
+		err := d.machine.dump(d.output, d.machine.processor)
+		if err != nil {
+			fmt.Fprintln(d.output, err)
+		}
+	case "snapshot":
+		if len(arguments) != 1 {
+			fmt.Fprintln(d.output, "snapshot takes a file name")
+			return true
+		}
+
+		err := writeSnapshot(arguments[0], d.machine.processor)
+		if err != nil {
+			fmt.Fprintln(d.output, err)
+		}
+	case "q", "quit":
+		return false
+	case "h", "help":
+		fmt.Fprintln(d.output, debugHelp)
+	default:
+		fmt.Fprintf(d.output, "unknown command %s, try help\n", command)
+	}
+
+	return true
+}
+
+// step executes one instruction, returning false once execution has ended
+func (d *debugger) step() bool {
+	if d.halted || d.err != nil {
+		fmt.Fprintln(d.output, "execution has ended")
+		return false
+	}
+
+	d.halted, d.err = d.machine.processor.Step()
+
+	if d.err != nil {
+		fmt.Fprintln(d.output, d.err)
+		return false
+	}
+
+	if d.halted {
+		fmt.Fprintln(d.output, "halted")
+		return false
+	}
+
+	return true
+}
+
+func (d *debugger) toggleBreakpoints(arguments []string) {
+	for _, argument := range arguments {
+		address, err := strconv.ParseUint(argument, 16, 8)
+		if err != nil {
+			fmt.Fprintf(d.output, "%s is not a hex address from 00 to ff\n", argument)
+			return
+		}
+
+		d.breakpoints[memory.Address(address)] = !d.breakpoints[memory.Address(address)]
+
+		if !d.breakpoints[memory.Address(address)] {
+			delete(d.breakpoints, memory.Address(address))
+		}
+	}
+
+	var addresses []string
+
+	for address := range d.breakpoints {
+		addresses = append(addresses, fmt.Sprintf("%02x", address))
+	}
+
+	sort.Strings(addresses)
+
+	fmt.Fprintln(d.output, "breakpoints: "+strings.Join(addresses, " "))
+}
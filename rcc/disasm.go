@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/tmornini/rigetti-computing/processor"
+)
+
+func disasmCommand(
+	name string,
+	args []string,
+	stdin io.Reader,
+	stdout io.Writer,
+	stderr io.Writer,
+) (exitCode int) {
+	flags := newFlagSet(name, "program", stderr)
+
+	err := flags.Parse(args)
+	if err != nil {
+		return 2
+	}
+
+	programMemory, exitCode := readProgram(flags, stdin, stderr)
+	if exitCode != 0 {
+		return exitCode
+	}
+
+	err = processor.WriteListing(
+		stdout,
+		processor.DisassembleProgram(programMemory),
+		nil,
+	)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"io"
+	"sort"
+
+	"github.com/tmornini/rigetti-computing/processor"
+)
+
+// dumpFormat writes the final state of a processor
+type dumpFormat func(io.Writer, *processor.Processor) error
+
+var dumpFormats = map[string]dumpFormat{
+	processor.StateDump.String(): processorDump(processor.StateDump),
+	processor.HexDump.String():   processorDump(processor.HexDump),
+	processor.JSONDump.String():  processorDump(processor.JSONDump),
+	processor.DiffDump.String():  processorDump(processor.DiffDump),
+	"none":                       dumpNone,
+}
+
+func dumpFormatNames() []string {
+	var names []string
+
+	for name := range dumpFormats {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func processorDump(format processor.DumpFormat) dumpFormat {
+	return func(writer io.Writer, p *processor.Processor) error {
+		return p.Dump(writer, format)
+	}
+}
+
+func dumpNone(writer io.Writer, p *processor.Processor) error {
+	return nil
+}
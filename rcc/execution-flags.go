@@ -0,0 +1,687 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/tmornini/rigetti-computing/coverage"
+	"github.com/tmornini/rigetti-computing/memory"
+	"github.com/tmornini/rigetti-computing/processor"
+)
+
+// executionFlags are shared by rcc run, trace and debug
+type executionFlags struct {
+	configuration processor.Configuration
+
+	registers       processor.Registers
+	programCounter  byte
+	faultHandler    byte
+	faultClasses    map[processor.FaultClass]bool
+	mainMemoryFile  string
+	mainMemoryBytes memoryBytes
+
+	restoreFile  string
+	snapshotFile string
+	coverageFile string
+
+	timingFile     string
+	clockFrequency float64
+
+	outputFile      string
+	dumpOutputFile  string
+	traceOutputFile string
+	traceOnly       opcodes
+	traceSkip       opcodes
+
+	dump string
+}
+
+func newExecutionFlags(flags *flag.FlagSet, traced bool) *executionFlags {
+	execution := &executionFlags{
+		configuration: processor.DefaultConfiguration,
+		faultClasses:  map[processor.FaultClass]bool{},
+	}
+
+	configuration := &execution.configuration
+
+	flags.Var(
+		endOfProgramMemoryPolicy{&configuration.FetchBeyondEnd},
+		"fetch-beyond-end",
+		"`policy` when an instruction's parameters extend past #xFF: fault, wrap or halt",
+	)
+	flags.Var(
+		endOfProgramMemoryPolicy{&configuration.ProgramCounterOverflow},
+		"pc-overflow",
+		"`policy` when the PC advances past #xFF: fault, wrap or halt",
+	)
+	flags.Var(
+		faultPolicies{&configuration.FaultPolicies, execution.faultClasses},
+		"fault-policy",
+		"fault `class=policy` pairs, e.g. unknown-register=set-e,unknown-opcode=trap",
+	)
+	flags.Var(
+		hexByte{&execution.faultHandler},
+		"fault-handler",
+		"`address` the trap fault policy continues at, in hex",
+	)
+	flags.Uint64Var(
+		&configuration.MaxSteps,
+		"max-steps",
+		0,
+		"stop with exit code 4 after this many `steps`, 0 is unlimited",
+	)
+
+	flags.StringVar(
+		&execution.mainMemoryFile,
+		"memory",
+		"",
+		"preload main memory from a binary, hex dump, Intel HEX or S-record `file`",
+	)
+	flags.Var(
+		&execution.mainMemoryBytes,
+		"memory-bytes",
+		"preload main memory with `address:hexbytes`, after -memory, repeatable",
+	)
+	flags.Var(hexByte{&execution.programCounter}, "pc", "initial program counter, in `hex`")
+	flags.Var(hexByte{&execution.registers.X}, "x", "initial X register, in `hex`")
+	flags.Var(hexByte{&execution.registers.Y}, "y", "initial Y register, in `hex`")
+	flags.Var(hexByte{&execution.registers.Z}, "z", "initial Z register, in `hex`")
+	flags.Var(hexByte{&execution.registers.W}, "w", "initial W register, in `hex`")
+	flags.BoolVar(&execution.registers.C, "c", false, "set the C flag initially")
+	flags.BoolVar(&execution.registers.E, "e", false, "set the E flag initially")
+
+	flags.StringVar(
+		&execution.restoreFile,
+		"restore",
+		"",
+		"start from a snapshot `file` rather than a program",
+	)
+	flags.StringVar(
+		&execution.snapshotFile,
+		"snapshot",
+		"",
+		"write a snapshot `file` of the final state, which -restore resumes",
+	)
+
+	flags.StringVar(
+		&execution.timingFile,
+		"timing",
+		"",
+		"count cycles, costing opcodes and memory accesses as a timing `file` says",
+	)
+	flags.Var(
+		clockFrequency{&execution.clockFrequency},
+		"clock",
+		"count cycles, converting them to time at this `frequency`, e.g. 4MHz",
+	)
+
+	flags.StringVar(
+		&execution.coverageFile,
+		"coverage",
+		"",
+		"add this run's coverage to `file`, which rcc coverage reports",
+	)
+
+	flags.StringVar(
+		&execution.outputFile,
+		"output",
+		"",
+		"write PRN output and the final state to `file` rather than stdout",
+	)
+	flags.StringVar(
+		&execution.dumpOutputFile,
+		"dump-output",
+		"",
+		"write the final state to `file`, apart from PRN output",
+	)
+
+	if traced {
+		flags.StringVar(
+			&execution.traceOutputFile,
+			"trace-output",
+			"",
+			"write the trace to `file` rather than stderr",
+		)
+		flags.Var(
+			&execution.traceOnly,
+			"trace-only",
+			"trace only these `opcodes`, e.g. JMP,JMC,JME",
+		)
+		flags.Var(
+			&execution.traceSkip,
+			"trace-skip",
+			"do not trace these `opcodes`, e.g. NOP",
+		)
+	}
+
+	flags.StringVar(
+		&execution.dump,
+		"dump",
+		"state",
+		"final state `format`: "+strings.Join(dumpFormatNames(), ", "),
+	)
+
+	return execution
+}
+
+// machine is a booted processor and the files its output goes to
+type machine struct {
+	processor  *processor.Processor
+	output     io.Writer
+	dumpOutput io.Writer // output, unless -dump-output separates them
+	dump       dumpFormat
+	coverage   *coverage.Coverage // nil without -coverage
+	files      []*os.File
+}
+
+func (m *machine) close() {
+	for _, file := range m.files {
+		file.Close()
+	}
+}
+
+// boot loads the program and main memory, or restores a snapshot, opens
+// output files and presets registers, returning an exit code other than 0
+// if anything fails
+func (execution *executionFlags) boot(
+	flags *flag.FlagSet,
+	traced bool,
+	stdin io.Reader,
+	stdout io.Writer,
+	stderr io.Writer,
+) (*machine, int) {
+	dump, ok := dumpFormats[execution.dump]
+	if !ok {
+		fmt.Fprintf(
+			stderr,
+			"-dump must be one of %s\n",
+			strings.Join(dumpFormatNames(), ", "),
+		)
+		return nil, 2
+	}
+
+	set := map[string]bool{}
+	flags.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	configuration := execution.configuration
+	configuration.FaultHandler = memory.Address(execution.faultHandler)
+	configuration.Output = stdout
+	configuration.TraceOutput = stderr
+
+	var snapshot *processor.Snapshot
+	var programMemory *memory.ReadOnly
+	var exitCode int
+
+	if execution.restoreFile != "" {
+		snapshot, configuration, exitCode = execution.readSnapshot(
+			flags,
+			set,
+			configuration,
+			stderr,
+		)
+	} else {
+		programMemory, exitCode = readProgram(flags, stdin, stderr)
+	}
+
+	if exitCode != 0 {
+		return nil, exitCode
+	}
+
+	m := &machine{dump: dump, output: stdout}
+
+	if execution.outputFile != "" {
+		file, err := os.Create(execution.outputFile)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return nil, 1
+		}
+
+		m.files = append(m.files, file)
+		m.output = file
+		configuration.Output = file
+	}
+
+	m.dumpOutput = m.output
+
+	if execution.dumpOutputFile != "" {
+		file, err := os.Create(execution.dumpOutputFile)
+		if err != nil {
+			m.close()
+			fmt.Fprintln(stderr, err)
+			return nil, 1
+		}
+
+		m.files = append(m.files, file)
+		m.dumpOutput = file
+	}
+
+	if execution.traceOutputFile != "" {
+		file, err := os.Create(execution.traceOutputFile)
+		if err != nil {
+			m.close()
+			fmt.Fprintln(stderr, err)
+			return nil, 1
+		}
+
+		m.files = append(m.files, file)
+		configuration.TraceOutput = file
+	}
+
+	configuration.Timing, exitCode = execution.timing(set, stderr)
+	if exitCode != 0 {
+		m.close()
+		return nil, exitCode
+	}
+
+	instructionSet := execution.instructionSet(traced)
+
+	if execution.coverageFile != "" {
+		m.coverage = &coverage.Coverage{Runs: 1}
+
+		configuration.Observers = append(configuration.Observers, m.coverage)
+	}
+
+	if snapshot != nil {
+		p, err := processor.Restore(instructionSet, configuration, snapshot)
+		if err != nil {
+			m.close()
+			fmt.Fprintln(stderr, err)
+			return nil, 3
+		}
+
+		m.processor = p
+
+		exitCode = execution.presetMainMemory(p.MainMemory(), stderr)
+	} else {
+		mainMemory := &memory.ReadWrite{}
+
+		exitCode = execution.presetMainMemory(mainMemory, stderr)
+
+		m.processor = processor.New(
+			instructionSet,
+			configuration,
+			programMemory,
+			mainMemory,
+		)
+	}
+
+	if exitCode != 0 {
+		m.close()
+		return nil, exitCode
+	}
+
+	execution.presetRegisters(m.processor, set)
+
+	if m.coverage != nil {
+		m.coverage.ProgramMemory = *m.processor.ProgramMemory()
+	}
+
+	return m, 0
+}
+
+// saveCoverage adds the coverage of this run to -coverage's file, creating
+// it if need be
+func (execution *executionFlags) saveCoverage(m *machine) error {
+	if m.coverage == nil {
+		return nil
+	}
+
+	file, err := os.Open(execution.coverageFile)
+	if err == nil {
+		previous, err := coverage.Read(file)
+		file.Close()
+
+		if err != nil {
+			return fmt.Errorf("%s: %w", execution.coverageFile, err)
+		}
+
+		err = m.coverage.Merge(previous)
+		if err != nil {
+			return fmt.Errorf("%s: %w", execution.coverageFile, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	file, err = os.Create(execution.coverageFile)
+	if err != nil {
+		return err
+	}
+
+	err = m.coverage.Write(file)
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// readSnapshot reads -restore in place of a program. Policy flags that are
+// set override the snapshot's policies, -fault-policy only for the classes
+// it names
+func (execution *executionFlags) readSnapshot(
+	flags *flag.FlagSet,
+	set map[string]bool,
+	configuration processor.Configuration,
+	stderr io.Writer,
+) (*processor.Snapshot, processor.Configuration, int) {
+	if flags.NArg() != 0 {
+		fmt.Fprintln(stderr, "-restore replaces the program, so it cannot be given too")
+		flags.Usage()
+		return nil, configuration, 2
+	}
+
+	file, err := os.Open(execution.restoreFile)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return nil, configuration, 1
+	}
+	defer file.Close()
+
+	snapshot, err := processor.ReadSnapshot(file)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return nil, configuration, 3
+	}
+
+	restored, err := snapshot.Configuration(configuration)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return nil, configuration, 3
+	}
+
+	if set["fetch-beyond-end"] {
+		restored.FetchBeyondEnd = configuration.FetchBeyondEnd
+	}
+
+	if set["pc-overflow"] {
+		restored.ProgramCounterOverflow = configuration.ProgramCounterOverflow
+	}
+
+	for class := range execution.faultClasses {
+		restored.FaultPolicies[class] = configuration.FaultPolicies[class]
+	}
+
+	if set["fault-handler"] {
+		restored.FaultHandler = configuration.FaultHandler
+	}
+
+	return snapshot, restored, 0
+}
+
+// timing reads -timing and applies -clock, returning nil when neither is
+// set, so cycles are not counted
+func (execution *executionFlags) timing(
+	set map[string]bool,
+	stderr io.Writer,
+) (*processor.Timing, int) {
+	if !set["timing"] && !set["clock"] {
+		return nil, 0
+	}
+
+	timing := processor.DefaultTiming()
+
+	if execution.timingFile != "" {
+		file, err := os.Open(execution.timingFile)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return nil, 1
+		}
+		defer file.Close()
+
+		timing, err = processor.ParseTiming(file)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return nil, 3
+		}
+	}
+
+	if set["clock"] {
+		timing.ClockFrequency = execution.clockFrequency
+	}
+
+	return timing, 0
+}
+
+// presetMainMemory applies -memory and -memory-bytes, on top of a restored
+// snapshot's main memory when there is one
+func (execution *executionFlags) presetMainMemory(
+	mainMemory *memory.ReadWrite,
+	stderr io.Writer,
+) int {
+	if execution.mainMemoryFile != "" {
+		file, err := os.Open(execution.mainMemoryFile)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+		defer file.Close()
+
+		image, err := memory.NewMainMemoryFromImage(file)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 3
+		}
+
+		*mainMemory = *image
+	}
+
+	err := execution.mainMemoryBytes.writeTo(mainMemory)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 3
+	}
+
+	return 0
+}
+
+// presetRegisters applies the register and flag flags that are set, so a
+// restored snapshot keeps the values they do not mention
+func (execution *executionFlags) presetRegisters(
+	p *processor.Processor,
+	set map[string]bool,
+) {
+	registers := p.Registers()
+	presets := execution.registers
+
+	if set["pc"] {
+		registers.ProgramCounter = memory.Address(execution.programCounter)
+	}
+
+	if set["x"] {
+		registers.X = presets.X
+	}
+
+	if set["y"] {
+		registers.Y = presets.Y
+	}
+
+	if set["z"] {
+		registers.Z = presets.Z
+	}
+
+	if set["w"] {
+		registers.W = presets.W
+	}
+
+	if set["c"] {
+		registers.C = presets.C
+	}
+
+	if set["e"] {
+		registers.E = presets.E
+	}
+
+	p.SetRegisters(registers)
+}
+
+func writeSnapshot(pathname string, p *processor.Processor) error {
+	file, err := os.Create(pathname)
+	if err != nil {
+		return err
+	}
+
+	err = p.Snapshot().Write(file)
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// instructionSet traces every opcode in -trace-only, or all of them when it
+// is not given, except those in -trace-skip
+func (execution *executionFlags) instructionSet(
+	traced bool,
+) processor.InstructionSet {
+	if !traced {
+		return processor.NormalInstructionSet
+	}
+
+	var tracedOpcodes [256]bool
+
+	for opcode := range tracedOpcodes {
+		tracedOpcodes[opcode] = len(execution.traceOnly) == 0
+	}
+
+	for _, opcode := range execution.traceOnly {
+		tracedOpcodes[opcode] = true
+	}
+
+	for _, opcode := range execution.traceSkip {
+		tracedOpcodes[opcode] = false
+	}
+
+	return processor.TracedInstructionSet(tracedOpcodes)
+}
+
+// endOfProgramMemoryPolicy is a flag.Value for fault, wrap or halt
+type endOfProgramMemoryPolicy struct {
+	policy *processor.EndOfProgramMemoryPolicy
+}
+
+func (flag endOfProgramMemoryPolicy) String() string {
+	if flag.policy == nil {
+		return ""
+	}
+
+	return flag.policy.String()
+}
+
+func (flag endOfProgramMemoryPolicy) Set(text string) error {
+	policy, err := processor.ParseEndOfProgramMemoryPolicy(text)
+	if err != nil {
+		return err
+	}
+
+	*flag.policy = policy
+
+	return nil
+}
+
+// faultPolicies is a flag.Value for e.g.
+// unknown-register=set-e,unknown-opcode=trap, recording the classes named
+type faultPolicies struct {
+	policies *processor.FaultPolicies
+	named    map[processor.FaultClass]bool
+}
+
+func (flag faultPolicies) String() string {
+	if flag.policies == nil {
+		return ""
+	}
+
+	var assignments []string
+
+	for class, policy := range flag.policies {
+		if policy != processor.Abort {
+			assignments = append(
+				assignments,
+				processor.FaultClass(class).String()+"="+policy.String(),
+			)
+		}
+	}
+
+	return strings.Join(assignments, ",")
+}
+
+func (flag faultPolicies) Set(text string) error {
+	for _, assignment := range strings.Split(text, ",") {
+		className, policyName := assignment, ""
+
+		if index := strings.Index(assignment, "="); index >= 0 {
+			className, policyName = assignment[:index], assignment[index+1:]
+		}
+
+		class, err := processor.ParseFaultClass(className)
+		if err != nil {
+			return errors.New(err.Error() + ": " + className)
+		}
+
+		policy, err := processor.ParseFaultPolicy(policyName)
+		if err != nil {
+			return err
+		}
+
+		flag.policies[class] = policy
+		flag.named[class] = true
+	}
+
+	return nil
+}
+
+// opcodes is a repeatable flag.Value of comma separated mnemonics
+type opcodes []byte
+
+func (flag *opcodes) String() string {
+	var mnemonics []string
+
+	for _, opcode := range *flag {
+		mnemonics = append(mnemonics, processor.OpcodeName(opcode))
+	}
+
+	return strings.Join(mnemonics, ",")
+}
+
+func (flag *opcodes) Set(text string) error {
+	for _, mnemonic := range strings.Split(text, ",") {
+		opcode, err := processor.ParseOpcode(mnemonic)
+		if err != nil {
+			return err
+		}
+
+		*flag = append(*flag, opcode)
+	}
+
+	return nil
+}
+
+// clockFrequency is a flag.Value of a frequency such as 4MHz
+type clockFrequency struct {
+	hertz *float64
+}
+
+func (flag clockFrequency) String() string {
+	if flag.hertz == nil || *flag.hertz == 0 {
+		return ""
+	}
+
+	return processor.FormatClockFrequency(*flag.hertz)
+}
+
+func (flag clockFrequency) Set(text string) error {
+	hertz, err := processor.ParseClockFrequency(text)
+	if err != nil {
+		return err
+	}
+
+	*flag.hertz = hertz
+
+	return nil
+}
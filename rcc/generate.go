@@ -0,0 +1,123 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/tmornini/rigetti-computing/generator"
+)
+
+// generateCommand writes a random program that always halts, as annotated
+// assembly language or an image
+func generateCommand(
+	name string,
+	args []string,
+	stdin io.Reader,
+	stdout io.Writer,
+	stderr io.Writer,
+) (exitCode int) {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: "+name+" [flags]")
+		flags.PrintDefaults()
+	}
+
+	options := generator.DefaultOptions
+
+	var mixText string
+
+	flags.Int64Var(&options.Seed, "seed", options.Seed, "generate the same program from the same `seed`")
+	flags.IntVar(
+		&options.Instructions,
+		"instructions",
+		options.Instructions,
+		"`number` of instructions from the mix, not counting those running loops",
+	)
+	flags.IntVar(&options.Loops, "loops", options.Loops, "`number` of loops")
+	flags.IntVar(&options.MaxDepth, "depth", options.MaxDepth, "nest loops up to this `depth`, 1 to 16")
+	flags.IntVar(
+		&options.MaxIterations,
+		"iterations",
+		options.MaxIterations,
+		"run each loop from 1 to this many `times`, up to 255",
+	)
+	flags.StringVar(
+		&mixText,
+		"mix",
+		"",
+		"change the `weights` of opcodes NOP to PRN, e.g. PRN=0,MUL=8",
+	)
+	format := flags.String(
+		"format",
+		"asm",
+		"program `format`: asm, binary, hex-dump, intel-hex or s-record",
+	)
+	outputFile := flags.String(
+		"output",
+		"",
+		"write the program to `file` rather than stdout",
+	)
+
+	err := flags.Parse(args)
+	if err != nil {
+		return 2
+	}
+
+	if flags.NArg() != 0 {
+		flags.Usage()
+		return 2
+	}
+
+	writeImage, ok := imageWriters[*format]
+	if !ok && *format != "asm" {
+		fmt.Fprintln(
+			stderr,
+			"-format must be one of asm, binary, hex-dump, intel-hex or s-record",
+		)
+		return 2
+	}
+
+	if mixText != "" {
+		options.Mix, err = generator.ParseMix(mixText)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 2
+		}
+	}
+
+	// invalid options, or too many instructions or loops to fit
+	program, err := generator.Generate(options)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	output := stdout
+
+	if *outputFile != "" {
+		file, err := os.Create(*outputFile)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+		defer file.Close()
+
+		output = file
+	}
+
+	if *format == "asm" {
+		_, err = io.WriteString(output, program.Assembly)
+	} else {
+		err = writeImage(output, program.Bytes)
+	}
+
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	return 0
+}
@@ -1,57 +1,148 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"io"
 	"os"
 
 	"github.com/tmornini/rigetti-computing/memory"
-	"github.com/tmornini/rigetti-computing/processor"
 )
 
 func main() {
-	var err error
-	var programReader io.ReadCloser
+	os.Exit(run(os.Args, os.Stdin, os.Stdout, os.Stderr))
+}
 
-	switch len(os.Args) {
-	case 1:
-		programReader = os.Stdin
-	case 2:
-		programReader, err = os.Open(os.Args[1])
-		if err != nil {
-			fmt.Fprintln(os.Stderr, err)
-			os.Exit(1)
+// subcommand is a main for each of rcc run, rcc trace, etc
+type subcommand func(
+	name string,
+	args []string,
+	stdin io.Reader,
+	stdout io.Writer,
+	stderr io.Writer,
+) (exitCode int)
+
+// run is main without the process, so specs can execute it in-process
+func run(
+	args []string,
+	stdin io.Reader,
+	stdout io.Writer,
+	stderr io.Writer,
+) (exitCode int) {
+	if len(args) > 1 {
+		switch args[1] {
+		case "help", "-h", "-help", "--help":
+			usage(args[0], stdout)
+			return 0
+		}
+
+		command, ok := subcommands()[args[1]]
+		if ok {
+			return command(args[0]+" "+args[1], args[2:], stdin, stdout, stderr)
 		}
-	default:
-		fmt.Fprintln(
-			os.Stderr,
-			"usage: "+os.Args[0]+"[256 byte binary file]",
-		)
-		os.Exit(2)
 	}
 
-	programMemory, err := memory.NewProgramFrom(programReader)
-	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(3)
+	// rcc program.bin runs the program, as rcc did before subcommands
+	return runCommand(args[0]+" run", args[1:], stdin, stdout, stderr)
+}
+
+func subcommands() map[string]subcommand {
+	return map[string]subcommand{
+		"run":      runCommand,
+		"trace":    traceCommand,
+		"debug":    debugCommand,
+		"asm":      asmCommand,
+		"disasm":   disasmCommand,
+		"check":    checkCommand,
+		"cfg":      cfgCommand,
+		"profile":  profileCommand,
+		"coverage": coverageCommand,
+		"pipeline": pipelineCommand,
+		"verilog":  verilogCommand,
+		"symbolic": symbolicCommand,
+		"generate": generateCommand,
 	}
+}
 
-	instructionSet := processor.NormalInstructionSet
+func usage(name string, writer io.Writer) {
+	fmt.Fprintln(writer, "usage: "+name+" <command> [flags] [program]")
+	fmt.Fprintln(writer)
+	fmt.Fprintln(writer, "  run      run a program, then dump the final state")
+	fmt.Fprintln(writer, "  trace    run a program, tracing each instruction")
+	fmt.Fprintln(writer, "  debug    step through a program interactively")
+	fmt.Fprintln(writer, "  asm      assemble a program")
+	fmt.Fprintln(writer, "  disasm   disassemble a program")
+	fmt.Fprintln(writer, "  check    report problems in the code a program can reach")
+	fmt.Fprintln(writer, "  cfg      draw a program's control-flow graph as DOT or Mermaid")
+	fmt.Fprintln(writer, "  profile  run a program, then list where it spent its time")
+	fmt.Fprintln(writer, "  coverage report what -coverage recorded, as a listing or LCOV")
+	fmt.Fprintln(writer, "  pipeline run a program on a pipeline, then report its CPI and stalls")
+	fmt.Fprintln(writer, "  verilog  generate a Verilog core running a program")
+	fmt.Fprintln(writer, "  symbolic find inputs for which a program reaches a state")
+	fmt.Fprintln(writer, "  generate write a random program that always halts")
+	fmt.Fprintln(writer)
+	fmt.Fprintln(writer, "programs are 256 byte binary, hex dump, Intel HEX or S-record files")
+	fmt.Fprintln(writer, "run "+name+" <command> -h for each command's flags")
+}
 
-	if os.Getenv("DEBUG") != "" {
-		instructionSet = processor.DebugInstructionSet
+// newFlagSet reports flag errors and usage on stderr, naming the program
+// argument
+func newFlagSet(
+	name string,
+	argument string,
+	stderr io.Writer,
+) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: "+name+" [flags] ["+argument+"]")
+		flags.PrintDefaults()
+	}
+
+	return flags
+}
 
-		if os.Getenv("NONOP") != "" {
-			instructionSet[0] = processor.NormalInstructionSet[0]
+// openArgument opens the only argument, or returns stdin when there is none
+func openArgument(
+	flags *flag.FlagSet,
+	stdin io.Reader,
+	stderr io.Writer,
+) (reader io.Reader, closeArgument func(), exitCode int) {
+	switch flags.NArg() {
+	case 0:
+		return stdin, func() {}, 0
+	case 1:
+		file, err := os.Open(flags.Arg(0))
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return nil, nil, 1
 		}
+
+		return file, func() { file.Close() }, 0
+	default:
+		flags.Usage()
+		return nil, nil, 2
 	}
+}
 
-	err = processor.Boot(
-		instructionSet,
-		programMemory,
-		&memory.ReadWrite{},
-	)
+// readProgram loads the program named by the only argument, or read from
+// stdin when there is none
+func readProgram(
+	flags *flag.FlagSet,
+	stdin io.Reader,
+	stderr io.Writer,
+) (programMemory *memory.ReadOnly, exitCode int) {
+	programReader, closeProgram, exitCode := openArgument(flags, stdin, stderr)
+	if exitCode != 0 {
+		return nil, exitCode
+	}
+	defer closeProgram()
+
+	programMemory, err := memory.NewProgramFromImage(programReader)
 	if err != nil {
-		os.Exit(4)
+		fmt.Fprintln(stderr, err)
+		return nil, 3
 	}
+
+	return programMemory, 0
 }
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/tmornini/rigetti-computing/pipeline"
+	"github.com/tmornini/rigetti-computing/processor"
+)
+
+// pipelineCommand runs a program on a pipeline, then dumps its final state
+// as rcc run does, followed by the CPI and what the stalls cost
+func pipelineCommand(
+	name string,
+	args []string,
+	stdin io.Reader,
+	stdout io.Writer,
+	stderr io.Writer,
+) (exitCode int) {
+	flags := newFlagSet(name, "program", stderr)
+	execution := newExecutionFlags(flags, false)
+
+	var options pipeline.Options
+
+	flags.BoolVar(
+		&options.Forwarding,
+		"forwarding",
+		false,
+		"forward results to the next instruction, rather than stalling it",
+	)
+	flags.BoolVar(
+		&options.Lockstep,
+		"lockstep",
+		false,
+		"check the pipeline against the processor after every instruction",
+	)
+
+	err := flags.Parse(args)
+	if err != nil {
+		return 2
+	}
+
+	set := map[string]bool{}
+	flags.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	for _, unsupported := range []string{"timing", "clock", "coverage"} {
+		if set[unsupported] {
+			fmt.Fprintf(stderr, "-%s is not supported by %s\n", unsupported, name)
+			flags.Usage()
+			return 2
+		}
+	}
+
+	m, exitCode := execution.boot(flags, false, stdin, stdout, stderr)
+	if exitCode != 0 {
+		return exitCode
+	}
+	defer m.close()
+
+	pl, err := pipeline.New(m.processor, options)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 3
+	}
+
+	runErr := pl.Run()
+
+	final, err := processor.Restore(
+		processor.NormalInstructionSet,
+		m.processor.Configuration(),
+		pl.Snapshot(),
+	)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return runExitCode(runErr, 1, stderr)
+	}
+
+	if execution.snapshotFile != "" {
+		err = writeSnapshot(execution.snapshotFile, final)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return runExitCode(runErr, 1, stderr)
+		}
+	}
+
+	err = m.dump(m.dumpOutput, final)
+	if err == nil {
+		_, err = fmt.Fprintln(m.output)
+	}
+
+	if err == nil {
+		err = pl.Statistics().Write(m.output)
+	}
+
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return runExitCode(runErr, 1, stderr)
+	}
+
+	return runExitCode(runErr, 0, stderr)
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/tmornini/rigetti-computing/memory"
+)
+
+// hexByte is a flag.Value for a register or address, written as hex
+type hexByte struct {
+	value *byte
+}
+
+func (flag hexByte) String() string {
+	if flag.value == nil {
+		return ""
+	}
+
+	return fmt.Sprintf("%02x", *flag.value)
+}
+
+func (flag hexByte) Set(text string) error {
+	value, err := strconv.ParseUint(text, 16, 8)
+	if err != nil {
+		return errors.New("must be a hex byte from 00 to ff")
+	}
+
+	*flag.value = byte(value)
+
+	return nil
+}
+
+// memoryBytes is a repeatable flag.Value of address:hexbytes, e.g.
+// 00:52434300 writes R, C, C and a zero terminator from address #x00
+type memoryBytes struct {
+	addresses []memory.Address
+	data      [][]byte
+}
+
+func (flag *memoryBytes) String() string {
+	var assignments []string
+
+	for index, address := range flag.addresses {
+		assignments = append(
+			assignments,
+			fmt.Sprintf("%02x:%x", address, flag.data[index]),
+		)
+	}
+
+	return strings.Join(assignments, ",")
+}
+
+func (flag *memoryBytes) Set(text string) error {
+	index := strings.Index(text, ":")
+	if index < 0 {
+		return errors.New("must be address:hexbytes, e.g. 00:52434300")
+	}
+
+	address, err := strconv.ParseUint(text[:index], 16, 8)
+	if err != nil {
+		return errors.New("address must be a hex byte from 00 to ff")
+	}
+
+	data, err := hex.DecodeString(text[index+1:])
+	if err != nil {
+		return fmt.Errorf("invalid hex %q", text[index+1:])
+	}
+
+	if int(address)+len(data) > 256 {
+		return fmt.Errorf(
+			"%d bytes from %02x extend beyond the end of memory at ff",
+			len(data),
+			address,
+		)
+	}
+
+	flag.addresses = append(flag.addresses, memory.Address(address))
+	flag.data = append(flag.data, data)
+
+	return nil
+}
+
+// writeTo writes each address:hexbytes in the order given, so later bytes
+// overwrite earlier ones
+func (flag *memoryBytes) writeTo(mainMemory *memory.ReadWrite) error {
+	for index, address := range flag.addresses {
+		err := mainMemory.Write(address, flag.data[index])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/tmornini/rigetti-computing/profile"
+)
+
+// writeProfile writes the annotated listing after the final state, and
+// -pprof's file, named after the program
+func writeProfile(
+	programProfile *profile.Profile,
+	m *machine,
+	pprofFile string,
+	flags *flag.FlagSet,
+	stderr io.Writer,
+) (exitCode int) {
+	programMemory := m.processor.ProgramMemory()
+
+	_, err := fmt.Fprintln(m.output)
+	if err == nil {
+		err = programProfile.WriteListing(m.output, programMemory)
+	}
+
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	if pprofFile == "" {
+		return 0
+	}
+
+	program := "stdin"
+
+	switch {
+	case flags.NArg() > 0:
+		program = filepath.Base(flags.Arg(0))
+	case flags.Lookup("restore").Value.String() != "":
+		program = filepath.Base(flags.Lookup("restore").Value.String())
+	}
+
+	file, err := os.Create(pprofFile)
+	if err == nil {
+		err = programProfile.WritePprof(file, programMemory, program)
+
+		closeErr := file.Close()
+		if err == nil {
+			err = closeErr
+		}
+	}
+
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	return 0
+}
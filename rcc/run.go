@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/tmornini/rigetti-computing/profile"
+)
+
+func runCommand(
+	name string,
+	args []string,
+	stdin io.Reader,
+	stdout io.Writer,
+	stderr io.Writer,
+) (exitCode int) {
+	return execute(name, args, false, false, stdin, stdout, stderr)
+}
+
+func traceCommand(
+	name string,
+	args []string,
+	stdin io.Reader,
+	stdout io.Writer,
+	stderr io.Writer,
+) (exitCode int) {
+	return execute(name, args, true, false, stdin, stdout, stderr)
+}
+
+func profileCommand(
+	name string,
+	args []string,
+	stdin io.Reader,
+	stdout io.Writer,
+	stderr io.Writer,
+) (exitCode int) {
+	return execute(name, args, false, true, stdin, stdout, stderr)
+}
+
+// execute runs a program to completion, then dumps its final state even
+// when execution stopped with an error. A profiled program's annotated
+// listing follows its final state
+func execute(
+	name string,
+	args []string,
+	traced bool,
+	profiled bool,
+	stdin io.Reader,
+	stdout io.Writer,
+	stderr io.Writer,
+) (exitCode int) {
+	flags := newFlagSet(name, "program", stderr)
+	execution := newExecutionFlags(flags, traced)
+
+	var pprofFile string
+	var programProfile *profile.Profile
+
+	if profiled {
+		flags.StringVar(
+			&pprofFile,
+			"pprof",
+			"",
+			"also write the profile to a `file` for go tool pprof",
+		)
+
+		programProfile = profile.New()
+		execution.configuration.Observers = append(
+			execution.configuration.Observers,
+			programProfile,
+		)
+	}
+
+	err := flags.Parse(args)
+	if err != nil {
+		return 2
+	}
+
+	m, exitCode := execution.boot(flags, traced, stdin, stdout, stderr)
+	if exitCode != 0 {
+		return exitCode
+	}
+	defer m.close()
+
+	runErr := m.processor.Run()
+
+	err = execution.saveCoverage(m)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return runExitCode(runErr, 1, stderr)
+	}
+
+	if execution.snapshotFile != "" {
+		err = writeSnapshot(execution.snapshotFile, m.processor)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return runExitCode(runErr, 1, stderr)
+		}
+	}
+
+	err = m.dump(m.dumpOutput, m.processor)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return runExitCode(runErr, 1, stderr)
+	}
+
+	if profiled {
+		exitCode = writeProfile(programProfile, m, pprofFile, flags, stderr)
+		if exitCode != 0 {
+			return runExitCode(runErr, exitCode, stderr)
+		}
+	}
+
+	return runExitCode(runErr, 0, stderr)
+}
+
+// runExitCode is 4 when execution exited unexpectedly, which wins over the
+// exitCode of writing its results, otherwise exitCode
+func runExitCode(runErr error, exitCode int, stderr io.Writer) int {
+	if runErr != nil {
+		fmt.Fprintln(stderr, runErr)
+		return 4
+	}
+
+	return exitCode
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool(
+	"update",
+	false,
+	"rewrite spec .stdout, .stderr and .exit files with actual output",
+)
+
+func TestSpecSuccesses(t *testing.T) {
+	testSpecs(t, "../spec-successes", true)
+}
+
+func TestSpecFailures(t *testing.T) {
+	testSpecs(t, "../spec-failures", false)
+}
+
+// programs are hex dumps, Intel HEX and S-records to cover those loaders,
+// assembly language to cover rcc asm, snapshots to cover -restore,
+// coverage files to cover rcc coverage, or the flags of rcc generate
+var programExtensions = []string{
+	".hex",
+	".ihex",
+	".srec",
+	".asm",
+	".snapshot",
+	".coverage",
+	".generate",
+}
+
+func testSpecs(t *testing.T, directory string, succeeds bool) {
+	var programPathnames []string
+
+	for _, extension := range programExtensions {
+		pathnames, err := filepath.Glob(filepath.Join(directory, "*"+extension))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		programPathnames = append(programPathnames, pathnames...)
+	}
+
+	if len(programPathnames) == 0 {
+		t.Fatalf("no specs found in %s", directory)
+	}
+
+	for _, programPathname := range programPathnames {
+		programPathname := programPathname
+		pathname := strings.TrimSuffix(
+			programPathname,
+			filepath.Ext(programPathname),
+		)
+
+		t.Run(filepath.Base(pathname), func(t *testing.T) {
+			testSpec(t, programPathname, pathname, succeeds)
+		})
+	}
+}
+
+func testSpec(
+	t *testing.T,
+	programPathname string,
+	pathname string,
+	succeeds bool,
+) {
+	args, err := readArguments(pathname+".args", programPathname)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// debug specs read commands from an optional .stdin file
+	var stdin io.Reader
+
+	stdinFile, err := os.Open(pathname + ".stdin")
+	if err == nil {
+		defer stdinFile.Close()
+		stdin = stdinFile
+	} else if !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+
+	exitCode := run(
+		append([]string{"rcc"}, args...),
+		stdin,
+		stdout,
+		stderr,
+	)
+
+	if succeeds && exitCode != 0 {
+		t.Errorf("exit code is %d, expected 0", exitCode)
+	}
+
+	if !succeeds && exitCode == 0 {
+		t.Errorf("exit code is 0, expected non-zero")
+	}
+
+	compareWithSpec(t, pathname+".stdout", stdout.String())
+	compareWithSpec(t, pathname+".stderr", stderr.String())
+	compareWithSpec(t, pathname+".exit", strconv.Itoa(exitCode))
+}
+
+// spec files hold output with trailing newlines trimmed, plus one newline
+func compareWithSpec(t *testing.T, specPathname string, actual string) {
+	actual = strings.TrimRight(actual, "\n")
+
+	if *update {
+		err := ioutil.WriteFile(specPathname, []byte(actual+"\n"), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return
+	}
+
+	expected, err := ioutil.ReadFile(specPathname)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.TrimRight(string(expected), "\n") != actual {
+		t.Errorf(
+			"%s differs, run go test ./rcc -update then git diff\n"+
+				"--- expected\n%s\n--- actual\n%s",
+			specPathname,
+			expected,
+			actual,
+		)
+	}
+}
+
+// readArguments returns the subcommand, the flags in an optional .args file
+// and the program pathname. When the .args file does not begin with a
+// subcommand, programs are traced without NOPs, .asm files assembled and
+// .coverage files reported. .snapshot files are restored in place of a
+// program. .generate files hold the flags of rcc generate, which reads no
+// program. Pathnames in .args files are relative to rcc/
+func readArguments(pathname string, programPathname string) ([]string, error) {
+	var args []string
+
+	contents, err := ioutil.ReadFile(pathname)
+	if err == nil {
+		args = strings.Fields(string(contents))
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	program := []string{programPathname}
+
+	switch filepath.Ext(programPathname) {
+	case ".asm":
+		args = withSubcommand(args, "asm")
+	case ".coverage":
+		args = withSubcommand(args, "coverage")
+	case ".generate":
+		contents, err := ioutil.ReadFile(programPathname)
+		if err != nil {
+			return nil, err
+		}
+
+		return append(withSubcommand(args, "generate"), strings.Fields(string(contents))...), nil
+	case ".snapshot":
+		args = withSubcommand(args, "trace", "-trace-skip", "NOP")
+		program = []string{"-restore", programPathname}
+	default:
+		args = withSubcommand(args, "trace", "-trace-skip", "NOP")
+	}
+
+	return append(args, program...), nil
+}
+
+// withSubcommand prepends defaultArgs unless args begins with a subcommand
+func withSubcommand(args []string, defaultArgs ...string) []string {
+	if len(args) > 0 {
+		if _, ok := subcommands()[args[0]]; ok {
+			return args
+		}
+	}
+
+	return append(defaultArgs, args...)
+}
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tmornini/rigetti-computing/memory"
+	"github.com/tmornini/rigetti-computing/processor"
+	"github.com/tmornini/rigetti-computing/symbolic"
+)
+
+// symbolicCommand explores every path a program can take for any values of
+// its inputs, looking for values satisfying a query, which it replays
+func symbolicCommand(
+	name string,
+	args []string,
+	stdin io.Reader,
+	stdout io.Writer,
+	stderr io.Writer,
+) (exitCode int) {
+	flags := newFlagSet(name, "program", stderr)
+
+	limits := symbolic.DefaultLimits
+
+	var inputsText string
+	var queryText string
+	var mainMemoryFile string
+
+	flags.StringVar(
+		&inputsText,
+		"inputs",
+		"",
+		"symbolic `inputs`, e.g. X,Y,[10],[20-2f]; others start at 0",
+	)
+	flags.StringVar(
+		&queryText,
+		"query",
+		"",
+		"`conditions` to satisfy joined by &&, e.g. \"halt && Z == 0\" or E",
+	)
+	flags.StringVar(
+		&mainMemoryFile,
+		"memory",
+		"",
+		"initialize ram from a binary, hex dump, Intel HEX or S-record `file`",
+	)
+	flags.Uint64Var(
+		&limits.MaxSteps,
+		"max-steps",
+		limits.MaxSteps,
+		"stop each path after this many `instructions`, 0 is unlimited",
+	)
+	flags.IntVar(
+		&limits.MaxPaths,
+		"max-paths",
+		limits.MaxPaths,
+		"stop after exploring this many `paths`, 0 is unlimited",
+	)
+
+	err := flags.Parse(args)
+	if err != nil {
+		return 2
+	}
+
+	if inputsText == "" || queryText == "" {
+		fmt.Fprintln(stderr, "-inputs and -query are required")
+		return 2
+	}
+
+	inputs, err := symbolic.ParseInputs(inputsText)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	query, err := symbolic.ParseQuery(queryText)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	programMemory, exitCode := readProgram(flags, stdin, stderr)
+	if exitCode != 0 {
+		return exitCode
+	}
+
+	return explore(
+		flags.Arg(0),
+		programMemory,
+		mainMemoryFile,
+		inputs,
+		query,
+		limits,
+		stdout,
+		stderr,
+	)
+}
+
+func explore(
+	program string,
+	programMemory *memory.ReadOnly,
+	mainMemoryFile string,
+	inputs []processor.Input,
+	query symbolic.Query,
+	limits symbolic.Limits,
+	stdout io.Writer,
+	stderr io.Writer,
+) (exitCode int) {
+	mainMemory := &memory.ReadWrite{}
+
+	if mainMemoryFile != "" {
+		file, err := os.Open(mainMemoryFile)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+
+		mainMemory, err = memory.NewMainMemoryFromImage(file)
+		file.Close()
+
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 3
+		}
+	}
+
+	result := symbolic.Explore(programMemory, mainMemory, inputs, query, limits)
+
+	if !result.Found {
+		if result.Complete {
+			fmt.Fprintf(
+				stdout,
+				"no inputs satisfy %s, on any of %s\n",
+				query,
+				paths(result.Paths),
+			)
+
+			return 0
+		}
+
+		// inconclusive, as unexplored paths may satisfy the query
+		fmt.Fprintf(
+			stdout,
+			"no inputs found satisfying %s on %s, "+
+				"but exploration stopped at a limit\n",
+			query,
+			paths(result.Paths),
+		)
+
+		return 6
+	}
+
+	err := symbolic.Replay(
+		programMemory,
+		mainMemory,
+		inputs,
+		result.Values,
+		query,
+		limits.MaxSteps,
+	)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 4
+	}
+
+	var assignments []string
+
+	for index, input := range inputs {
+		assignments = append(
+			assignments,
+			fmt.Sprintf("%s=%02x", input, result.Values[index]),
+		)
+	}
+
+	fmt.Fprintf(
+		stdout,
+		"%s is satisfied after %d steps with %s\n",
+		query,
+		result.Steps,
+		strings.Join(assignments, " "),
+	)
+
+	if program == "" {
+		program = "program"
+	}
+
+	fmt.Fprintf(
+		stdout,
+		"replay: rcc run %s\n",
+		strings.Join(replayFlags(inputs, result.Values, mainMemoryFile, program), " "),
+	)
+
+	return 0
+}
+
+func paths(count int) string {
+	if count == 1 {
+		return "1 path"
+	}
+
+	return fmt.Sprintf("%d paths", count)
+}
+
+// replayFlags presets the inputs for rcc run, writing consecutive main
+// memory inputs with one -memory-bytes
+func replayFlags(
+	inputs []processor.Input,
+	values []byte,
+	mainMemoryFile string,
+	program string,
+) []string {
+	var flags []string
+
+	if mainMemoryFile != "" {
+		flags = append(flags, "-memory", mainMemoryFile)
+	}
+
+	var mainMemory [256]bool
+	var preset memory.ReadWrite
+
+	for index, input := range inputs {
+		if !input.Memory {
+			flags = append(
+				flags,
+				"-"+strings.ToLower(input.String()),
+				fmt.Sprintf("%02x", values[index]),
+			)
+
+			continue
+		}
+
+		mainMemory[input.Index] = true
+		preset[input.Index] = values[index]
+	}
+
+	for address := 0; address < len(mainMemory); address++ {
+		if !mainMemory[address] {
+			continue
+		}
+
+		end := address
+
+		for end < len(mainMemory) && mainMemory[end] {
+			end++
+		}
+
+		flags = append(
+			flags,
+			"-memory-bytes",
+			fmt.Sprintf("%02x:%x", address, preset[address:end]),
+		)
+
+		address = end
+	}
+
+	return append(flags, filepath.Base(program))
+}
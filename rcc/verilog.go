@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/tmornini/rigetti-computing/memory"
+	"github.com/tmornini/rigetti-computing/rtl"
+)
+
+// verilogCommand writes the core generated for a program as Verilog, and
+// cross-checks it against the processor
+func verilogCommand(
+	name string,
+	args []string,
+	stdin io.Reader,
+	stdout io.Writer,
+	stderr io.Writer,
+) (exitCode int) {
+	flags := newFlagSet(name, "program", stderr)
+
+	var mainMemoryFile string
+	var testbench bool
+	var check bool
+	var maxCycles uint64
+
+	flags.StringVar(
+		&mainMemoryFile,
+		"memory",
+		"",
+		"initialize ram from a binary, hex dump, Intel HEX or S-record `file`",
+	)
+	flags.BoolVar(
+		&testbench,
+		"testbench",
+		false,
+		"also write a testbench that runs the core until it halts",
+	)
+	flags.BoolVar(
+		&check,
+		"check",
+		false,
+		"simulate the core, comparing it with the processor after every cycle",
+	)
+	flags.Uint64Var(
+		&maxCycles,
+		"max-cycles",
+		100000,
+		"stop the testbench and -check after this many `cycles`, 0 is unlimited",
+	)
+
+	err := flags.Parse(args)
+	if err != nil {
+		return 2
+	}
+
+	programMemory, exitCode := readProgram(flags, stdin, stderr)
+	if exitCode != 0 {
+		return exitCode
+	}
+
+	mainMemory := &memory.ReadWrite{}
+
+	if mainMemoryFile != "" {
+		file, err := os.Open(mainMemoryFile)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+
+		mainMemory, err = memory.NewMainMemoryFromImage(file)
+		file.Close()
+
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 3
+		}
+	}
+
+	program := "stdin"
+	if flags.NArg() > 0 {
+		program = filepath.Base(flags.Arg(0))
+	}
+
+	core := rtl.NewCore(programMemory, mainMemory)
+
+	err = core.WriteVerilog(
+		stdout,
+		"RCC core running "+program+", generated by "+name+"\n"+
+			"each rising edge of clk executes one instruction, until halted",
+	)
+	if err == nil && testbench {
+		err = core.WriteTestbench(stdout, maxCycles)
+	}
+
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	if !check {
+		return 0
+	}
+
+	cycles, err := rtl.CrossCheck(programMemory, mainMemory, maxCycles)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 4
+	}
+
+	fmt.Fprintf(stderr, "%d cycles match the processor\n", cycles)
+
+	return 0
+}
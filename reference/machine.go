@@ -0,0 +1,171 @@
+package reference
+
+// Machine is a deliberately simple RCC, written straight from the ISA in
+// the README and sharing no code with package processor, so the two can be
+// run in lockstep to check each other. It behaves as the processor does
+// with the default policies: divide by zero sets E and continues, other
+// faults set E and stop, and the PC wraps around past #xFF
+type Machine struct {
+	Program [256]byte
+	Memory  [256]byte
+
+	PC        byte
+	Registers [4]byte // X, Y, Z and W
+	C         bool
+	E         bool
+
+	// Output is what PRN printed. Values above #x7F are not ASCII, so they
+	// print as the Unicode character with that code point, in UTF-8
+	Output []byte
+
+	Halted  bool
+	Faulted bool // stopped by a fault
+}
+
+// New machine running program with main memory initialized to memory
+func New(program [256]byte, memory [256]byte) *Machine {
+	return &Machine{Program: program, Memory: memory}
+}
+
+// lengths of the sixteen instructions, in bytes
+var lengths = [16]int{1, 4, 4, 4, 4, 3, 3, 3, 3, 3, 3, 2, 2, 2, 2, 1}
+
+// Step executes one instruction, unless the machine has halted or faulted,
+// and reports whether it is still running
+func (m *Machine) Step() bool {
+	if m.Halted || m.Faulted {
+		return false
+	}
+
+	opcode := m.Program[m.PC]
+
+	if opcode > 0x0f {
+		return m.fault()
+	}
+
+	length := lengths[opcode]
+
+	if int(m.PC)+length > len(m.Program) {
+		return m.fault()
+	}
+
+	var operands [3]byte
+	copy(operands[:], m.Program[int(m.PC)+1:int(m.PC)+length])
+
+	r1, r2, r3 := operands[0], operands[1], operands[2]
+	r := &m.Registers
+	next := m.PC + byte(length)
+
+	switch opcode {
+	case 0x00: // NOP
+	case 0x01: // ADD r1 r2 r3
+		if !valid(r1, r2, r3) {
+			return m.fault()
+		}
+
+		r[r3] = r[r1] + r[r2]
+	case 0x02: // SUB r1 r2 r3
+		if !valid(r1, r2, r3) {
+			return m.fault()
+		}
+
+		r[r3] = r[r1] - r[r2]
+	case 0x03: // MUL r1 r2 r3
+		if !valid(r1, r2, r3) {
+			return m.fault()
+		}
+
+		r[r3] = r[r1] * r[r2]
+	case 0x04: // DIV r1 r2 r3
+		if !valid(r1, r2, r3) {
+			return m.fault()
+		}
+
+		if r[r2] == 0 {
+			m.E = true
+		} else {
+			r[r3] = r[r1] / r[r2]
+		}
+	case 0x05: // LDM r1 r2
+		if !valid(r1, r2) {
+			return m.fault()
+		}
+
+		r[r2] = m.Memory[r[r1]]
+	case 0x06: // LDI imm r1
+		if !valid(r2) {
+			return m.fault()
+		}
+
+		r[r2] = r1
+	case 0x07: // STR r1 r2
+		if !valid(r1, r2) {
+			return m.fault()
+		}
+
+		m.Memory[r[r2]] = r[r1]
+	case 0x08: // SWP r1 r2
+		if !valid(r1, r2) {
+			return m.fault()
+		}
+
+		r[r1], r[r2] = r[r2], r[r1]
+	case 0x09: // EQL r1 r2
+		if !valid(r1, r2) {
+			return m.fault()
+		}
+
+		m.C = r[r1] == r[r2]
+	case 0x0a: // NQL r1 r2
+		if !valid(r1, r2) {
+			return m.fault()
+		}
+
+		m.C = r[r1] != r[r2]
+	case 0x0b: // JMP imm
+		next = r1
+	case 0x0c: // JMC imm
+		if m.C {
+			next = r1
+			m.C = false
+		}
+	case 0x0d: // JME imm
+		if m.E {
+			next = r1
+			m.E = false
+		}
+	case 0x0e: // PRN r1
+		if !valid(r1) {
+			return m.fault()
+		}
+
+		m.Output = append(m.Output, string(rune(r[r1]))...)
+	case 0x0f: // HLT
+		m.Halted = true
+
+		return false
+	}
+
+	m.PC = next
+
+	return true
+}
+
+// fault sets E and stops the machine
+func (m *Machine) fault() bool {
+	m.E = true
+	m.Faulted = true
+
+	return false
+}
+
+// valid is whether each is a register, X to W
+func valid(registers ...byte) bool {
+	for _, register := range registers {
+		if register > 3 {
+			return false
+		}
+	}
+
+	return true
+}
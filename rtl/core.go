@@ -0,0 +1,308 @@
+package rtl
+
+import (
+	"strings"
+
+	"github.com/tmornini/rigetti-computing/memory"
+	"github.com/tmornini/rigetti-computing/processor"
+)
+
+// Core is the RCC as a single cycle design: each rising edge of clk
+// executes the instruction at pc, reading its opcode and parameters from
+// rom combinationally. It behaves as a processor.Processor with
+// processor.DefaultConfiguration does: divide by zero sets E and continues,
+// every other fault sets E and fault, then halts, and the PC wraps around
+type Core struct {
+	*Design
+
+	PC *Signal
+	X  *Signal
+	Y  *Signal
+	Z  *Signal
+	W  *Signal
+	C  *Signal
+	E  *Signal
+
+	Halted *Signal
+	Fault  *Signal
+
+	// PRN prints PrintChar on the rising edge when PrintValid is 1
+	PrintValid *Signal
+	PrintChar  *Signal
+
+	ROM *Memory
+	RAM *Memory
+}
+
+// NewCore generates the core with rom holding a program and ram main
+// memory. Opcodes, their mnemonics, lengths and operand kinds come from
+// the processor's tables, so the decoder follows the simulator
+func NewCore(
+	programMemory *memory.ReadOnly,
+	mainMemory *memory.ReadWrite,
+) *Core {
+	design := NewDesign("rcc_core")
+
+	core := &Core{
+		Design: design,
+
+		PC: design.Register("pc", 8, 0),
+		X:  design.Register("x", 8, 0),
+		Y:  design.Register("y", 8, 0),
+		Z:  design.Register("z", 8, 0),
+		W:  design.Register("w", 8, 0),
+		C:  design.Register("c", 1, 0),
+		E:  design.Register("e", 1, 0),
+
+		Halted: design.Register("halted", 1, 0),
+		Fault:  design.Register("fault", 1, 0),
+
+		ROM: design.Memory("rom", append([]byte(nil), programMemory[:]...)),
+		RAM: design.Memory("ram", append([]byte(nil), mainMemory[:]...)),
+	}
+
+	for _, signal := range []*Signal{
+		core.PC, core.X, core.Y, core.Z, core.W, core.C, core.E,
+		core.Halted, core.Fault,
+	} {
+		signal.Output = true
+	}
+
+	core.generate()
+
+	return core
+}
+
+// decoded opcode, and its operands by kind
+type decodedOpcode struct {
+	opcode byte
+	is     *Signal
+	kinds  string
+
+	registerOperands []int // indexes of the parameters that are registers
+}
+
+func (core *Core) generate() {
+	design := core.Design
+	byteOne := Const(8, 1)
+
+	opcode := design.Wire("opcode", core.ROM.Read(core.PC))
+
+	parameters := []*Signal{}
+	address := Expr(core.PC)
+
+	for index := 0; index < 3; index++ {
+		address = design.Wire(parameterName("address", index), Add(address, byteOne))
+		parameters = append(
+			parameters,
+			design.Wire(parameterName("operand", index), core.ROM.Read(address)),
+		)
+	}
+
+	decoded := map[string]*decodedOpcode{}
+	var opcodes []*decodedOpcode
+
+	for op := 0; op < 256; op++ {
+		name := processor.OpcodeName(byte(op))
+		if name == "???" {
+			continue
+		}
+
+		d := &decodedOpcode{
+			opcode: byte(op),
+			is:     design.Wire("is_"+strings.ToLower(name), Eq(opcode, Const(8, uint64(op)))),
+			kinds:  processor.OpcodeOperands(byte(op)),
+		}
+
+		for index, kind := range d.kinds {
+			if kind == 'r' {
+				d.registerOperands = append(d.registerOperands, index)
+			}
+		}
+
+		decoded[name] = d
+		opcodes = append(opcodes, d)
+	}
+
+	var knownOpcodes []Expr
+	var lengths []Case
+
+	for _, d := range opcodes {
+		knownOpcodes = append(knownOpcodes, d.is)
+		lengths = append(lengths, Case{
+			d.is,
+			Const(8, uint64(processor.InstructionLength(d.opcode))),
+		})
+	}
+
+	known := design.Wire("known", AnyOf(knownOpcodes...))
+	length := design.Wire("length", Select(byteOne, lengths...))
+
+	// the last byte of the instruction wraps around past #xFF
+	last := design.Wire("last", Sub(Add(core.PC, length), byteOne))
+	fetchBeyondEnd := design.Wire("fetch_beyond_end", Lt(last, core.PC))
+
+	// r1, r2 and r3 are the first, second and third register operands,
+	// wherever they are encoded, e.g. LDI's r1 is its second parameter
+	operandFields := make([]*Signal, 3)
+
+	for operand := range operandFields {
+		var cases []Case
+
+		for _, d := range opcodes {
+			if operand < len(d.registerOperands) && d.registerOperands[operand] != operand {
+				cases = append(cases, Case{d.is, parameters[d.registerOperands[operand]]})
+			}
+		}
+
+		operandFields[operand] = design.Wire(
+			parameterName("r", operand),
+			Select(parameters[operand], cases...),
+		)
+	}
+
+	imm := parameters[0]
+
+	var badRegisterCases []Expr
+
+	for _, d := range opcodes {
+		var bad []Expr
+
+		for _, index := range d.registerOperands {
+			bad = append(bad, Gt(parameters[index], Const(8, 3)))
+		}
+
+		if len(bad) > 0 {
+			badRegisterCases = append(badRegisterCases, And(d.is, AnyOf(bad...)))
+		}
+	}
+
+	badRegister := design.Wire("bad_register", AnyOf(badRegisterCases...))
+
+	abort := design.Wire(
+		"abort",
+		AnyOf(And(known, fetchBeyondEnd), Not(known), badRegister),
+	)
+	ok := design.Wire("ok", Not(abort))
+
+	registers := []*Signal{core.X, core.Y, core.Z, core.W}
+
+	read := func(name string, field Expr) *Signal {
+		cases := make([]Case, len(registers)-1)
+
+		for index := range cases {
+			cases[index] = Case{Eq(field, Const(8, uint64(index))), registers[index]}
+		}
+
+		return design.Wire(name, Select(registers[len(registers)-1], cases...))
+	}
+
+	a := read("a", operandFields[0])
+	b := read("b", operandFields[1])
+
+	divisorZero := design.Wire("divisor_zero", Eq(b, Const(8, 0)))
+	divisor := design.Wire("divisor", Mux(divisorZero, byteOne, b))
+
+	sum := design.Wire("sum", Add(a, b))
+	difference := design.Wire("difference", Sub(a, b))
+	product := design.Wire("product", Mul(a, b))
+	quotient := design.Wire("quotient", Div(a, divisor))
+	loaded := design.Wire("loaded", core.RAM.Read(a))
+	equal := design.Wire("equal", Eq(a, b))
+
+	is := func(name string) *Signal {
+		return decoded[name].is
+	}
+
+	divideByZero := design.Wire("divide_by_zero", AllOf(ok, is("DIV"), divisorZero))
+
+	c := core.C
+	e := core.E
+
+	jump := design.Wire(
+		"jump",
+		And(ok, AnyOf(is("JMP"), And(is("JMC"), c), And(is("JME"), e))),
+	)
+
+	// port a writes r3 for arithmetic, r2 for LDM and r1 for LDI and SWP.
+	// Port b writes SWP's r2
+	portA := []Case{
+		{is("ADD"), sum},
+		{is("SUB"), difference},
+		{is("MUL"), product},
+		{is("DIV"), quotient},
+		{is("LDM"), loaded},
+		{is("LDI"), imm},
+		{is("SWP"), b},
+	}
+
+	var portAOpcodes []Expr
+	for _, portACase := range portA {
+		portAOpcodes = append(portAOpcodes, portACase.Sel)
+	}
+
+	writeA := design.Wire(
+		"write_a",
+		AllOf(ok, AnyOf(portAOpcodes...), Not(divideByZero)),
+	)
+	writeARegister := design.Wire(
+		"write_a_register",
+		Select(
+			operandFields[0],
+			Case{AnyOf(is("ADD"), is("SUB"), is("MUL"), is("DIV")), operandFields[2]},
+			Case{is("LDM"), operandFields[1]},
+		),
+	)
+	writeAValue := design.Wire("write_a_value", Select(Const(8, 0), portA...))
+
+	writeB := design.Wire("write_b", And(ok, is("SWP")))
+
+	for index, register := range registers {
+		number := Const(8, uint64(index))
+
+		register.Next = Select(
+			register,
+			Case{And(writeB, Eq(operandFields[1], number)), a},
+			Case{And(writeA, Eq(writeARegister, number)), writeAValue},
+		)
+	}
+
+	core.PC.Next = Select(
+		Add(core.PC, length),
+		Case{AnyOf(abort, is("HLT")), core.PC},
+		Case{jump, imm},
+	)
+
+	c.Next = Select(
+		c,
+		Case{abort, c},
+		Case{is("EQL"), equal},
+		Case{is("NQL"), Not(equal)},
+		Case{And(is("JMC"), c), Bit(false)},
+	)
+
+	e.Next = Select(
+		e,
+		Case{AnyOf(abort, divideByZero), Bit(true)},
+		Case{And(is("JME"), e), Bit(false)},
+	)
+
+	core.Halted.Next = AnyOf(abort, is("HLT"))
+	core.Fault.Next = abort
+
+	core.RAM.WriteEnable = design.Wire("ram_write", And(ok, is("STR")))
+	core.RAM.WriteAddress = b
+	core.RAM.WriteData = a
+
+	core.PrintValid = design.Wire("print_valid", AllOf(Not(core.Halted), ok, is("PRN")))
+	core.PrintValid.Output = true
+
+	core.PrintChar = design.Wire("print_char", a)
+	core.PrintChar.Output = true
+
+	design.Enable = Not(core.Halted)
+}
+
+func parameterName(prefix string, index int) string {
+	return prefix + string(rune('1'+index))
+}
@@ -0,0 +1,132 @@
+package rtl
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/tmornini/rigetti-computing/memory"
+	"github.com/tmornini/rigetti-computing/processor"
+)
+
+// CrossCheck simulates the core generated for a program, clocking it once
+// for each instruction a processor.Processor with
+// processor.DefaultConfiguration processes, and compares their registers,
+// flags, main memory and output after every one. It stops when both halt
+// or fault, or after maxCycles, 0 being unlimited, returning the cycles
+// simulated, and a *MismatchError if they ever differ
+func CrossCheck(
+	programMemory *memory.ReadOnly,
+	mainMemory *memory.ReadWrite,
+	maxCycles uint64,
+) (uint64, error) {
+	core := NewCore(programMemory, mainMemory)
+
+	simulator, err := NewSimulator(core.Design)
+	if err != nil {
+		return 0, err
+	}
+
+	var output bytes.Buffer
+
+	configuration := processor.DefaultConfiguration
+	configuration.Output = &output
+	configuration.TraceOutput = ioutil.Discard
+
+	processorMainMemory := *mainMemory
+
+	p := processor.New(
+		processor.NormalInstructionSet,
+		configuration,
+		programMemory,
+		&processorMainMemory,
+	)
+
+	for maxCycles == 0 || simulator.Cycles() < maxCycles {
+		var printed []byte
+
+		if simulator.Value(core.PrintValid) != 0 {
+			printed = []byte(string(rune(simulator.Value(core.PrintChar))))
+		}
+
+		simulator.Clock()
+
+		halted, err := p.Step()
+
+		err = core.compare(simulator, p, halted, err, printed, output.Bytes())
+		if err != nil {
+			return simulator.Cycles(), err
+		}
+
+		output.Reset()
+
+		if simulator.Value(core.Halted) != 0 {
+			return simulator.Cycles(), nil
+		}
+	}
+
+	return simulator.Cycles(), nil
+}
+
+func (core *Core) compare(
+	simulator *Simulator,
+	p *processor.Processor,
+	halted bool,
+	err error,
+	printed []byte,
+	output []byte,
+) error {
+	registers := p.Registers()
+
+	states := []struct {
+		signal    *Signal
+		processor uint64
+	}{
+		{core.PC, uint64(registers.ProgramCounter)},
+		{core.X, uint64(registers.X)},
+		{core.Y, uint64(registers.Y)},
+		{core.Z, uint64(registers.Z)},
+		{core.W, uint64(registers.W)},
+		{core.C, boolean(registers.C)},
+		{core.E, boolean(registers.E)},
+		{core.Halted, boolean(halted || err != nil)},
+		{core.Fault, boolean(err != nil)},
+	}
+
+	for _, state := range states {
+		value := simulator.Value(state.signal)
+
+		if value != state.processor {
+			return &MismatchError{
+				Cycle:     simulator.Cycles(),
+				State:     state.signal.Name,
+				Design:    fmt.Sprintf("%02x", value),
+				Processor: fmt.Sprintf("%02x", state.processor),
+			}
+		}
+	}
+
+	if !bytes.Equal(printed, output) {
+		return &MismatchError{
+			Cycle:     simulator.Cycles(),
+			State:     "output",
+			Design:    fmt.Sprintf("%q", printed),
+			Processor: fmt.Sprintf("%q", output),
+		}
+	}
+
+	ram := simulator.Memory(core.RAM)
+
+	for address, value := range p.MainMemory() {
+		if ram[address] != value {
+			return &MismatchError{
+				Cycle:     simulator.Cycles(),
+				State:     fmt.Sprintf("ram[%02x]", address),
+				Design:    fmt.Sprintf("%02x", ram[address]),
+				Processor: fmt.Sprintf("%02x", value),
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package rtl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tmornini/rigetti-computing/memory"
+)
+
+// TestCrossCheck simulates the core generated for every spec program that
+// loads, comparing it with the processor after every instruction. Programs
+// that never halt are compared for a bounded number of cycles
+func TestCrossCheck(t *testing.T) {
+	var programs []string
+
+	for _, pattern := range []string{
+		"../spec-successes/*.hex",
+		"../spec-successes/*.ihex",
+		"../spec-successes/*.srec",
+		"../spec-failures/*.hex",
+	} {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		programs = append(programs, matches...)
+	}
+
+	if len(programs) == 0 {
+		t.Fatal("no spec programs found")
+	}
+
+	for _, program := range programs {
+		t.Run(filepath.Base(program), func(t *testing.T) {
+			file, err := os.Open(program)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer file.Close()
+
+			programMemory, err := memory.NewProgramFromImage(file)
+			if err != nil {
+				t.Skip(err)
+			}
+
+			_, err = CrossCheck(programMemory, &memory.ReadWrite{}, 100000)
+			if err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package rtl
+
+import "fmt"
+
+// Design is a synchronous circuit at register-transfer level: registers and
+// memories updated on the rising edge of clk, and the wires between them.
+// WriteVerilog writes it as a Verilog module, and Simulator executes it, so
+// what is simulated is what is synthesized
+type Design struct {
+	Name string
+
+	signals  []*Signal // inputs, registers and wires, in declaration order
+	memories []*Memory
+
+	// Enable gates every register and memory write, except reset
+	Enable Expr
+}
+
+// Signal is an input, register or wire, which expressions refer to by name
+type Signal struct {
+	Name   string
+	Kind   SignalKind
+	Output bool
+
+	Reset uint64 // registers, on reset
+	Next  Expr   // registers, their value after the rising edge of clk
+	Value Expr   // wires
+
+	width int
+	index int
+}
+
+// SignalKind distinguishes inputs, registers and wires
+type SignalKind int
+
+const (
+	// Input driven from outside the design, clk and reset
+	Input SignalKind = iota
+
+	// Register updated on the rising edge of clk
+	Register
+
+	// Wire combinational, continuously assigned
+	Wire
+)
+
+// Memory is an array of bytes, read combinationally and written on the
+// rising edge of clk when its write port is enabled
+type Memory struct {
+	Name    string
+	Initial []byte
+
+	WriteEnable  Expr // nil for a ROM
+	WriteAddress Expr
+	WriteData    Expr
+}
+
+// NewDesign has the clk and reset inputs every design has
+func NewDesign(name string) *Design {
+	design := &Design{Name: name}
+
+	design.Input("clk", 1)
+	design.Input("reset", 1)
+
+	return design
+}
+
+func (design *Design) add(signal *Signal) *Signal {
+	signal.index = len(design.signals)
+	design.signals = append(design.signals, signal)
+
+	return signal
+}
+
+// Input declares an input
+func (design *Design) Input(name string, width int) *Signal {
+	return design.add(&Signal{Name: name, width: width, Kind: Input})
+}
+
+// Register declares a register, whose Next must be set before the design
+// is written or simulated
+func (design *Design) Register(name string, width int, reset uint64) *Signal {
+	return design.add(
+		&Signal{Name: name, width: width, Kind: Register, Reset: reset},
+	)
+}
+
+// Wire declares a wire as wide as its value. Wires may only refer to
+// signals declared before them, so they can be evaluated in order
+func (design *Design) Wire(name string, value Expr) *Signal {
+	return design.add(
+		&Signal{Name: name, width: value.Width(), Kind: Wire, Value: value},
+	)
+}
+
+// Memory declares a memory of len(initial) bytes
+func (design *Design) Memory(name string, initial []byte) *Memory {
+	memory := &Memory{Name: name, Initial: initial}
+
+	design.memories = append(design.memories, memory)
+
+	return memory
+}
+
+// Signal looks up a signal by name
+func (design *Design) Signal(name string) (*Signal, bool) {
+	for _, signal := range design.signals {
+		if signal.Name == name {
+			return signal, true
+		}
+	}
+
+	return nil, false
+}
+
+// Signals in declaration order
+func (design *Design) Signals() []*Signal {
+	return design.signals
+}
+
+// Memories in declaration order
+func (design *Design) Memories() []*Memory {
+	return design.memories
+}
+
+// validate that every register has a next value and every expression's
+// operands are as wide as each other, as Verilog would otherwise extend
+// them silently
+func (design *Design) validate() error {
+	for _, signal := range design.signals {
+		switch signal.Kind {
+		case Register:
+			if signal.Next == nil {
+				return fmt.Errorf("%w: register %s has no next value", ErrInvalidDesign, signal.Name)
+			}
+
+			if signal.Next.Width() != signal.width {
+				return fmt.Errorf(
+					"%w: register %s is %d bits, its next value %d",
+					ErrInvalidDesign,
+					signal.Name,
+					signal.width,
+					signal.Next.Width(),
+				)
+			}
+
+			err := validateExpr(signal.Next)
+			if err != nil {
+				return fmt.Errorf("%w: register %s: %s", ErrInvalidDesign, signal.Name, err)
+			}
+		case Wire:
+			err := validateExpr(signal.Value)
+			if err != nil {
+				return fmt.Errorf("%w: wire %s: %s", ErrInvalidDesign, signal.Name, err)
+			}
+		}
+	}
+
+	for _, memory := range design.memories {
+		if memory.WriteEnable == nil {
+			continue
+		}
+
+		if memory.WriteEnable.Width() != 1 ||
+			memory.WriteAddress.Width() != 8 ||
+			memory.WriteData.Width() != 8 {
+			return fmt.Errorf("%w: memory %s write port has mismatched widths", ErrInvalidDesign, memory.Name)
+		}
+
+		for _, expr := range []Expr{
+			memory.WriteEnable,
+			memory.WriteAddress,
+			memory.WriteData,
+		} {
+			err := validateExpr(expr)
+			if err != nil {
+				return fmt.Errorf("%w: memory %s: %s", ErrInvalidDesign, memory.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,36 @@
+package rtl
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidDesign design that cannot be written or simulated
+var ErrInvalidDesign = errors.New("invalid design")
+
+// ErrMismatch design and processor differ
+var ErrMismatch = errors.New("design and processor differ")
+
+// MismatchError identifies the first state CrossCheck found different
+type MismatchError struct {
+	Cycle     uint64 // the clock cycle after which they differed
+	State     string // e.g. x, ram or output
+	Design    string
+	Processor string
+}
+
+func (err *MismatchError) Error() string {
+	return fmt.Sprintf(
+		"%s after cycle %d: %s is %s, processor's is %s",
+		ErrMismatch,
+		err.Cycle,
+		err.State,
+		err.Design,
+		err.Processor,
+	)
+}
+
+// Unwrap allows errors.Is(err, ErrMismatch)
+func (err *MismatchError) Unwrap() error {
+	return ErrMismatch
+}
@@ -0,0 +1,326 @@
+package rtl
+
+import "fmt"
+
+// Expr is a combinational expression, as wide as Width bits. Results are
+// truncated to Width, as assigning them to a wire of that width would
+type Expr interface {
+	Width() int
+
+	verilog() string
+	eval(simulator *Simulator) uint64
+}
+
+// Width of the signal, in bits
+func (signal *Signal) Width() int {
+	return signal.width
+}
+
+func (signal *Signal) verilog() string {
+	return signal.Name
+}
+
+func (signal *Signal) eval(simulator *Simulator) uint64 {
+	return simulator.values[signal.index]
+}
+
+type constant struct {
+	width int
+	value uint64
+}
+
+// Const is a constant of width bits
+func Const(width int, value uint64) Expr {
+	return constant{width: width, value: value & mask(width)}
+}
+
+// Bit is a 1 bit constant
+func Bit(value bool) Expr {
+	if value {
+		return Const(1, 1)
+	}
+
+	return Const(1, 0)
+}
+
+func (c constant) Width() int {
+	return c.width
+}
+
+func (c constant) verilog() string {
+	if c.width == 1 {
+		return fmt.Sprintf("1'b%d", c.value)
+	}
+
+	return fmt.Sprintf("%d'h%02x", c.width, c.value)
+}
+
+func (c constant) eval(simulator *Simulator) uint64 {
+	return c.value
+}
+
+type binary struct {
+	operator string
+	a        Expr
+	b        Expr
+}
+
+var comparisons = map[string]bool{
+	"==": true,
+	"!=": true,
+	"<":  true,
+	">":  true,
+}
+
+func (expr binary) Width() int {
+	if comparisons[expr.operator] {
+		return 1
+	}
+
+	return expr.a.Width()
+}
+
+func (expr binary) verilog() string {
+	return "(" + expr.a.verilog() + " " + expr.operator + " " + expr.b.verilog() + ")"
+}
+
+func (expr binary) eval(simulator *Simulator) uint64 {
+	a := expr.a.eval(simulator)
+	b := expr.b.eval(simulator)
+
+	var result uint64
+
+	switch expr.operator {
+	case "+":
+		result = a + b
+	case "-":
+		result = a - b
+	case "*":
+		result = a * b
+	case "/":
+		if b != 0 {
+			result = a / b
+		}
+	case "&":
+		result = a & b
+	case "|":
+		result = a | b
+	case "==":
+		result = boolean(a == b)
+	case "!=":
+		result = boolean(a != b)
+	case "<":
+		result = boolean(a < b)
+	case ">":
+		result = boolean(a > b)
+	}
+
+	return result & mask(expr.Width())
+}
+
+// Add a + b, wrapping around
+func Add(a, b Expr) Expr { return binary{"+", a, b} }
+
+// Sub a - b, wrapping around
+func Sub(a, b Expr) Expr { return binary{"-", a, b} }
+
+// Mul a * b, wrapping around
+func Mul(a, b Expr) Expr { return binary{"*", a, b} }
+
+// Div a / b, which b must not be 0 for, as Verilog's result is x
+func Div(a, b Expr) Expr { return binary{"/", a, b} }
+
+// And a & b
+func And(a, b Expr) Expr { return binary{"&", a, b} }
+
+// Or a | b
+func Or(a, b Expr) Expr { return binary{"|", a, b} }
+
+// Eq a == b, 1 bit
+func Eq(a, b Expr) Expr { return binary{"==", a, b} }
+
+// Ne a != b, 1 bit
+func Ne(a, b Expr) Expr { return binary{"!=", a, b} }
+
+// Lt a < b, unsigned, 1 bit
+func Lt(a, b Expr) Expr { return binary{"<", a, b} }
+
+// Gt a > b, unsigned, 1 bit
+func Gt(a, b Expr) Expr { return binary{">", a, b} }
+
+// AnyOf ors 1 bit expressions, 1'b0 when there are none
+func AnyOf(exprs ...Expr) Expr {
+	if len(exprs) == 0 {
+		return Bit(false)
+	}
+
+	result := exprs[0]
+
+	for _, expr := range exprs[1:] {
+		result = Or(result, expr)
+	}
+
+	return result
+}
+
+// AllOf ands 1 bit expressions, 1'b1 when there are none
+func AllOf(exprs ...Expr) Expr {
+	if len(exprs) == 0 {
+		return Bit(true)
+	}
+
+	result := exprs[0]
+
+	for _, expr := range exprs[1:] {
+		result = And(result, expr)
+	}
+
+	return result
+}
+
+type not struct {
+	a Expr
+}
+
+// Not inverts a 1 bit expression
+func Not(a Expr) Expr {
+	return not{a}
+}
+
+func (expr not) Width() int {
+	return 1
+}
+
+func (expr not) verilog() string {
+	return "!" + expr.a.verilog()
+}
+
+func (expr not) eval(simulator *Simulator) uint64 {
+	return boolean(expr.a.eval(simulator) == 0)
+}
+
+type mux struct {
+	sel Expr
+	a   Expr
+	b   Expr
+}
+
+// Mux is a when sel is 1, otherwise b
+func Mux(sel, a, b Expr) Expr {
+	return mux{sel, a, b}
+}
+
+func (expr mux) Width() int {
+	return expr.a.Width()
+}
+
+func (expr mux) verilog() string {
+	return "(" + expr.sel.verilog() + " ? " + expr.a.verilog() + " : " + expr.b.verilog() + ")"
+}
+
+func (expr mux) eval(simulator *Simulator) uint64 {
+	if expr.sel.eval(simulator) != 0 {
+		return expr.a.eval(simulator)
+	}
+
+	return expr.b.eval(simulator)
+}
+
+// Case is value when sel is 1
+type Case struct {
+	Sel   Expr
+	Value Expr
+}
+
+// Select is the value of the first case selected, otherwise otherwise
+func Select(otherwise Expr, cases ...Case) Expr {
+	result := otherwise
+
+	for index := len(cases) - 1; index >= 0; index-- {
+		result = Mux(cases[index].Sel, cases[index].Value, result)
+	}
+
+	return result
+}
+
+type memoryRead struct {
+	memory  *Memory
+	address Expr
+}
+
+// Read a byte of memory, combinationally
+func (memory *Memory) Read(address Expr) Expr {
+	return memoryRead{memory, address}
+}
+
+func (expr memoryRead) Width() int {
+	return 8
+}
+
+func (expr memoryRead) verilog() string {
+	return expr.memory.Name + "[" + expr.address.verilog() + "]"
+}
+
+func (expr memoryRead) eval(simulator *Simulator) uint64 {
+	return uint64(simulator.memories[expr.memory][expr.address.eval(simulator)])
+}
+
+// validateExpr checks the operands of every operator are as wide as each
+// other, and that selects and memory addresses are as wide as they must be
+func validateExpr(expr Expr) error {
+	switch e := expr.(type) {
+	case binary:
+		if e.a.Width() != e.b.Width() {
+			return fmt.Errorf(
+				"%s has %d and %d bit operands",
+				e.verilog(),
+				e.a.Width(),
+				e.b.Width(),
+			)
+		}
+
+		for _, operand := range []Expr{e.a, e.b} {
+			err := validateExpr(operand)
+			if err != nil {
+				return err
+			}
+		}
+	case not:
+		if e.a.Width() != 1 {
+			return fmt.Errorf("%s is not 1 bit", e.a.verilog())
+		}
+
+		return validateExpr(e.a)
+	case mux:
+		if e.sel.Width() != 1 || e.a.Width() != e.b.Width() {
+			return fmt.Errorf("%s has mismatched widths", e.verilog())
+		}
+
+		for _, operand := range []Expr{e.sel, e.a, e.b} {
+			err := validateExpr(operand)
+			if err != nil {
+				return err
+			}
+		}
+	case memoryRead:
+		if e.address.Width() != 8 {
+			return fmt.Errorf("%s address is not 8 bits", e.verilog())
+		}
+
+		return validateExpr(e.address)
+	}
+
+	return nil
+}
+
+func mask(width int) uint64 {
+	return 1<<uint(width) - 1
+}
+
+func boolean(value bool) uint64 {
+	if value {
+		return 1
+	}
+
+	return 0
+}
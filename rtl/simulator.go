@@ -0,0 +1,134 @@
+package rtl
+
+// Simulator executes a design a clock cycle at a time, as a Verilog
+// simulator would execute WriteVerilog's module
+type Simulator struct {
+	design   *Design
+	values   []uint64
+	memories map[*Memory][]byte
+	cycles   uint64
+}
+
+// NewSimulator starts a design with reset deasserted, its registers holding
+// their reset values and its memories their initial contents
+func NewSimulator(design *Design) (*Simulator, error) {
+	err := design.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	simulator := &Simulator{
+		design:   design,
+		values:   make([]uint64, len(design.signals)),
+		memories: map[*Memory][]byte{},
+	}
+
+	for _, memory := range design.memories {
+		simulator.memories[memory] = append([]byte(nil), memory.Initial...)
+	}
+
+	for _, signal := range design.signals {
+		if signal.Kind == Register {
+			simulator.values[signal.index] = signal.Reset
+		}
+	}
+
+	simulator.settle()
+
+	return simulator, nil
+}
+
+// settle evaluates every wire, in declaration order
+func (simulator *Simulator) settle() {
+	for _, signal := range simulator.design.signals {
+		if signal.Kind == Wire {
+			simulator.values[signal.index] = signal.Value.eval(simulator)
+		}
+	}
+}
+
+// Clock applies a rising edge of clk. Every register and memory write
+// takes the value its expression had before the edge
+func (simulator *Simulator) Clock() {
+	simulator.cycles++
+
+	design := simulator.design
+
+	reset, _ := design.Signal("reset")
+
+	if simulator.values[reset.index] != 0 {
+		for _, signal := range design.signals {
+			if signal.Kind == Register {
+				simulator.values[signal.index] = signal.Reset
+			}
+		}
+
+		simulator.settle()
+
+		return
+	}
+
+	if design.Enable != nil && design.Enable.eval(simulator) == 0 {
+		return
+	}
+
+	next := make([]uint64, len(simulator.values))
+
+	for _, signal := range design.signals {
+		if signal.Kind == Register {
+			next[signal.index] = signal.Next.eval(simulator)
+		}
+	}
+
+	type write struct {
+		memory  []byte
+		address uint64
+		data    byte
+	}
+
+	var writes []write
+
+	for _, memory := range design.memories {
+		if memory.WriteEnable != nil && memory.WriteEnable.eval(simulator) != 0 {
+			writes = append(writes, write{
+				memory:  simulator.memories[memory],
+				address: memory.WriteAddress.eval(simulator),
+				data:    byte(memory.WriteData.eval(simulator)),
+			})
+		}
+	}
+
+	for _, signal := range design.signals {
+		if signal.Kind == Register {
+			simulator.values[signal.index] = next[signal.index]
+		}
+	}
+
+	for _, w := range writes {
+		w.memory[w.address] = w.data
+	}
+
+	simulator.settle()
+}
+
+// SetInput drives an input, e.g. reset
+func (simulator *Simulator) SetInput(signal *Signal, value uint64) {
+	simulator.values[signal.index] = value & mask(signal.width)
+
+	simulator.settle()
+}
+
+// Value of a signal, as of the last clock edge
+func (simulator *Simulator) Value(signal *Signal) uint64 {
+	return simulator.values[signal.index]
+}
+
+// Memory contents, as of the last clock edge
+func (simulator *Simulator) Memory(memory *Memory) []byte {
+	return simulator.memories[memory]
+}
+
+// Cycles clocked
+func (simulator *Simulator) Cycles() uint64 {
+	return simulator.cycles
+}
@@ -0,0 +1,270 @@
+package rtl
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteVerilog writes the design as a synthesizable Verilog-2001 module.
+// Every wire is continuously assigned, every register and memory write is
+// in a single always block clocked by clk, with reset synchronous, and
+// memories are initialized in an initial block, as FPGA tools infer ROM and
+// RAM from
+func (design *Design) WriteVerilog(writer io.Writer, comment string) error {
+	err := design.validate()
+	if err != nil {
+		return err
+	}
+
+	v := &verilogWriter{writer: writer}
+
+	for _, line := range strings.Split(comment, "\n") {
+		v.line("// %s", line)
+	}
+
+	v.line("")
+	v.line("`default_nettype none")
+	v.line("")
+	v.line("module %s (", design.Name)
+
+	var ports []string
+
+	for _, signal := range design.signals {
+		switch {
+		case signal.Kind == Input:
+			ports = append(ports, "input  wire "+widthRange(signal.width)+signal.Name)
+		case signal.Output && signal.Kind == Register:
+			ports = append(ports, "output reg  "+widthRange(signal.width)+signal.Name)
+		case signal.Output:
+			ports = append(ports, "output wire "+widthRange(signal.width)+signal.Name)
+		}
+	}
+
+	for index, port := range ports {
+		if index < len(ports)-1 {
+			port += ","
+		}
+
+		v.line("    %s", port)
+	}
+
+	v.line(");")
+
+	design.writeMemories(v)
+	design.writeDeclarations(v)
+	design.writeAlways(v)
+
+	v.line("endmodule")
+	v.line("")
+	v.line("`default_nettype wire")
+
+	return v.err
+}
+
+func (design *Design) writeMemories(v *verilogWriter) {
+	if len(design.memories) == 0 {
+		return
+	}
+
+	v.line("")
+
+	for _, memory := range design.memories {
+		v.line("    reg [7:0] %s [0:%d];", memory.Name, len(memory.Initial)-1)
+	}
+
+	v.line("")
+	v.line("    integer i;")
+	v.line("")
+	v.line("    initial begin")
+
+	for _, memory := range design.memories {
+		v.line("        for (i = 0; i < %d; i = i + 1) %s[i] = 8'h00;", len(memory.Initial), memory.Name)
+
+		for address, value := range memory.Initial {
+			if value != 0 {
+				v.line("        %s[8'h%02x] = 8'h%02x;", memory.Name, address, value)
+			}
+		}
+	}
+
+	v.line("    end")
+}
+
+func (design *Design) writeDeclarations(v *verilogWriter) {
+	v.line("")
+
+	for _, signal := range design.signals {
+		switch {
+		case signal.Kind == Register && !signal.Output:
+			v.line("    reg  %s%s;", widthRange(signal.width), signal.Name)
+		case signal.Kind == Wire && signal.Output:
+			v.line("    assign %s = %s;", signal.Name, trimParentheses(signal.Value.verilog()))
+		case signal.Kind == Wire:
+			v.line(
+				"    wire %s%s = %s;",
+				widthRange(signal.width),
+				signal.Name,
+				trimParentheses(signal.Value.verilog()),
+			)
+		}
+	}
+}
+
+func (design *Design) writeAlways(v *verilogWriter) {
+	v.line("")
+	v.line("    always @(posedge clk) begin")
+	v.line("        if (reset) begin")
+
+	for _, signal := range design.signals {
+		if signal.Kind == Register {
+			v.line("            %s <= %s;", signal.Name, Const(signal.width, signal.Reset).verilog())
+		}
+	}
+
+	enable := "1'b1"
+	if design.Enable != nil {
+		enable = trimParentheses(design.Enable.verilog())
+	}
+
+	v.line("        end else if (%s) begin", enable)
+
+	for _, signal := range design.signals {
+		if signal.Kind == Register {
+			v.line("            %s <= %s;", signal.Name, trimParentheses(signal.Next.verilog()))
+		}
+	}
+
+	for _, memory := range design.memories {
+		if memory.WriteEnable == nil {
+			continue
+		}
+
+		v.line("")
+		v.line("            if (%s)", trimParentheses(memory.WriteEnable.verilog()))
+		v.line(
+			"                %s[%s] <= %s;",
+			memory.Name,
+			memory.WriteAddress.verilog(),
+			trimParentheses(memory.WriteData.verilog()),
+		)
+	}
+
+	v.line("        end")
+	v.line("    end")
+}
+
+// WriteTestbench writes a module that resets the core, clocks it until it
+// halts or maxCycles, writing print_char whenever print_valid, then displays
+// the final registers and flags as rcc does, for simulators such as Icarus
+// Verilog
+func (core *Core) WriteTestbench(writer io.Writer, maxCycles uint64) error {
+	v := &verilogWriter{writer: writer}
+
+	v.line("")
+	v.line("module %s_tb;", core.Name)
+	v.line("    reg clk = 1'b0;")
+	v.line("    reg reset = 1'b1;")
+	v.line("")
+
+	var connections []string
+
+	for _, signal := range core.signals {
+		switch {
+		case signal.Kind == Input:
+			connections = append(connections, "."+signal.Name+"("+signal.Name+")")
+		case signal.Output:
+			v.line("    wire %s%s;", widthRange(signal.width), signal.Name)
+			connections = append(connections, "."+signal.Name+"("+signal.Name+")")
+		}
+	}
+
+	v.line("")
+	v.line("    %s core (", core.Name)
+
+	for index, connection := range connections {
+		if index < len(connections)-1 {
+			connection += ","
+		}
+
+		v.line("        %s", connection)
+	}
+
+	v.line("    );")
+	v.line("")
+	v.line("    always #5 clk = !clk;")
+	v.line("")
+	v.line("    integer cycles = 0;")
+	v.line("")
+	v.line("    always @(posedge clk) begin")
+	v.line("        if (!reset && !halted) begin")
+	v.line("            cycles = cycles + 1;")
+	v.line("")
+	v.line("            if (print_valid)")
+	v.line("                $write(\"%%c\", print_char);")
+	v.line("        end")
+	v.line("    end")
+	v.line("")
+	v.line("    initial begin")
+	v.line("        @(posedge clk);")
+	v.line("        #1 reset = 1'b0;")
+	v.line("")
+	v.line("        wait (halted || cycles == %d);", maxCycles)
+	v.line("        @(negedge clk);")
+	v.line("")
+	v.line("        $display(\"\");")
+	v.line("        $display(\"PC:%%02x   X:%%02x   Y:%%02x   Z:%%02x   W:%%02x   C:%%s   E:%%s\",")
+	v.line("            pc, x, y, z, w, c ? \"t\" : \"f\", e ? \"t\" : \"f\");")
+	v.line("        $display(\"%%0d cycles%%s\", cycles, fault ? \", faulted\" : \"\");")
+	v.line("        $finish;")
+	v.line("    end")
+	v.line("endmodule")
+
+	return v.err
+}
+
+type verilogWriter struct {
+	writer io.Writer
+	err    error
+}
+
+func (v *verilogWriter) line(format string, arguments ...interface{}) {
+	if v.err != nil {
+		return
+	}
+
+	_, v.err = fmt.Fprintf(v.writer, format+"\n", arguments...)
+}
+
+func widthRange(width int) string {
+	if width == 1 {
+		return "      "
+	}
+
+	return fmt.Sprintf("[%d:0] ", width-1)
+}
+
+// trimParentheses removes the parentheses around a whole expression,
+// which assignments do not need
+func trimParentheses(expression string) string {
+	if !strings.HasPrefix(expression, "(") || !strings.HasSuffix(expression, ")") {
+		return expression
+	}
+
+	depth := 0
+
+	for index, character := range expression {
+		switch character {
+		case '(':
+			depth++
+		case ')':
+			depth--
+
+			if depth == 0 && index < len(expression)-1 {
+				return expression
+			}
+		}
+	}
+
+	return expression[1 : len(expression)-1]
+}
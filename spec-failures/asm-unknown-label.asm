@@ -0,0 +1 @@
+        JMP NOWHERE
@@ -0,0 +1,11 @@
+; data between instructions, which rcc disasm would take for an LDI that
+; swallows the first byte of START, and misreads what follows
+
+        LDI 0x01 X
+        JMP START
+        .byte 0x06 0x69
+START:  LDI 0x01 Y
+        EQL X Y
+        JMC DONE        ; taken
+        NOP
+DONE:   HLT
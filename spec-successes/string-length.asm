@@ -0,0 +1,17 @@
+; assignment-2 without its LDI/STR prologue: rcc trace -memory-bytes 00:486921 prints Hi!
+; and leaves the length of the string in Z
+
+        LDI 0x00 X      ; X is character address
+        LDI 0x00 Z      ; Z is character count
+LOOP:   LDM X Y         ; Y is character at address
+        LDI 0x00 W
+        EQL Y W         ; last character?
+        JMC DONE
+        PRN Y
+        LDI 0x01 W
+        ADD X, W, X     ; commas are optional
+        ADD Z W Z       // increment character count
+        JMP LOOP
+
+        .org 0xf0
+DONE:   HLT
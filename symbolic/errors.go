@@ -0,0 +1,66 @@
+package symbolic
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidInput input that is neither a register nor main memory
+var ErrInvalidInput = errors.New(
+	"invalid input, must be X, Y, Z, W, [address] or [first-last]",
+)
+
+// ErrInvalidQuery query that cannot be parsed
+var ErrInvalidQuery = errors.New("invalid query")
+
+// ErrReplayMismatch inputs that do not satisfy the query when replayed
+var ErrReplayMismatch = errors.New("replay does not satisfy the query")
+
+// InputError identifies the invalid input
+type InputError struct {
+	Input  string
+	Reason string
+}
+
+func (err *InputError) Error() string {
+	if err.Reason != "" {
+		return fmt.Sprintf("%s: %s, %s", ErrInvalidInput, err.Input, err.Reason)
+	}
+
+	return fmt.Sprintf("%s: %s", ErrInvalidInput, err.Input)
+}
+
+// Unwrap allows errors.Is(err, ErrInvalidInput)
+func (err *InputError) Unwrap() error {
+	return ErrInvalidInput
+}
+
+// QueryError identifies the invalid term of a query
+type QueryError struct {
+	Term   string
+	Reason string
+}
+
+func (err *QueryError) Error() string {
+	return fmt.Sprintf("%s: %q: %s", ErrInvalidQuery, err.Term, err.Reason)
+}
+
+// Unwrap allows errors.Is(err, ErrInvalidQuery)
+func (err *QueryError) Unwrap() error {
+	return ErrInvalidQuery
+}
+
+// ReplayError is how the replay ended
+type ReplayError struct {
+	Steps   uint64
+	Outcome string
+}
+
+func (err *ReplayError) Error() string {
+	return fmt.Sprintf("%s: %s after %d steps", ErrReplayMismatch, err.Outcome, err.Steps)
+}
+
+// Unwrap allows errors.Is(err, ErrReplayMismatch)
+func (err *ReplayError) Unwrap() error {
+	return ErrReplayMismatch
+}
@@ -0,0 +1,347 @@
+package symbolic
+
+import (
+	"github.com/tmornini/rigetti-computing/memory"
+	"github.com/tmornini/rigetti-computing/processor"
+)
+
+// Limits bound exploration, so programs with loops that depend on symbols
+// are answered in reasonable time. Zero is unlimited
+type Limits struct {
+	MaxSteps uint64 // instructions on each path
+	MaxPaths int
+
+	// MaxTests is how many conditions the solver may test for each query
+	// it answers, before giving up
+	MaxTests int
+}
+
+// DefaultLimits explore up to 10,000 paths of 10,000 instructions
+var DefaultLimits = Limits{
+	MaxSteps: 10000,
+	MaxPaths: 10000,
+	MaxTests: 1 << 24,
+}
+
+// Result of exploring a query
+type Result struct {
+	Found  bool
+	Inputs []processor.Input
+	Values []byte // of Inputs, when Found
+	Steps  uint64 // instructions executed before the goal was reached
+
+	Paths int // paths explored, each ending at HLT, a fault or a limit
+
+	// Complete is true when every path was explored to HLT or a fault, and
+	// the solver answered every question, so when nothing was Found no
+	// inputs satisfy the query
+	Complete bool
+}
+
+// state of one path through the program
+type state struct {
+	programCounter memory.Address
+	registers      [4]Expr
+	c              Bool
+	e              bool
+	mainMemory     [256]Expr
+
+	conditions []Bool // the path is taken when they all hold
+	values     []byte // of the symbols, satisfying conditions
+	steps      uint64
+}
+
+func (s *state) fork() *state {
+	forked := *s
+	forked.conditions = append([]Bool{}, s.conditions...)
+
+	return &forked
+}
+
+type explorer struct {
+	programMemory *memory.ReadOnly
+	query         Query
+	limits        Limits
+	solver        solver
+	result        *Result
+}
+
+// Explore executes the program on every path the inputs can take it, as
+// the processor does with the default policies, until it finds values of
+// the inputs satisfying the query. Other registers and flags start at 0,
+// and main memory as mainMemory
+func Explore(
+	programMemory *memory.ReadOnly,
+	mainMemory *memory.ReadWrite,
+	inputs []processor.Input,
+	query Query,
+	limits Limits,
+) *Result {
+	initial := &state{
+		c:      boolConstant(false),
+		values: make([]byte, len(inputs)),
+	}
+
+	for index := range initial.registers {
+		initial.registers[index] = constant(0)
+	}
+
+	for address, value := range mainMemory {
+		initial.mainMemory[address] = constant(value)
+	}
+
+	for index, input := range inputs {
+		if input.Memory {
+			initial.mainMemory[input.Index] = symbol(index)
+		} else {
+			initial.registers[input.Index] = symbol(index)
+		}
+	}
+
+	e := &explorer{
+		programMemory: programMemory,
+		query:         query,
+		limits:        limits,
+		solver:        solver{numberOfSymbols: len(inputs)},
+		result:        &Result{Inputs: inputs, Complete: true},
+	}
+
+	pending := []*state{initial}
+
+	for len(pending) > 0 && !e.result.Found {
+		if limits.MaxPaths != 0 && e.result.Paths == limits.MaxPaths {
+			e.result.Complete = false
+			break
+		}
+
+		s := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+
+		pending = append(pending, e.run(s)...)
+	}
+
+	return e.result
+}
+
+// run executes a path until it ends or forks, returning the paths it
+// forks into
+func (e *explorer) run(s *state) []*state {
+	for {
+		if e.query.Goal == Ever && e.reached(s) {
+			return nil
+		}
+
+		if e.limits.MaxSteps != 0 && s.steps == e.limits.MaxSteps {
+			e.result.Paths++
+			e.result.Complete = false
+
+			return nil
+		}
+
+		next, ended := e.step(s)
+
+		if ended {
+			e.result.Paths++
+
+			return nil
+		}
+
+		if len(next) != 1 {
+			return next
+		}
+
+		s = next[0]
+	}
+}
+
+// reached records the values of the symbols when the query's conditions
+// can hold in state
+func (e *explorer) reached(s *state) bool {
+	conditions := s.conditions
+
+	for _, condition := range e.query.Conditions {
+		symbolic := condition.symbolic(s)
+
+		if symbolic == boolConstant(false) {
+			return false
+		}
+
+		if symbolic != boolConstant(true) {
+			conditions = append(conditions[:len(conditions):len(conditions)], symbolic)
+		}
+	}
+
+	values, solution := e.solve(conditions, s.values)
+	if solution != satisfiable {
+		return false
+	}
+
+	e.result.Found = true
+	e.result.Values = values
+	e.result.Steps = s.steps
+
+	return true
+}
+
+func (e *explorer) solve(conditions []Bool, hint []byte) ([]byte, solution) {
+	e.solver.budget = e.limits.MaxTests
+	if e.solver.budget == 0 {
+		e.solver.budget = int(^uint(0) >> 1)
+	}
+
+	values, solution := e.solver.solve(conditions, hint)
+	if solution == unknown {
+		e.result.Complete = false
+	}
+
+	return values, solution
+}
+
+// branch forks state into the paths where condition holds and where it
+// does not, nil when a path cannot be taken
+func (e *explorer) branch(s *state, condition Bool) (whenTrue, whenFalse *state) {
+	switch condition {
+	case boolConstant(true):
+		return s, nil
+	case boolConstant(false):
+		return nil, s
+	}
+
+	sides := []*state{nil, nil}
+
+	for index, side := range []Bool{condition, newNegation(condition)} {
+		forked := s.fork()
+		forked.conditions = append(forked.conditions, side)
+
+		values, solution := e.solve(forked.conditions, s.values)
+
+		switch solution {
+		case satisfiable:
+			forked.values = values
+			sides[index] = forked
+		case unknown:
+			// explored, as the path may be feasible; the query is only
+			// answered from conditions the solver satisfies
+			sides[index] = forked
+		}
+	}
+
+	return sides[0], sides[1]
+}
+
+// step executes an instruction, returning the states it leads to, or
+// whether the path ended
+func (e *explorer) step(s *state) (next []*state, ended bool) {
+	instruction := processor.Disassemble(e.programMemory, s.programCounter)
+
+	s.steps++
+
+	if instruction.Err != nil {
+		s.e = true
+
+		if e.query.Goal == Fault || e.query.Goal == Ever {
+			e.reached(s)
+		}
+
+		return nil, true
+	}
+
+	operands := instruction.Bytes[1:]
+	advance := memory.Address(len(instruction.Bytes))
+	r := &s.registers
+
+	switch instruction.Bytes[0] {
+	case processor.ADD:
+		r[operands[2]] = newBinary(add, r[operands[0]], r[operands[1]])
+	case processor.SUB:
+		r[operands[2]] = newBinary(sub, r[operands[0]], r[operands[1]])
+	case processor.MUL:
+		r[operands[2]] = newBinary(mul, r[operands[0]], r[operands[1]])
+	case processor.DIV:
+		zero := newComparison(equal, r[operands[1]], constant(0))
+		byZero, quotient := e.branch(s, zero)
+
+		if quotient != nil {
+			q := &quotient.registers
+			q[operands[2]] = newBinary(div, q[operands[0]], q[operands[1]])
+		}
+
+		if byZero != nil {
+			byZero.e = true
+		}
+
+		return advanced(advance, byZero, quotient), false
+	case processor.LDM:
+		r[operands[1]] = newLoad(r[operands[0]], &s.mainMemory)
+	case processor.LDI:
+		r[operands[1]] = constant(operands[0])
+	case processor.STR:
+		s.store(r[operands[1]], r[operands[0]])
+	case processor.SWP:
+		r[operands[0]], r[operands[1]] = r[operands[1]], r[operands[0]]
+	case processor.EQL:
+		s.c = newComparison(equal, r[operands[0]], r[operands[1]])
+	case processor.NQL:
+		s.c = newComparison(notEqual, r[operands[0]], r[operands[1]])
+	case processor.JMP:
+		s.programCounter = memory.Address(operands[0])
+		advance = 0
+	case processor.JMC:
+		taken, notTaken := e.branch(s, s.c)
+
+		if taken != nil {
+			taken.programCounter = memory.Address(operands[0])
+			taken.c = boolConstant(false)
+		}
+
+		return append(advanced(0, taken), advanced(advance, notTaken)...), false
+	case processor.JME:
+		if s.e {
+			s.programCounter = memory.Address(operands[0])
+			s.e = false
+			advance = 0
+		}
+	case processor.HLT:
+		if e.query.Goal == Halt {
+			e.reached(s)
+		}
+
+		return nil, true
+	}
+
+	s.programCounter += advance
+
+	return []*state{s}, false
+}
+
+// advanced advances the PC of the states that are not nil
+func advanced(advance memory.Address, states ...*state) []*state {
+	var next []*state
+
+	for _, s := range states {
+		if s != nil {
+			s.programCounter += advance
+			next = append(next, s)
+		}
+	}
+
+	return next
+}
+
+// store value at an address. When the address depends on symbols, every
+// byte becomes value if it is at the address, otherwise what it was
+func (s *state) store(address Expr, value Expr) {
+	if c, ok := address.(constant); ok {
+		s.mainMemory[c] = value
+
+		return
+	}
+
+	for index := range s.mainMemory {
+		s.mainMemory[index] = newIfThenElse(
+			newComparison(equal, address, constant(index)),
+			value,
+			s.mainMemory[index],
+		)
+	}
+}
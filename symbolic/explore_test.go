@@ -0,0 +1,355 @@
+package symbolic
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/tmornini/rigetti-computing/memory"
+	"github.com/tmornini/rigetti-computing/processor"
+)
+
+// explore parses the inputs and query, then explores the program
+func explore(
+	t *testing.T,
+	programMemory *memory.ReadOnly,
+	mainMemory *memory.ReadWrite,
+	inputsText string,
+	queryText string,
+	limits Limits,
+) ([]processor.Input, Query, *Result) {
+	t.Helper()
+
+	inputs, err := ParseInputs(inputsText)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	query, err := ParseQuery(queryText)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return inputs, query, Explore(programMemory, mainMemory, inputs, query, limits)
+}
+
+// TestSymbolicAddresses stores to, and loads from, addresses that depend
+// on the inputs
+func TestSymbolicAddresses(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		program  memory.ReadOnly
+		inputs   string
+		query    string
+		expected []byte
+	}{
+		{
+			name: "store",
+			program: memory.ReadOnly{
+				0x06, 0x42, 0x01, // LDI 0x42 Y
+				0x07, 0x01, 0x00, // STR Y X
+				0x06, 0x10, 0x02, // LDI 0x10 Z
+				0x05, 0x02, 0x03, // LDM Z W
+				0x0f, //             HLT
+			},
+			inputs:   "X",
+			query:    "halt && W == 42",
+			expected: []byte{0x10},
+		},
+		{
+			name: "load",
+			program: memory.ReadOnly{
+				0x05, 0x00, 0x01, // LDM X Y
+				0x0f, //             HLT
+			},
+			inputs:   "X,[30]",
+			query:    "halt && Y == 99 && X != 30",
+			expected: nil, // only [30] is an input, which X must not address
+		},
+		{
+			name: "load stored",
+			program: memory.ReadOnly{
+				0x07, 0x01, 0x00, // STR Y X
+				0x05, 0x02, 0x03, // LDM Z W
+				0x0f, //             HLT
+			},
+			inputs:   "X,Y,Z",
+			query:    "halt && W == 99 && X == 80",
+			expected: []byte{0x80, 0x99, 0x80},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			mainMemory := &memory.ReadWrite{}
+
+			inputs, query, result := explore(
+				t,
+				&test.program,
+				mainMemory,
+				test.inputs,
+				test.query,
+				DefaultLimits,
+			)
+
+			if !result.Complete {
+				t.Errorf("exploration is incomplete")
+			}
+
+			if test.expected == nil {
+				if result.Found {
+					t.Errorf("found %x, want none", result.Values)
+				}
+
+				return
+			}
+
+			if !result.Found || string(result.Values) != string(test.expected) {
+				t.Fatalf("found %t, %x, want %x", result.Found, result.Values, test.expected)
+			}
+
+			err := Replay(&test.program, mainMemory, inputs, result.Values, query, DefaultLimits.MaxSteps)
+			if err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// TestMaxTests gives up on a query the solver cannot answer within
+// MaxTests, reporting exploration incomplete
+func TestMaxTests(t *testing.T) {
+	programMemory := &memory.ReadOnly{
+		0x03, 0x00, 0x01, 0x02, // MUL X Y Z
+		0x0f, //                   HLT
+	}
+
+	limits := DefaultLimits
+	limits.MaxTests = 100
+
+	_, _, result := explore(
+		t,
+		programMemory,
+		&memory.ReadWrite{},
+		"X,Y",
+		"halt && Z == 01 && X == Y",
+		limits,
+	)
+
+	if result.Found || result.Complete {
+		t.Errorf("found %t, complete %t, want neither", result.Found, result.Complete)
+	}
+
+	_, _, result = explore(
+		t,
+		programMemory,
+		&memory.ReadWrite{},
+		"X,Y",
+		"halt && Z == 01 && X == Y",
+		DefaultLimits,
+	)
+
+	if !result.Found {
+		t.Errorf("found nothing without the limit")
+	}
+}
+
+// TestExploreMatchesReplay checks Explore against replaying every value of
+// a single input on random programs: what it finds must replay, and when
+// it finds nothing on a complete exploration no value may satisfy the
+// query. Half the queries are for the final state of a random value, so
+// there is one to find
+func TestExploreMatchesReplay(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+
+	limits := Limits{MaxSteps: 64, MaxPaths: 1000, MaxTests: 1 << 20}
+
+	found, proven := 0, 0
+
+	for count := 0; count < 300; count++ {
+		programMemory := randomProgram(random)
+
+		var mainMemory memory.ReadWrite
+		random.Read(mainMemory[:])
+
+		inputs := []processor.Input{randomInput(random)}
+
+		query, ok := witnessedQuery(t, random, programMemory, &mainMemory, inputs, limits.MaxSteps)
+		if !ok {
+			query = randomQuery(t, random)
+		}
+
+		result := Explore(programMemory, &mainMemory, inputs, query, limits)
+
+		if result.Found {
+			found++
+
+			err := Replay(programMemory, &mainMemory, inputs, result.Values, query, limits.MaxSteps)
+			if err != nil {
+				t.Fatalf(
+					"program %d, %s=%02x, %s: %v\n%x",
+					count,
+					inputs[0],
+					result.Values[0],
+					query,
+					err,
+					programMemory[:],
+				)
+			}
+
+			continue
+		}
+
+		if !result.Complete {
+			continue
+		}
+
+		proven++
+
+		for value := 0; value < 256; value++ {
+			err := Replay(programMemory, &mainMemory, inputs, []byte{byte(value)}, query, limits.MaxSteps)
+			if err == nil {
+				t.Fatalf(
+					"program %d: no inputs satisfy %s, but %s=%02x does\n%x",
+					count,
+					query,
+					inputs[0],
+					value,
+					programMemory[:],
+				)
+			}
+		}
+	}
+
+	if found < 50 || proven < 50 {
+		t.Errorf("found inputs for %d and proved none for %d queries, want 50 of each", found, proven)
+	}
+}
+
+// randomProgram is valid instructions with registers X to W, jumping
+// within the program, and a final HLT
+func randomProgram(random *rand.Rand) *memory.ReadOnly {
+	var programMemory memory.ReadOnly
+
+	size := 4 + random.Intn(24)
+
+	address := 0
+
+	for address < size {
+		opcode := byte(random.Intn(processor.HLT + 1))
+		programMemory[address] = opcode
+		address++
+
+		for _, operand := range processor.OpcodeOperands(opcode) {
+			switch {
+			case operand == 'r':
+				programMemory[address] = byte(random.Intn(4))
+			case opcode == processor.JMP || processor.IsConditionalJump(opcode):
+				programMemory[address] = byte(random.Intn(size))
+			default:
+				programMemory[address] = byte(random.Intn(256))
+			}
+
+			address++
+		}
+	}
+
+	programMemory[address] = processor.HLT
+
+	return &programMemory
+}
+
+// witnessedQuery runs the program with a random value of the input and
+// asks for its final state, when it halts or faults within maxSteps
+func witnessedQuery(
+	t *testing.T,
+	random *rand.Rand,
+	programMemory *memory.ReadOnly,
+	mainMemory *memory.ReadWrite,
+	inputs []processor.Input,
+	maxSteps uint64,
+) (Query, bool) {
+	if random.Intn(2) == 0 {
+		return Query{}, false
+	}
+
+	preset := *mainMemory
+
+	configuration := processor.DefaultConfiguration
+	configuration.MaxSteps = maxSteps
+	configuration.Output = ioutil.Discard
+
+	p := processor.New(processor.NormalInstructionSet, configuration, programMemory, &preset)
+
+	err := p.Preset(inputs, []byte{byte(random.Intn(256))})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	goal := "halt"
+
+	err = p.Run()
+	switch {
+	case errors.Is(err, processor.ErrStepLimitExceeded):
+		return Query{}, false
+	case err != nil:
+		goal = "fault"
+	}
+
+	final := p.Registers()
+	address := random.Intn(256)
+
+	query, err := ParseQuery(fmt.Sprintf(
+		"%s && X == %02x && Y == %02x && Z == %02x && W == %02x && [%02x] == %02x",
+		goal,
+		final.X,
+		final.Y,
+		final.Z,
+		final.W,
+		address,
+		preset[address],
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return query, true
+}
+
+func randomInput(random *rand.Rand) processor.Input {
+	if random.Intn(3) == 0 {
+		return processor.Input{Memory: true, Index: byte(random.Intn(256))}
+	}
+
+	return processor.Input{Index: byte(random.Intn(len(registerNames)))}
+}
+
+// randomQuery tests a few conditions when the program halts or
+// faults, or after every instruction
+func randomQuery(t *testing.T, random *rand.Rand) Query {
+	terms := []string{[]string{"halt", "fault", "halt", "E"}[random.Intn(4)]}
+
+	operands := []string{"X", "Y", "Z", "W", fmt.Sprintf("[%02x]", random.Intn(256))}
+	operators := []string{"==", "!=", "<", ">="}
+
+	for count := random.Intn(3); count > 0; count-- {
+		terms = append(terms, fmt.Sprintf(
+			"%s %s %02x",
+			operands[random.Intn(len(operands))],
+			operators[random.Intn(len(operators))],
+			random.Intn(256),
+		))
+	}
+
+	if random.Intn(4) == 0 {
+		terms = append(terms, []string{"C", "!C", "!E"}[random.Intn(3)])
+	}
+
+	query, err := ParseQuery(strings.Join(terms, " && "))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return query
+}
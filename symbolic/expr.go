@@ -0,0 +1,251 @@
+package symbolic
+
+// Expr is a byte computed from the symbols. Exprs without symbols are
+// always constants, as constructors fold them
+type Expr interface {
+	eval(values []byte) byte
+	symbols() []int // in increasing order
+}
+
+// Bool is a condition on the symbols, folded like Expr
+type Bool interface {
+	test(values []byte) bool
+	symbols() []int
+}
+
+type constant byte
+
+func (c constant) eval(values []byte) byte { return byte(c) }
+func (c constant) symbols() []int          { return nil }
+
+type symbol int
+
+func (s symbol) eval(values []byte) byte { return values[s] }
+func (s symbol) symbols() []int          { return []int{int(s)} }
+
+type operator int
+
+const (
+	add operator = iota
+	sub
+	mul
+	div
+)
+
+type binary struct {
+	operator operator
+	a        Expr
+	b        Expr
+	used     []int
+}
+
+func newBinary(operator operator, a, b Expr) Expr {
+	e := &binary{
+		operator: operator,
+		a:        a,
+		b:        b,
+		used:     union(a.symbols(), b.symbols()),
+	}
+
+	if len(e.used) == 0 {
+		return constant(e.eval(nil))
+	}
+
+	return e
+}
+
+func (e *binary) eval(values []byte) byte {
+	a, b := e.a.eval(values), e.b.eval(values)
+
+	switch e.operator {
+	case add:
+		return a + b
+	case sub:
+		return a - b
+	case mul:
+		return a * b
+	default:
+		// paths fork on divide by zero, so never reach this with b 0
+		if b == 0 {
+			return 0
+		}
+
+		return a / b
+	}
+}
+
+func (e *binary) symbols() []int { return e.used }
+
+// ifThenElse is a when condition holds, otherwise b
+type ifThenElse struct {
+	condition Bool
+	a         Expr
+	b         Expr
+	used      []int
+}
+
+func newIfThenElse(condition Bool, a, b Expr) Expr {
+	if c, ok := condition.(boolConstant); ok {
+		if c {
+			return a
+		}
+
+		return b
+	}
+
+	if a == b {
+		return a
+	}
+
+	return &ifThenElse{
+		condition: condition,
+		a:         a,
+		b:         b,
+		used:      union(condition.symbols(), union(a.symbols(), b.symbols())),
+	}
+}
+
+func (e *ifThenElse) eval(values []byte) byte {
+	if e.condition.test(values) {
+		return e.a.eval(values)
+	}
+
+	return e.b.eval(values)
+}
+
+func (e *ifThenElse) symbols() []int { return e.used }
+
+// load is the main memory byte at an address that depends on symbols
+type load struct {
+	address Expr
+	cells   [256]Expr
+	used    []int
+}
+
+func newLoad(address Expr, cells *[256]Expr) Expr {
+	if c, ok := address.(constant); ok {
+		return cells[c]
+	}
+
+	e := &load{address: address, cells: *cells, used: address.symbols()}
+
+	for _, cell := range cells {
+		e.used = union(e.used, cell.symbols())
+	}
+
+	return e
+}
+
+func (e *load) eval(values []byte) byte {
+	return e.cells[e.address.eval(values)].eval(values)
+}
+
+func (e *load) symbols() []int { return e.used }
+
+type boolConstant bool
+
+func (c boolConstant) test(values []byte) bool { return bool(c) }
+func (c boolConstant) symbols() []int          { return nil }
+
+// Comparison operators
+const (
+	equal = iota
+	notEqual
+	less
+	lessOrEqual
+	greater
+	greaterOrEqual
+)
+
+var comparisonNames = []string{"==", "!=", "<", "<=", ">", ">="}
+
+type comparison struct {
+	operator int
+	a        Expr
+	b        Expr
+	used     []int
+}
+
+func newComparison(operator int, a, b Expr) Bool {
+	e := &comparison{
+		operator: operator,
+		a:        a,
+		b:        b,
+		used:     union(a.symbols(), b.symbols()),
+	}
+
+	if len(e.used) == 0 {
+		return boolConstant(e.test(nil))
+	}
+
+	return e
+}
+
+func (e *comparison) test(values []byte) bool {
+	return compare(e.operator, e.a.eval(values), e.b.eval(values))
+}
+
+func (e *comparison) symbols() []int { return e.used }
+
+func compare(operator int, a, b byte) bool {
+	switch operator {
+	case equal:
+		return a == b
+	case notEqual:
+		return a != b
+	case less:
+		return a < b
+	case lessOrEqual:
+		return a <= b
+	case greater:
+		return a > b
+	default:
+		return a >= b
+	}
+}
+
+type negation struct {
+	a Bool
+}
+
+func newNegation(a Bool) Bool {
+	switch a := a.(type) {
+	case boolConstant:
+		return !a
+	case *negation:
+		return a.a
+	}
+
+	return &negation{a: a}
+}
+
+func (e *negation) test(values []byte) bool { return !e.a.test(values) }
+func (e *negation) symbols() []int          { return e.a.symbols() }
+
+// union of two sets of symbols, in increasing order
+func union(a, b []int) []int {
+	switch {
+	case len(a) == 0:
+		return b
+	case len(b) == 0:
+		return a
+	}
+
+	merged := make([]int, 0, len(a)+len(b))
+
+	for len(a) > 0 || len(b) > 0 {
+		switch {
+		case len(b) == 0 || len(a) > 0 && a[0] < b[0]:
+			merged = append(merged, a[0])
+			a = a[1:]
+		case len(a) == 0 || b[0] < a[0]:
+			merged = append(merged, b[0])
+			b = b[1:]
+		default:
+			merged = append(merged, a[0])
+			a, b = a[1:], b[1:]
+		}
+	}
+
+	return merged
+}
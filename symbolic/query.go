@@ -0,0 +1,357 @@
+package symbolic
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/tmornini/rigetti-computing/memory"
+	"github.com/tmornini/rigetti-computing/processor"
+)
+
+var registerNames = []string{"X", "Y", "Z", "W"}
+
+// ParseInputs parses comma separated registers and main memory addresses,
+// e.g. X,Y,[10] or [00-03] for 00 to 03
+func ParseInputs(text string) ([]processor.Input, error) {
+	var inputs []processor.Input
+
+	for _, field := range strings.Split(text, ",") {
+		field = strings.TrimSpace(field)
+
+		if register, ok := parseRegister(field); ok {
+			inputs = append(inputs, processor.Input{Index: register})
+			continue
+		}
+
+		if !strings.HasPrefix(field, "[") || !strings.HasSuffix(field, "]") {
+			return nil, &InputError{Input: field}
+		}
+
+		bounds := strings.SplitN(field[1:len(field)-1], "-", 2)
+
+		first, err := parseByte(bounds[0])
+		if err != nil {
+			return nil, &InputError{Input: field}
+		}
+
+		last := first
+
+		if len(bounds) == 2 {
+			last, err = parseByte(bounds[1])
+			if err != nil || last < first {
+				return nil, &InputError{Input: field}
+			}
+		}
+
+		for address := int(first); address <= int(last); address++ {
+			inputs = append(inputs, processor.Input{Memory: true, Index: byte(address)})
+		}
+	}
+
+	seen := map[processor.Input]bool{}
+
+	for _, input := range inputs {
+		if seen[input] {
+			return nil, &InputError{Input: input.String(), Reason: "repeated"}
+		}
+
+		seen[input] = true
+	}
+
+	return inputs, nil
+}
+
+func parseRegister(text string) (byte, bool) {
+	for index, name := range registerNames {
+		if strings.EqualFold(text, name) {
+			return byte(index), true
+		}
+	}
+
+	return 0, false
+}
+
+// parseByte parses hex, with or without 0x
+func parseByte(text string) (byte, error) {
+	text = strings.TrimPrefix(strings.ToLower(text), "0x")
+
+	value, err := strconv.ParseUint(text, 16, 8)
+
+	return byte(value), err
+}
+
+// Goal is when a Query's conditions are tested
+type Goal int
+
+const (
+	// Ever tests them before and after every instruction
+	Ever Goal = iota
+
+	// Halt tests them when HLT executes
+	Halt
+
+	// Fault tests them when an instruction faults, before it changes
+	// anything but E, which is set
+	Fault
+)
+
+var goalNames = []string{"ever", "halt", "fault"}
+
+func (goal Goal) String() string {
+	return goalNames[goal]
+}
+
+// Query asks whether there are inputs for which its conditions all hold
+// when its goal is reached
+type Query struct {
+	Goal       Goal
+	Conditions []Condition
+}
+
+// Condition compares a register, flag or main memory byte. Flags are
+// compared with 1
+type Condition struct {
+	Operator int
+	A        Operand
+	B        Operand
+}
+
+// Operand of a condition
+type Operand struct {
+	Kind  OperandKind
+	Index byte // register 0-3, flag 0 for C and 1 for E, address or value
+}
+
+// OperandKind is what an operand reads
+type OperandKind int
+
+const (
+	// Value is a constant
+	Value OperandKind = iota
+
+	// Register X, Y, Z or W
+	Register
+
+	// Flag C or E
+	Flag
+
+	// Memory byte at an address
+	Memory
+)
+
+// ParseQuery parses conditions joined by &&. One may be halt or fault,
+// otherwise the conditions are tested after every instruction. Conditions
+// compare registers, [address] of main memory and hex values with ==, !=,
+// <, <=, > or >=, or are C, E, !C or !E, e.g. "halt && Z == 0" or "E"
+func ParseQuery(text string) (Query, error) {
+	var query Query
+
+	for _, term := range strings.Split(text, "&&") {
+		term = strings.TrimSpace(term)
+
+		switch strings.ToLower(term) {
+		case "halt", "fault":
+			if query.Goal != Ever {
+				return Query{}, &QueryError{Term: term, Reason: "second goal"}
+			}
+
+			query.Goal = Halt
+			if strings.ToLower(term) == "fault" {
+				query.Goal = Fault
+			}
+
+			continue
+		}
+
+		condition, err := parseCondition(term)
+		if err != nil {
+			return Query{}, err
+		}
+
+		query.Conditions = append(query.Conditions, condition)
+	}
+
+	return query, nil
+}
+
+func parseCondition(term string) (Condition, error) {
+	for _, operator := range []int{
+		equal,
+		notEqual,
+		lessOrEqual,
+		greaterOrEqual,
+		less,
+		greater,
+	} {
+		operands := strings.SplitN(term, comparisonNames[operator], 2)
+		if len(operands) != 2 {
+			continue
+		}
+
+		a, err := parseOperand(operands[0], term)
+		if err != nil {
+			return Condition{}, err
+		}
+
+		b, err := parseOperand(operands[1], term)
+		if err != nil {
+			return Condition{}, err
+		}
+
+		return Condition{Operator: operator, A: a, B: b}, nil
+	}
+
+	operator := equal
+
+	if strings.HasPrefix(term, "!") {
+		operator = notEqual
+		term = strings.TrimSpace(term[1:])
+	}
+
+	for index, name := range []string{"C", "E"} {
+		if strings.EqualFold(term, name) {
+			return Condition{
+				Operator: operator,
+				A:        Operand{Kind: Flag, Index: byte(index)},
+				B:        Operand{Kind: Value, Index: 1},
+			}, nil
+		}
+	}
+
+	return Condition{}, &QueryError{Term: term, Reason: "not a condition"}
+}
+
+func parseOperand(text string, term string) (Operand, error) {
+	text = strings.TrimSpace(text)
+
+	if register, ok := parseRegister(text); ok {
+		return Operand{Kind: Register, Index: register}, nil
+	}
+
+	if strings.HasPrefix(text, "[") && strings.HasSuffix(text, "]") {
+		address, err := parseByte(text[1 : len(text)-1])
+		if err == nil {
+			return Operand{Kind: Memory, Index: address}, nil
+		}
+	}
+
+	value, err := parseByte(text)
+	if err != nil {
+		return Operand{}, &QueryError{Term: term, Reason: text + " is not an operand"}
+	}
+
+	return Operand{Kind: Value, Index: value}, nil
+}
+
+// symbolic is the condition on the symbols it holds for, in state
+func (condition Condition) symbolic(s *state) Bool {
+	return newComparison(
+		condition.Operator,
+		condition.A.symbolic(s),
+		condition.B.symbolic(s),
+	)
+}
+
+func (operand Operand) symbolic(s *state) Expr {
+	switch operand.Kind {
+	case Register:
+		return s.registers[operand.Index]
+	case Flag:
+		if operand.Index == 0 {
+			return newIfThenElse(s.c, constant(1), constant(0))
+		}
+
+		if s.e {
+			return constant(1)
+		}
+
+		return constant(0)
+	case Memory:
+		return s.mainMemory[operand.Index]
+	default:
+		return constant(operand.Index)
+	}
+}
+
+// holds is whether the condition holds for a processor
+func (condition Condition) holds(
+	registers processor.Registers,
+	mainMemory *memory.ReadWrite,
+) bool {
+	return compare(
+		condition.Operator,
+		condition.A.concrete(registers, mainMemory),
+		condition.B.concrete(registers, mainMemory),
+	)
+}
+
+func (operand Operand) concrete(
+	registers processor.Registers,
+	mainMemory *memory.ReadWrite,
+) byte {
+	switch operand.Kind {
+	case Register:
+		return []byte{registers.X, registers.Y, registers.Z, registers.W}[operand.Index]
+	case Flag:
+		flag := registers.E
+		if operand.Index == 0 {
+			flag = registers.C
+		}
+
+		if flag {
+			return 1
+		}
+
+		return 0
+	case Memory:
+		return mainMemory[operand.Index]
+	default:
+		return operand.Index
+	}
+}
+
+func (query Query) String() string {
+	var terms []string
+
+	if query.Goal != Ever {
+		terms = append(terms, query.Goal.String())
+	}
+
+	for _, condition := range query.Conditions {
+		terms = append(terms, condition.String())
+	}
+
+	return strings.Join(terms, " && ")
+}
+
+func (condition Condition) String() string {
+	flag := condition.A.Kind == Flag && condition.B == Operand{Kind: Value, Index: 1}
+
+	switch {
+	case flag && condition.Operator == equal:
+		return condition.A.String()
+	case flag && condition.Operator == notEqual:
+		return "!" + condition.A.String()
+	}
+
+	return fmt.Sprintf(
+		"%s %s %s",
+		condition.A,
+		comparisonNames[condition.Operator],
+		condition.B,
+	)
+}
+
+func (operand Operand) String() string {
+	switch operand.Kind {
+	case Register:
+		return registerNames[operand.Index]
+	case Flag:
+		return []string{"C", "E"}[operand.Index]
+	case Memory:
+		return fmt.Sprintf("[%02x]", operand.Index)
+	default:
+		return fmt.Sprintf("%02x", operand.Index)
+	}
+}
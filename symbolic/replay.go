@@ -0,0 +1,78 @@
+package symbolic
+
+import (
+	"errors"
+	"io/ioutil"
+
+	"github.com/tmornini/rigetti-computing/memory"
+	"github.com/tmornini/rigetti-computing/processor"
+)
+
+// Replay runs the program on the processor with the default policies,
+// presetting the inputs to values, and returns a *ReplayError unless the
+// query's conditions hold when its goal is reached within maxSteps, or a
+// *processor.InputError when the inputs cannot be preset
+func Replay(
+	programMemory *memory.ReadOnly,
+	mainMemory *memory.ReadWrite,
+	inputs []processor.Input,
+	values []byte,
+	query Query,
+	maxSteps uint64,
+) error {
+	preset := *mainMemory
+
+	configuration := processor.DefaultConfiguration
+	configuration.MaxSteps = maxSteps
+	configuration.Output = ioutil.Discard
+	configuration.TraceOutput = ioutil.Discard
+
+	p := processor.New(
+		processor.NormalInstructionSet,
+		configuration,
+		programMemory,
+		&preset,
+	)
+
+	err := p.Preset(inputs, values)
+	if err != nil {
+		return err
+	}
+
+	holds := func() bool {
+		for _, condition := range query.Conditions {
+			if !condition.holds(p.Registers(), p.MainMemory()) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	for {
+		if query.Goal == Ever && holds() {
+			return nil
+		}
+
+		halted, err := p.Step()
+
+		var limit *processor.StepLimitError
+
+		switch {
+		case halted:
+			if query.Goal == Halt && holds() {
+				return nil
+			}
+
+			return &ReplayError{Steps: p.Steps(), Outcome: "halted"}
+		case errors.As(err, &limit):
+			return &ReplayError{Steps: p.Steps(), Outcome: "step limit"}
+		case err != nil:
+			if (query.Goal == Fault || query.Goal == Ever) && holds() {
+				return nil
+			}
+
+			return &ReplayError{Steps: p.Steps(), Outcome: "faulted"}
+		}
+	}
+}
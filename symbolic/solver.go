@@ -0,0 +1,199 @@
+package symbolic
+
+// solver finds values of the symbols satisfying conditions, by trying
+// every value of each symbol in turn. Conditions sharing no symbols are
+// solved separately, and each condition is tested as soon as its symbols
+// have values, so only symbols a condition relates are searched together
+type solver struct {
+	numberOfSymbols int
+
+	// budget is how many more conditions may be tested, so queries
+	// relating many symbols give up rather than search for hours
+	budget int
+}
+
+// solution is whether conditions can be satisfied, with values when they
+// can
+type solution int
+
+const (
+	unsatisfiable solution = iota
+	satisfiable
+	unknown // the budget ran out
+)
+
+// solve starts from hint, the values satisfying an earlier subset of the
+// conditions, which are returned unchanged when they satisfy these too
+func (s *solver) solve(conditions []Bool, hint []byte) ([]byte, solution) {
+	if satisfies(conditions, hint) {
+		return hint, satisfiable
+	}
+
+	values := append([]byte{}, hint...)
+
+	for _, group := range groups(conditions, s.numberOfSymbols) {
+		result := s.search(group, values)
+		if result != satisfiable {
+			return nil, result
+		}
+	}
+
+	return values, satisfiable
+}
+
+func satisfies(conditions []Bool, values []byte) bool {
+	for _, condition := range conditions {
+		if !condition.test(values) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// group is conditions related by their symbols
+type group struct {
+	symbols []int
+
+	// conditions[i] are those whose last symbol is symbols[i]
+	conditions [][]Bool
+}
+
+// groups splits conditions into groups sharing no symbols
+func groups(conditions []Bool, numberOfSymbols int) []group {
+	parent := make([]int, numberOfSymbols)
+
+	for index := range parent {
+		parent[index] = index
+	}
+
+	var find func(symbol int) int
+	find = func(symbol int) int {
+		if parent[symbol] != symbol {
+			parent[symbol] = find(parent[symbol])
+		}
+
+		return parent[symbol]
+	}
+
+	var constants []Bool
+
+	for _, condition := range conditions {
+		used := condition.symbols()
+
+		if len(used) == 0 {
+			constants = append(constants, condition)
+			continue
+		}
+
+		for _, symbol := range used[1:] {
+			parent[find(symbol)] = find(used[0])
+		}
+	}
+
+	var related [][]Bool
+	byRoot := map[int]int{}
+
+	for _, condition := range conditions {
+		used := condition.symbols()
+
+		if len(used) == 0 {
+			continue
+		}
+
+		root := find(used[0])
+
+		index, ok := byRoot[root]
+		if !ok {
+			index = len(related)
+			byRoot[root] = index
+			related = append(related, nil)
+		}
+
+		related[index] = append(related[index], condition)
+	}
+
+	var found []group
+
+	if len(constants) > 0 {
+		// symbol-free conditions are folded constants, tested first
+		found = append(found, group{conditions: [][]Bool{constants}})
+	}
+
+	for _, conditions := range related {
+		found = append(found, newGroup(conditions))
+	}
+
+	return found
+}
+
+func newGroup(conditions []Bool) group {
+	var g group
+
+	for _, condition := range conditions {
+		g.symbols = union(g.symbols, condition.symbols())
+	}
+
+	g.conditions = make([][]Bool, len(g.symbols))
+
+	for _, condition := range conditions {
+		used := condition.symbols()
+		last := used[len(used)-1]
+
+		for index, symbol := range g.symbols {
+			if symbol == last {
+				g.conditions[index] = append(g.conditions[index], condition)
+			}
+		}
+	}
+
+	return g
+}
+
+// search assigns values to the group's symbols in order, backtracking when
+// a condition whose symbols all have values fails
+func (s *solver) search(g group, values []byte) solution {
+	if len(g.symbols) == 0 {
+		for _, condition := range g.conditions[0] {
+			if !condition.test(values) {
+				return unsatisfiable
+			}
+		}
+
+		return satisfiable
+	}
+
+	var assign func(index int) solution
+	assign = func(index int) solution {
+		if index == len(g.symbols) {
+			return satisfiable
+		}
+
+		symbol := g.symbols[index]
+		start := values[symbol]
+
+		for offset := 0; offset < 256; offset++ {
+			values[symbol] = start + byte(offset)
+
+			s.budget -= len(g.conditions[index])
+			if s.budget < 0 {
+				return unknown
+			}
+
+			if !satisfies(g.conditions[index], values) {
+				continue
+			}
+
+			result := assign(index + 1)
+			if result != unsatisfiable {
+				return result
+			}
+		}
+
+		values[symbol] = start
+
+		return unsatisfiable
+	}
+
+	return assign(0)
+}
@@ -0,0 +1,125 @@
+package symbolic
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestGroups separates conditions sharing no symbols, and tests folded
+// constants first
+func TestGroups(t *testing.T) {
+	conditions := []Bool{
+		newComparison(equal, symbol(2), constant(7)),
+		newComparison(less, symbol(0), symbol(1)),
+		boolConstant(true),
+		newComparison(notEqual, symbol(3), symbol(1)),
+	}
+
+	found := groups(conditions, 5)
+
+	if len(found) != 3 {
+		t.Fatalf("got %d groups, want 3", len(found))
+	}
+
+	if found[0].symbols != nil || len(found[0].conditions[0]) != 1 {
+		t.Errorf("first group is %+v, want the constant", found[0])
+	}
+
+	if !reflect.DeepEqual(found[1].symbols, []int{2}) {
+		t.Errorf("second group has symbols %v, want [2]", found[1].symbols)
+	}
+
+	if !reflect.DeepEqual(found[2].symbols, []int{0, 1, 3}) {
+		t.Errorf("third group has symbols %v, want [0 1 3]", found[2].symbols)
+	}
+
+	// each condition is tested once its last symbol has a value
+	tested := []int{0, 1, 1}
+
+	for index, conditions := range found[2].conditions {
+		if len(conditions) != tested[index] {
+			t.Errorf(
+				"%d conditions are tested at symbol %d, want %d",
+				len(conditions),
+				found[2].symbols[index],
+				tested[index],
+			)
+		}
+	}
+}
+
+// TestSolve backtracks to an earlier symbol when no value of a later one
+// satisfies the conditions
+func TestSolve(t *testing.T) {
+	// s0 * s1 is 6 only when s0 is odd, but the first s0 >= 80 is even
+	conditions := []Bool{
+		newComparison(greaterOrEqual, symbol(0), constant(0x80)),
+		newComparison(equal, newBinary(mul, symbol(0), symbol(1)), constant(6)),
+		newComparison(equal, symbol(2), constant(0x2a)),
+	}
+
+	s := &solver{numberOfSymbols: 3, budget: 1 << 20}
+
+	values, solution := s.solve(conditions, make([]byte, 3))
+	if solution != satisfiable {
+		t.Fatalf("got %v, want satisfiable", solution)
+	}
+
+	if !satisfies(conditions, values) || values[0] != 0x81 {
+		t.Errorf("got %x, want 81 and values satisfying the conditions", values)
+	}
+
+	// a hint that satisfies the conditions is kept
+	hint := []byte{0xff, 0xfa, 0x2a}
+
+	values, solution = s.solve(conditions, hint)
+	if solution != satisfiable || !reflect.DeepEqual(values, hint) {
+		t.Errorf("got %x, %v, want the hint %x", values, solution, hint)
+	}
+}
+
+// TestUnsatisfiable conditions are proven so, independent ones included
+func TestUnsatisfiable(t *testing.T) {
+	for _, conditions := range [][]Bool{
+		{
+			newComparison(equal, symbol(0), symbol(1)),
+			newComparison(notEqual, symbol(1), symbol(0)),
+		},
+		{
+			newComparison(less, symbol(0), constant(0)),
+		},
+		{
+			newComparison(equal, symbol(1), constant(1)),
+			boolConstant(false),
+		},
+	} {
+		s := &solver{numberOfSymbols: 2, budget: 1 << 20}
+
+		values, solution := s.solve(conditions, make([]byte, 2))
+		if solution != unsatisfiable {
+			t.Errorf("got %x, %v, want unsatisfiable", values, solution)
+		}
+	}
+}
+
+// TestBudget gives up with unknown rather than search on
+func TestBudget(t *testing.T) {
+	conditions := []Bool{
+		newComparison(equal, newBinary(add, symbol(0), symbol(1)), constant(0xff)),
+		newComparison(equal, symbol(0), symbol(1)),
+	}
+
+	s := &solver{numberOfSymbols: 2, budget: 1000}
+
+	_, solution := s.solve(conditions, make([]byte, 2))
+	if solution != unknown {
+		t.Errorf("got %v with a budget of 1000, want unknown", solution)
+	}
+
+	s = &solver{numberOfSymbols: 2, budget: 1 << 20}
+
+	_, solution = s.solve(conditions, make([]byte, 2))
+	if solution != unsatisfiable {
+		t.Errorf("got %v, want unsatisfiable", solution)
+	}
+}
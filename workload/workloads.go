@@ -0,0 +1,88 @@
+package workload
+
+import "github.com/tmornini/rigetti-computing/memory"
+
+// Workload is a program and the main memory it starts with
+type Workload struct {
+	Name string
+
+	ProgramMemory memory.ReadOnly
+	MainMemory    memory.ReadWrite
+}
+
+// All is the set of workloads benchmarked by processor and rcc-bench
+var All = []Workload{
+	StringLength,
+	TightLoop,
+	MemoryFillAndSum,
+}
+
+// StringLength counts the 255 non-zero bytes at the start of main memory,
+// leaving the count in Z. It is the loop from spec-successes/assignment-2.hex
+var StringLength = Workload{
+	Name: "string-length",
+
+	ProgramMemory: memory.ReadOnly{
+		0x06, 0x00, 0x00, //       LDI 0x00 X
+		0x06, 0x00, 0x02, //       LDI 0x00 Z
+		0x06, 0x00, 0x03, // LOOP  LDI 0x00 W
+		0x05, 0x00, 0x01, //       LDM X Y
+		0x09, 0x01, 0x03, //       EQL Y W
+		0x0c, 0x1e, //             JMC DONE
+		0x06, 0x01, 0x03, //       LDI 0x01 W
+		0x01, 0x00, 0x03, 0x00, // ADD X W X
+		0x01, 0x02, 0x03, 0x02, // ADD Z W Z
+		0x0b, 0x06, //             JMP LOOP
+		0x0f, //             DONE  HLT
+	},
+
+	MainMemory: longString(),
+}
+
+// TightLoop runs a three instruction loop 65,536 times
+var TightLoop = Workload{
+	Name: "tight-loop",
+
+	ProgramMemory: memory.ReadOnly{
+		0x06, 0x01, 0x03, //       LDI 0x01 W
+		0x01, 0x00, 0x03, 0x00, // INNER ADD X W X
+		0x0a, 0x00, 0x02, //       NQL X Z
+		0x0c, 0x03, //             JMC INNER
+		0x01, 0x01, 0x03, 0x01, // ADD Y W Y
+		0x0a, 0x01, 0x02, //       NQL Y Z
+		0x0c, 0x03, //             JMC INNER
+		0x0f, //                   HLT
+	},
+}
+
+// MemoryFillAndSum stores each address into itself, then sums all of main
+// memory into Z, which ends up as #x80
+var MemoryFillAndSum = Workload{
+	Name: "memory-fill-and-sum",
+
+	ProgramMemory: memory.ReadOnly{
+		0x06, 0x01, 0x03, //       LDI 0x01 W
+		0x07, 0x00, 0x00, // FILL  STR X X
+		0x01, 0x00, 0x03, 0x00, // ADD X W X
+		0x0a, 0x00, 0x02, //       NQL X Z
+		0x0c, 0x03, //             JMC FILL
+		0x05, 0x00, 0x01, // SUM   LDM X Y
+		0x01, 0x02, 0x01, 0x02, // ADD Z Y Z
+		0x01, 0x00, 0x03, 0x00, // ADD X W X
+		0x06, 0x00, 0x01, //       LDI 0x00 Y
+		0x0a, 0x00, 0x01, //       NQL X Y
+		0x0c, 0x0f, //             JMC SUM
+		0x0f, //                   HLT
+	},
+}
+
+// 255 non-zero bytes followed by the terminating zero
+func longString() memory.ReadWrite {
+	mainMemory := memory.ReadWrite{}
+
+	for address := 0; address < len(mainMemory)-1; address++ {
+		mainMemory[address] = 'a'
+	}
+
+	return mainMemory
+}